@@ -0,0 +1,70 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+
+	"ai_code_reviewer/internal/provider"
+	"ai_code_reviewer/internal/usage"
+)
+
+// 达到迭代上限时追加的提示，要求模型不再调用工具直接给出结论
+const finalizePrompt = "工具调用次数已达上限，请根据已获得的信息直接给出审查结论。"
+
+// Result 工具调用循环的结果
+type Result struct {
+	Content   string
+	Model     string // 回退模型给出最终回复时为该模型，为空表示请求中的模型
+	Usage     usage.Usage
+	Requests  int
+	ToolCalls int
+}
+
+// Run 执行工具调用循环：模型请求工具时执行并回传结果，直到模型给出最终回复或达到迭代上限
+func Run(ctx context.Context, p provider.Provider, req provider.Request, tb *Toolbox, maxIterations int) (*Result, error) {
+	result := &Result{}
+	messages := append([]provider.Message(nil), req.Messages...)
+
+	for i := 0; ; i++ {
+		call := provider.Request{Model: req.Model, Messages: messages}
+		if i < maxIterations {
+			call.Tools = tb.Tools()
+		} else {
+			// 达到上限后不再提供工具，强制模型收尾
+			messages = append(messages, provider.Message{Role: provider.RoleUser, Content: finalizePrompt})
+			call.Messages = messages
+		}
+
+		resp, err := p.Complete(ctx, call)
+		result.Requests++
+		if resp != nil {
+			result.Usage.Add(resp.Usage)
+		}
+		if err != nil {
+			return result, err
+		}
+
+		if len(resp.ToolCalls) == 0 {
+			result.Content = resp.Content
+			result.Model = resp.Model
+			return result, nil
+		}
+		if call.Tools == nil {
+			return result, fmt.Errorf("模型在工具调用达到上限后仍未给出结论")
+		}
+
+		messages = append(messages, provider.Message{
+			Role:      provider.RoleAssistant,
+			Content:   resp.Content,
+			ToolCalls: resp.ToolCalls,
+		})
+		for _, tc := range resp.ToolCalls {
+			result.ToolCalls++
+			messages = append(messages, provider.Message{
+				Role:       provider.RoleTool,
+				Content:    tb.Execute(tc),
+				ToolCallID: tc.ID,
+			})
+		}
+	}
+}
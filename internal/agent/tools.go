@@ -0,0 +1,287 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"ai_code_reviewer/internal/gitutil"
+	"ai_code_reviewer/internal/provider"
+	"ai_code_reviewer/internal/redact"
+)
+
+// 工具输出限制，避免单次调用占满上下文
+const (
+	maxToolOutput = 20000 // 字符数
+	maxReadLines  = 400
+	maxGrepLines  = 100
+	maxLogEntries = 20
+)
+
+// Toolbox 供模型在审查时调用的只读工具集，所有路径都限定在仓库根目录内
+type Toolbox struct {
+	ref        string // 读取文件的引用，为空时读取工作区
+	redactMode string
+}
+
+// NewToolbox 创建工具集，ref 为空或 "." 时读取工作区，工具输出按 redactMode 脱敏
+func NewToolbox(ref, redactMode string) *Toolbox {
+	// gitutil 将 "." 视为工作区，统一为空字符串，确保读取工作区时都做符号链接检查
+	if ref == "." {
+		ref = ""
+	}
+	return &Toolbox{
+		ref:        ref,
+		redactMode: redactMode,
+	}
+}
+
+// Tools 返回提供给模型的工具定义
+func (t *Toolbox) Tools() []provider.Tool {
+	return []provider.Tool{
+		{
+			Name:        "read_file",
+			Description: "读取仓库中的文件内容（带行号）。路径相对仓库根目录，可指定行范围，单次最多返回 400 行。",
+			Parameters: schema(map[string]any{
+				"path":       map[string]any{"type": "string", "description": "相对仓库根目录的文件路径"},
+				"start_line": map[string]any{"type": "integer", "description": "起始行号，从 1 开始"},
+				"end_line":   map[string]any{"type": "integer", "description": "结束行号（包含）"},
+			}, "path"),
+		},
+		{
+			Name:        "grep",
+			Description: "在仓库中按扩展正则表达式搜索，返回 路径:行号:内容，最多 100 条。",
+			Parameters: schema(map[string]any{
+				"pattern": map[string]any{"type": "string", "description": "扩展正则表达式"},
+				"path":    map[string]any{"type": "string", "description": "限定搜索的目录或文件，可选"},
+			}, "pattern"),
+		},
+		{
+			Name:        "list_dir",
+			Description: "列出仓库中某个目录下的文件和子目录。",
+			Parameters: schema(map[string]any{
+				"path": map[string]any{"type": "string", "description": "相对仓库根目录的目录路径，根目录为 ."},
+			}, "path"),
+		},
+		{
+			Name:        "git_log",
+			Description: "查看与某个路径相关的最近提交记录。",
+			Parameters: schema(map[string]any{
+				"path":  map[string]any{"type": "string", "description": "文件或目录路径"},
+				"limit": map[string]any{"type": "integer", "description": "返回的提交数，默认 10，最多 20"},
+			}, "path"),
+		},
+	}
+}
+
+// Execute 执行一次工具调用，参数错误或执行失败也以文本形式返回给模型
+func (t *Toolbox) Execute(call provider.ToolCall) string {
+	var args struct {
+		Path      string `json:"path"`
+		StartLine int    `json:"start_line"`
+		EndLine   int    `json:"end_line"`
+		Pattern   string `json:"pattern"`
+		Limit     int    `json:"limit"`
+	}
+	if err := json.Unmarshal([]byte(call.Arguments), &args); err != nil {
+		return fmt.Sprintf("错误: 参数不是合法的 JSON: %v", err)
+	}
+
+	var out string
+	var err error
+	switch call.Name {
+	case "read_file":
+		out, err = t.readFile(args.Path, args.StartLine, args.EndLine)
+	case "grep":
+		out, err = t.grep(args.Pattern, args.Path)
+	case "list_dir":
+		out, err = t.listDir(args.Path)
+	case "git_log":
+		out, err = t.gitLog(args.Path, args.Limit)
+	default:
+		err = fmt.Errorf("未知工具 %s", call.Name)
+	}
+	if err != nil {
+		return "错误: " + err.Error()
+	}
+	return t.sanitize(call.Name, out)
+}
+
+func (t *Toolbox) readFile(p string, start, end int) (string, error) {
+	p, err := t.resolve(p)
+	if err != nil {
+		return "", err
+	}
+	data, err := gitutil.ShowFile(t.ref, p)
+	if err != nil {
+		return "", fmt.Errorf("读取 %s 失败", p)
+	}
+	if strings.IndexByte(string(data[:min(len(data), 8000)]), 0) >= 0 {
+		return "", fmt.Errorf("%s 是二进制文件", p)
+	}
+
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if start < 1 {
+		start = 1
+	}
+	if end < start || end > len(lines) {
+		end = len(lines)
+	}
+	if end-start+1 > maxReadLines {
+		end = start + maxReadLines - 1
+	}
+	if start > len(lines) {
+		return "", fmt.Errorf("%s 只有 %d 行", p, len(lines))
+	}
+
+	var b strings.Builder
+	for i := start; i <= end; i++ {
+		fmt.Fprintf(&b, "%5d  %s\n", i, lines[i-1])
+	}
+	if end < len(lines) {
+		fmt.Fprintf(&b, "...（共 %d 行，可指定 start_line 继续读取）\n", len(lines))
+	}
+	return b.String(), nil
+}
+
+func (t *Toolbox) grep(pattern, p string) (string, error) {
+	if pattern == "" {
+		return "", fmt.Errorf("pattern 不能为空")
+	}
+	if p != "" {
+		var err error
+		if p, err = t.resolve(p); err != nil {
+			return "", err
+		}
+	}
+	out, err := gitutil.Grep(t.ref, pattern, p)
+	if err != nil {
+		return "", fmt.Errorf("搜索失败: %v", err)
+	}
+	if out == "" {
+		return "无匹配结果", nil
+	}
+
+	lines := strings.Split(strings.TrimRight(out, "\n"), "\n")
+	if len(lines) > maxGrepLines {
+		return strings.Join(lines[:maxGrepLines], "\n") + fmt.Sprintf("\n...（共 %d 条，请缩小搜索范围）", len(lines)), nil
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+func (t *Toolbox) listDir(p string) (string, error) {
+	p, err := t.resolve(p)
+	if err != nil {
+		return "", err
+	}
+	files, err := gitutil.ListFiles(t.ref, p)
+	if err != nil {
+		return "", fmt.Errorf("列出目录失败: %v", err)
+	}
+
+	seen := map[string]bool{}
+	for _, f := range files {
+		rel := f
+		if p != "." {
+			rel = strings.TrimPrefix(f, p+"/")
+		}
+		if first, _, isDir := strings.Cut(rel, "/"); isDir {
+			seen[first+"/"] = true
+		} else {
+			seen[first] = true
+		}
+	}
+	if len(seen) == 0 {
+		return "", fmt.Errorf("目录 %s 不存在或为空", p)
+	}
+
+	entries := make([]string, 0, len(seen))
+	for e := range seen {
+		entries = append(entries, e)
+	}
+	sort.Strings(entries)
+	return strings.Join(entries, "\n"), nil
+}
+
+func (t *Toolbox) gitLog(p string, limit int) (string, error) {
+	p, err := t.resolve(p)
+	if err != nil {
+		return "", err
+	}
+	if limit <= 0 {
+		limit = 10
+	}
+	out, err := gitutil.Log(t.ref, p, min(limit, maxLogEntries))
+	if err != nil {
+		return "", fmt.Errorf("获取提交记录失败: %v", err)
+	}
+	if strings.TrimSpace(out) == "" {
+		return "无提交记录", nil
+	}
+	return out, nil
+}
+
+// resolve 校验路径并转换为相对仓库根目录的形式，拒绝越出仓库或访问 .git 目录
+func (t *Toolbox) resolve(p string) (string, error) {
+	if p == "" {
+		return "", fmt.Errorf("path 不能为空")
+	}
+	if filepath.IsAbs(p) {
+		return "", fmt.Errorf("只允许使用相对仓库根目录的路径: %s", p)
+	}
+	clean := path.Clean(filepath.ToSlash(p))
+	if clean == ".." || strings.HasPrefix(clean, "../") {
+		return "", fmt.Errorf("路径超出仓库范围: %s", p)
+	}
+	if clean == ".git" || strings.HasPrefix(clean, ".git/") {
+		return "", fmt.Errorf("不允许访问 .git 目录")
+	}
+
+	// 读取工作区时还要防止通过符号链接访问仓库外的文件
+	if t.ref == "" {
+		top, err := gitutil.TopLevel()
+		if err != nil {
+			return "", err
+		}
+		realTop, err := filepath.EvalSymlinks(top)
+		if err != nil {
+			return "", err
+		}
+		real, err := filepath.EvalSymlinks(filepath.Join(top, clean))
+		if err != nil {
+			if os.IsNotExist(err) {
+				return "", fmt.Errorf("%s 不存在", clean)
+			}
+			return "", err
+		}
+		rel, err := filepath.Rel(realTop, real)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return "", fmt.Errorf("路径超出仓库范围: %s", p)
+		}
+	}
+	return clean, nil
+}
+
+// sanitize 对工具输出脱敏并截断
+func (t *Toolbox) sanitize(name, out string) string {
+	out, _, err := redact.ApplyText(t.redactMode, name, out)
+	if err != nil {
+		return "错误: 内容包含敏感信息，已拒绝提供"
+	}
+	if len(out) > maxToolOutput {
+		out = strings.ToValidUTF8(out[:maxToolOutput], "") + "\n...（输出过长，已截断）"
+	}
+	return out
+}
+
+func schema(properties map[string]any, required ...string) map[string]any {
+	return map[string]any{
+		"type":       "object",
+		"properties": properties,
+		"required":   required,
+	}
+}
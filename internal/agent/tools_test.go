@@ -0,0 +1,149 @@
+package agent
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setupRepo 创建包含符号链接的测试仓库并提交，测试期间以其为工作目录。
+// 仓库外的 outside 目录中有一个不应被读取的 secret.txt
+func setupRepo(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	repo := filepath.Join(root, "repo")
+	outside := filepath.Join(root, "outside")
+	for _, dir := range []string{filepath.Join(repo, "sub"), outside} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files := map[string]string{
+		filepath.Join(repo, "a.go"):          "package a\n",
+		filepath.Join(repo, "sub", "b.go"):   "package sub\n",
+		filepath.Join(outside, "secret.txt"): "TOP SECRET\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	links := map[string]string{
+		"secret":  filepath.Join(outside, "secret.txt"), // 指向仓库外的文件
+		"outdir":  outside,                              // 指向仓库外的目录
+		"inlink":  "a.go",                               // 指向仓库内的文件
+		"relout":  "../outside/secret.txt",              // 相对路径指向仓库外
+		"sub/up":  "../a.go",                            // 相对路径指向仓库内
+		"sub/dot": "..",                                 // 指向仓库根目录
+	}
+	for name, target := range links {
+		if err := os.Symlink(target, filepath.Join(repo, name)); err != nil {
+			t.Skipf("无法创建符号链接: %v", err)
+		}
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(repo); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "-A"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "init"},
+	} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	return repo
+}
+
+func TestResolve(t *testing.T) {
+	repo := setupRepo(t)
+
+	tests := []struct {
+		name    string
+		path    string
+		want    string // 期望的规范化路径，为空表示应拒绝
+		wantRef string // 读取 HEAD 时期望的结果，为空时与 want 相同
+	}{
+		{name: "普通文件", path: "a.go", want: "a.go"},
+		{name: "目录", path: "sub", want: "sub"},
+		{name: "仓库根目录", path: ".", want: "."},
+		{name: "规范化路径", path: "./sub/../a.go", want: "a.go"},
+		{name: "多余的分隔符", path: "sub//b.go", want: "sub/b.go"},
+		{name: "空路径", path: ""},
+		{name: "绝对路径", path: "/etc/passwd"},
+		{name: "指向仓库内的绝对路径", path: filepath.Join(repo, "a.go")},
+		{name: "上级目录", path: ".."},
+		{name: "越出仓库", path: "../outside/secret.txt"},
+		{name: "先进入子目录再越出", path: "sub/../../outside/secret.txt"},
+		{name: ".git 目录", path: ".git"},
+		{name: ".git 下的文件", path: ".git/config"},
+		{name: "绕道访问 .git", path: "sub/../.git/HEAD"},
+		{name: "指向仓库内的符号链接", path: "inlink", want: "inlink"},
+		{name: "相对路径指向仓库内的符号链接", path: "sub/up", want: "sub/up"},
+		{name: "指向仓库根目录的符号链接", path: "sub/dot", want: "sub/dot"},
+		// 引用中的符号链接只是保存目标路径的普通对象，读取时不会跟随
+		{name: "指向仓库外文件的符号链接", path: "secret", wantRef: "secret"},
+		{name: "相对路径指向仓库外的符号链接", path: "relout", wantRef: "relout"},
+		{name: "经由指向仓库外目录的符号链接", path: "outdir/secret.txt", wantRef: "outdir/secret.txt"},
+		// 引用中不检查文件是否存在，由读取时报错
+		{name: "不存在的文件", path: "missing.go", wantRef: "missing.go"},
+	}
+	for _, ref := range []string{"", "HEAD"} {
+		tb := NewToolbox(ref, "off")
+		for _, tt := range tests {
+			want := tt.want
+			if ref != "" && tt.wantRef != "" {
+				want = tt.wantRef
+			}
+			t.Run(ref+"/"+tt.name, func(t *testing.T) {
+				got, err := tb.resolve(tt.path)
+				if want == "" {
+					if err == nil {
+						t.Errorf("resolve(%q) = %q, 应拒绝", tt.path, got)
+					}
+					return
+				}
+				if err != nil || got != want {
+					t.Errorf("resolve(%q) = %q, %v; want %q", tt.path, got, err, want)
+				}
+			})
+		}
+	}
+}
+
+func TestReadFileDoesNotFollowSymlinksOutside(t *testing.T) {
+	setupRepo(t)
+
+	tests := []struct {
+		ref  string
+		path string
+	}{
+		{ref: "", path: "secret"},
+		{ref: "", path: "outdir/secret.txt"},
+		{ref: "HEAD", path: "secret"},
+		{ref: "HEAD", path: "relout"},
+		{ref: "HEAD", path: "outdir/secret.txt"},
+		{ref: ".", path: "relout"}, // "." 与空引用一样读取工作区
+	}
+	for _, tt := range tests {
+		out, _ := NewToolbox(tt.ref, "off").readFile(tt.path, 0, 0)
+		if strings.Contains(out, "TOP SECRET") {
+			t.Errorf("ref=%q 读取 %s 时泄露了仓库外的文件内容", tt.ref, tt.path)
+		}
+	}
+
+	// 指向仓库内的符号链接可以正常读取
+	out, err := NewToolbox("", "off").readFile("sub/up", 0, 0)
+	if err != nil || !strings.Contains(out, "package a") {
+		t.Errorf("readFile(sub/up) = %q, %v", out, err)
+	}
+}
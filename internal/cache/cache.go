@@ -0,0 +1,215 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// entrySuffix 缓存条目文件后缀
+const entrySuffix = ".json"
+
+// Cache 基于文件系统的审查结果缓存，每个条目一个文件。
+// 过期条目在 Get 时发现并删除，完整清理只在本进程第一次写入时执行一次，之后按累计大小判断是否需要淘汰
+type Cache struct {
+	dir     string
+	ttl     time.Duration
+	maxSize int64
+
+	mu    sync.Mutex
+	swept bool  // 本进程是否已完整清理过一次
+	size  int64 // 条目总大小（字节）的估算值，清理时重新统计
+}
+
+// entry 缓存条目内容
+type entry struct {
+	CreatedAt time.Time `json:"created_at"`
+	Value     string    `json:"value"`
+}
+
+// New 创建缓存，ttl 为 0 表示不过期，maxSize 为 0 表示不限制总大小（字节）
+func New(dir string, ttl time.Duration, maxSize int64) *Cache {
+	return &Cache{
+		dir:     dir,
+		ttl:     ttl,
+		maxSize: maxSize,
+	}
+}
+
+// Key 根据多个组成部分计算缓存键
+func Key(parts ...string) string {
+	h := sha256.New()
+	for _, p := range parts {
+		h.Write([]byte(p))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Get 读取缓存，不存在或已过期时返回 false
+func (c *Cache) Get(key string) (string, bool) {
+	path := c.path(key)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		_ = os.Remove(path)
+		return "", false
+	}
+	if c.ttl > 0 && time.Since(e.CreatedAt) > c.ttl {
+		_ = os.Remove(path)
+		return "", false
+	}
+
+	// 更新修改时间，超出总大小时按最近使用顺序淘汰；是否过期只看 CreatedAt
+	now := time.Now()
+	_ = os.Chtimes(path, now, now)
+	return e.Value, true
+}
+
+// Put 写入缓存，超出总大小限制时淘汰最久未使用的条目
+func (c *Cache) Put(key, value string) error {
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return fmt.Errorf("创建缓存目录失败: %w", err)
+	}
+
+	data, err := json.Marshal(entry{CreatedAt: time.Now(), Value: value})
+	if err != nil {
+		return err
+	}
+
+	// 先写临时文件再重命名，避免并发读取到半截内容
+	tmp, err := os.CreateTemp(c.dir, "tmp-*")
+	if err != nil {
+		return fmt.Errorf("写入缓存失败: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("写入缓存失败: %w", err)
+	}
+	tmp.Close()
+	var replaced int64
+	if info, err := os.Stat(c.path(key)); err == nil {
+		replaced = info.Size()
+	}
+	if err := os.Rename(tmp.Name(), c.path(key)); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("写入缓存失败: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.swept {
+		c.swept = true
+		return c.evict(c.ttl > 0)
+	}
+	c.size += int64(len(data)) - replaced
+	if c.maxSize > 0 && c.size > c.maxSize {
+		return c.evict(false)
+	}
+	return nil
+}
+
+// Clear 清空所有缓存条目，返回删除的条目数
+func (c *Cache) Clear() (int, error) {
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("读取缓存目录失败: %w", err)
+	}
+
+	removed := 0
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), entrySuffix) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(c.dir, e.Name())); err != nil {
+			return removed, fmt.Errorf("删除缓存失败: %w", err)
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// evict 在超出总大小限制时按修改时间（最近使用时间）从旧到新删除条目，并重新统计总大小。
+// checkExpiry 为 true 时还会读取每个条目，按 CreatedAt 删除过期条目
+func (c *Cache) evict(checkExpiry bool) error {
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return fmt.Errorf("读取缓存目录失败: %w", err)
+	}
+
+	type fileInfo struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+
+	var files []fileInfo
+	var total int64
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), entrySuffix) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		path := filepath.Join(c.dir, e.Name())
+		if checkExpiry && c.expired(path) {
+			_ = os.Remove(path)
+			continue
+		}
+		files = append(files, fileInfo{path: path, size: info.Size(), modTime: info.ModTime()})
+		total += info.Size()
+	}
+
+	c.size = total
+	if c.maxSize <= 0 || total <= c.maxSize {
+		return nil
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].modTime.Before(files[j].modTime)
+	})
+	for _, f := range files {
+		if total <= c.maxSize {
+			break
+		}
+		if err := os.Remove(f.path); err == nil {
+			total -= f.size
+		}
+	}
+	c.size = total
+	return nil
+}
+
+// expired 判断条目是否已超过有效期，与 Get 一样按写入时间计算，无法解析的条目视为过期
+func (c *Cache) expired(path string) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return true
+	}
+	return time.Since(e.CreatedAt) > c.ttl
+}
+
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.dir, key+entrySuffix)
+}
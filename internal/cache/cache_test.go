@@ -0,0 +1,148 @@
+package cache
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+)
+
+// sizeOf 返回条目文件的大小
+func sizeOf(t *testing.T, c *Cache, key string) int64 {
+	t.Helper()
+	info, err := os.Stat(c.path(key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return info.Size()
+}
+
+// writeEntry 直接写入一个指定创建时间的条目
+func writeEntry(t *testing.T, c *Cache, key string, createdAt time.Time) {
+	t.Helper()
+	data, err := json.Marshal(entry{CreatedAt: createdAt, Value: key})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(c.path(key), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEvictUsesCreatedAt(t *testing.T) {
+	c := New(t.TempDir(), time.Hour, 0)
+	// 过期但刚被访问过的条目：修改时间是现在，创建时间已超过有效期
+	writeEntry(t, c, "old", time.Now().Add(-2*time.Hour))
+	writeEntry(t, c, "fresh", time.Now())
+
+	if err := c.evict(true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(c.path("old")); !os.IsNotExist(err) {
+		t.Errorf("过期条目应被淘汰，err = %v", err)
+	}
+	if _, ok := c.Get("fresh"); !ok {
+		t.Error("未过期的条目不应被淘汰")
+	}
+}
+
+func TestGetExpired(t *testing.T) {
+	c := New(t.TempDir(), time.Hour, 0)
+	writeEntry(t, c, "old", time.Now().Add(-2*time.Hour))
+	if _, ok := c.Get("old"); ok {
+		t.Error("过期条目应视为未命中")
+	}
+}
+
+func TestEvictBySizeKeepsRecentlyUsed(t *testing.T) {
+	c := New(t.TempDir(), 0, 0)
+	for _, key := range []string{"a", "b", "c"} {
+		if err := c.Put(key, key); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 让 a 最早写入、b 最久未使用
+	past := time.Now().Add(-time.Minute)
+	_ = os.Chtimes(c.path("a"), past, past)
+	_ = os.Chtimes(c.path("b"), past.Add(-time.Minute), past.Add(-time.Minute))
+	if _, ok := c.Get("a"); !ok {
+		t.Fatal("a 应命中")
+	}
+
+	// 条目中的时间戳长度不固定，按实际大小设置上限
+	c.maxSize = sizeOf(t, c, "a") + sizeOf(t, c, "c")
+	if err := c.evict(false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(c.path("b")); !os.IsNotExist(err) {
+		t.Error("最久未使用的 b 应被淘汰")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := c.Get(key); !ok {
+			t.Errorf("%s 不应被淘汰", key)
+		}
+	}
+}
+
+func TestPutSweepsOnce(t *testing.T) {
+	c := New(t.TempDir(), time.Hour, 0)
+	writeEntry(t, c, "stale", time.Now().Add(-2*time.Hour))
+	if err := c.Put("a", "a"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(c.path("stale")); !os.IsNotExist(err) {
+		t.Errorf("第一次写入时应清理过期条目，err = %v", err)
+	}
+
+	// 之后的写入不再逐个读取条目，过期条目留到 Get 时删除
+	writeEntry(t, c, "later", time.Now().Add(-2*time.Hour))
+	if err := c.Put("b", "b"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(c.path("later")); err != nil {
+		t.Errorf("再次写入时不应完整清理，err = %v", err)
+	}
+	if _, ok := c.Get("later"); ok {
+		t.Error("过期条目应视为未命中")
+	}
+	if _, err := os.Stat(c.path("later")); !os.IsNotExist(err) {
+		t.Errorf("Get 应删除过期条目，err = %v", err)
+	}
+}
+
+func TestPutEvictsWhenOverSize(t *testing.T) {
+	c := New(t.TempDir(), 0, 0)
+	if err := c.Put("a", "a"); err != nil {
+		t.Fatal(err)
+	}
+	// 上限足够容纳两个条目，时间戳长度不固定，留出余量
+	c.maxSize = 2*sizeOf(t, c, "a") + 10
+
+	// 覆盖已有条目不增加总大小
+	for i := 0; i < 3; i++ {
+		if err := c.Put("a", "a"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	past := time.Now().Add(-time.Minute)
+	_ = os.Chtimes(c.path("a"), past, past)
+	if err := c.Put("b", "b"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(c.path("a")); err != nil {
+		t.Fatalf("未超出大小限制时不应淘汰，err = %v", err)
+	}
+
+	// 累计大小超出限制时淘汰最久未使用的条目
+	if err := c.Put("c", "c"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(c.path("a")); !os.IsNotExist(err) {
+		t.Errorf("最久未使用的 a 应被淘汰，err = %v", err)
+	}
+	for _, key := range []string{"b", "c"} {
+		if _, ok := c.Get(key); !ok {
+			t.Errorf("%s 不应被淘汰", key)
+		}
+	}
+}
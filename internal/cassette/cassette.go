@@ -0,0 +1,254 @@
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// 控制录制与回放的环境变量，值为 cassette 文件路径
+const (
+	RecordEnv = "ACR_RECORD"
+	ReplayEnv = "ACR_REPLAY"
+)
+
+// scrubbed 替换敏感信息的占位符
+const scrubbed = "[SCRUBBED]"
+
+// minSecretLen 过短的值容易误伤正常文本，不做清除
+const minSecretLen = 8
+
+// Cassette 录制的请求与响应，按发生顺序排列
+type Cassette struct {
+	Version      int           `json:"version"`
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction 一次 HTTP 请求及其响应。请求头不录制，避免泄露认证信息
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request 录制的请求，Path 不含主机名，回放时可以使用不同的服务地址
+type Request struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Body   string `json:"body,omitempty"`
+}
+
+// Response 录制的响应，流式响应的 Body 为原始的 SSE 文本
+type Response struct {
+	Status      int    `json:"status"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        string `json:"body"`
+}
+
+// Load 读取 cassette 文件
+func Load(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取 cassette 失败: %w", err)
+	}
+	var c Cassette
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("解析 cassette %s 失败: %w", path, err)
+	}
+	return &c, nil
+}
+
+// Save 写入 cassette 文件
+func (c *Cassette) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("写入 cassette 失败: %w", err)
+	}
+	return nil
+}
+
+// Client 根据环境变量返回录制或回放用的 HTTP 客户端，两者都未设置时返回 nil。
+// secrets 为需要从录制内容中清除的值，如 API Token
+func Client(secrets ...string) (*http.Client, error) {
+	record, replay := os.Getenv(RecordEnv), os.Getenv(ReplayEnv)
+	switch {
+	case record != "" && replay != "":
+		return nil, fmt.Errorf("%s 和 %s 不能同时设置", RecordEnv, ReplayEnv)
+	case record != "":
+		return &http.Client{Transport: NewRecorder(record, http.DefaultTransport, secrets...)}, nil
+	case replay != "":
+		r, err := NewReplayer(replay, secrets...)
+		if err != nil {
+			return nil, err
+		}
+		return &http.Client{Transport: r}, nil
+	}
+	return nil, nil
+}
+
+// Recorder 转发请求并将请求与响应追加到 cassette 文件，每次请求后立即写入
+type Recorder struct {
+	path     string
+	base     http.RoundTripper
+	secrets  scrubber
+	mu       sync.Mutex
+	cassette Cassette
+}
+
+// NewRecorder 创建录制器，base 为实际发送请求的 Transport
+func NewRecorder(path string, base http.RoundTripper, secrets ...string) *Recorder {
+	return &Recorder{path: path, base: base, secrets: newScrubber(secrets), cassette: Cassette{Version: 1}}
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := r.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cassette.Interactions = append(r.cassette.Interactions, Interaction{
+		Request: Request{Method: req.Method, Path: req.URL.Path, Body: r.secrets.scrub(string(body))},
+		Response: Response{
+			Status:      resp.StatusCode,
+			ContentType: resp.Header.Get("Content-Type"),
+			Body:        r.secrets.scrub(string(respBody)),
+		},
+	})
+	if err := r.cassette.Save(r.path); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// scrubber 需要从录制内容中清除的敏感值
+type scrubber []string
+
+func newScrubber(secrets []string) scrubber {
+	var kept scrubber
+	for _, s := range secrets {
+		if len(s) >= minSecretLen {
+			kept = append(kept, s)
+		}
+	}
+	return kept
+}
+
+// tokenField 响应中 OAuth 令牌字段的值，如获取 AAD access token 的响应
+var tokenField = regexp.MustCompile(`("(?:access_token|refresh_token|id_token)"\s*:\s*)"[^"]*"`)
+
+// scrub 将文本中出现的敏感值和令牌字段替换为占位符
+func (sc scrubber) scrub(s string) string {
+	for _, secret := range sc {
+		s = strings.ReplaceAll(s, secret, scrubbed)
+	}
+	return tokenField.ReplaceAllString(s, `${1}"`+scrubbed+`"`)
+}
+
+// Replayer 按请求的方法、路径和请求体从 cassette 中查找响应，不发出任何网络请求。
+// 相同的请求按录制顺序依次回放，用完后重复最后一次的响应
+type Replayer struct {
+	mu      sync.Mutex
+	entries []Interaction
+	used    []bool
+	secrets scrubber
+}
+
+// NewReplayer 读取 cassette 文件并创建回放器，secrets 与录制时相同，使请求体按相同方式清除后再匹配
+func NewReplayer(path string, secrets ...string) (*Replayer, error) {
+	c, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Replayer{entries: c.Interactions, used: make([]bool, len(c.Interactions)), secrets: newScrubber(secrets)}, nil
+}
+
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+	key := canonical([]byte(r.secrets.scrub(string(body))))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	last := -1
+	for i, e := range r.entries {
+		if e.Request.Method != req.Method || e.Request.Path != req.URL.Path || canonical([]byte(e.Request.Body)) != key {
+			continue
+		}
+		last = i
+		if !r.used[i] {
+			r.used[i] = true
+			return e.Response.build(req), nil
+		}
+	}
+	if last >= 0 {
+		return r.entries[last].Response.build(req), nil
+	}
+	return nil, fmt.Errorf("cassette 中没有匹配的请求: %s %s", req.Method, req.URL.Path)
+}
+
+func (r Response) build(req *http.Request) *http.Response {
+	header := make(http.Header)
+	if r.ContentType != "" {
+		header.Set("Content-Type", r.ContentType)
+	}
+	return &http.Response{
+		StatusCode:    r.Status,
+		Status:        fmt.Sprintf("%d %s", r.Status, http.StatusText(r.Status)),
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(r.Body)),
+		ContentLength: int64(len(r.Body)),
+		Request:       req,
+	}
+}
+
+// readBody 读取请求体并恢复，使请求仍可发送
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil {
+		return nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// canonical 将 JSON 请求体规范化（键排序、去除空白），非 JSON 内容原样返回
+func canonical(body []byte) string {
+	var v any
+	if err := json.Unmarshal(body, &v); err != nil {
+		return string(body)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return string(body)
+	}
+	return string(data)
+}
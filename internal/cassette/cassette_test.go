@@ -0,0 +1,151 @@
+package cassette
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const token = "sk-test-0123456789abcdef"
+
+// send 通过 transport 发送一个 POST 请求，返回响应体
+func send(t *testing.T, rt http.RoundTripper, url, body string) (int, string) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, string(data)
+}
+
+func TestRecordReplayScrubsSecrets(t *testing.T) {
+	// 服务端在响应中回显 Authorization，模拟错误信息中带出 token 的情况
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		body, _ := io.ReadAll(r.Body)
+		w.Write([]byte(`{"auth": "` + r.Header.Get("Authorization") + `", "echo": ` + string(body) + `}`))
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	body := `{"model": "gpt-4o", "user": "` + token + `"}`
+	status, live := send(t, NewRecorder(path, http.DefaultTransport, token), srv.URL+"/v1/chat/completions", body)
+	if status != http.StatusOK || !strings.Contains(live, token) {
+		t.Fatalf("录制时调用方应收到原始响应: %d %s", status, live)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), token) {
+		t.Fatalf("cassette 中不应出现 token:\n%s", data)
+	}
+	if !strings.Contains(string(data), scrubbed) {
+		t.Errorf("cassette 中应包含占位符 %s:\n%s", scrubbed, data)
+	}
+
+	// 回放时使用不同的服务地址，请求体键顺序和空白不同也能匹配
+	replayer, err := NewReplayer(path, token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	status, replayed := send(t, replayer, "https://replay.invalid/v1/chat/completions", `{"user":"`+token+`","model":"gpt-4o"}`)
+	if status != http.StatusOK {
+		t.Fatalf("status = %d", status)
+	}
+	if want := strings.ReplaceAll(live, token, scrubbed); replayed != want {
+		t.Errorf("回放的响应 = %s, want %s", replayed, want)
+	}
+}
+
+func TestReplayer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	c := &Cassette{Version: 1, Interactions: []Interaction{
+		{Request: Request{Method: "POST", Path: "/v1/chat/completions", Body: `{"n":1}`}, Response: Response{Status: 200, Body: "first"}},
+		{Request: Request{Method: "POST", Path: "/v1/chat/completions", Body: `{"n":1}`}, Response: Response{Status: 429, Body: "second"}},
+		{Request: Request{Method: "POST", Path: "/v1/chat/completions", Body: `{"n":2}`}, Response: Response{Status: 200, Body: "other"}},
+	}}
+	if err := c.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	r, err := NewReplayer(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 相同的请求按录制顺序回放，用完后重复最后一次
+	tests := []struct {
+		body, want string
+		status     int
+	}{
+		{body: `{"n":1}`, want: "first", status: 200},
+		{body: `{"n":2}`, want: "other", status: 200},
+		{body: `{"n": 1}`, want: "second", status: 429},
+		{body: `{"n":1}`, want: "second", status: 429},
+	}
+	for _, tt := range tests {
+		status, got := send(t, r, "http://localhost/v1/chat/completions", tt.body)
+		if status != tt.status || got != tt.want {
+			t.Errorf("%s: got %d %q, want %d %q", tt.body, status, got, tt.status, tt.want)
+		}
+	}
+
+	req, _ := http.NewRequest(http.MethodPost, "http://localhost/v1/embeddings", strings.NewReader(`{"n":1}`))
+	if _, err := r.RoundTrip(req); err == nil {
+		t.Error("没有匹配的请求时应返回错误")
+	}
+}
+
+func TestClient(t *testing.T) {
+	t.Setenv(RecordEnv, "")
+	t.Setenv(ReplayEnv, "")
+	if c, err := Client(token); c != nil || err != nil {
+		t.Errorf("未设置环境变量时应返回 nil: %v, %v", c, err)
+	}
+
+	t.Setenv(RecordEnv, filepath.Join(t.TempDir(), "a.json"))
+	t.Setenv(ReplayEnv, filepath.Join(t.TempDir(), "b.json"))
+	if _, err := Client(token); err == nil {
+		t.Errorf("同时设置 %s 和 %s 时应返回错误", RecordEnv, ReplayEnv)
+	}
+}
+
+func TestRecorderScrubsTokenFields(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"token_type": "Bearer", "expires_in": 3599, "access_token": "eyJ0eXAi.aad.token"}`))
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	_, live := send(t, NewRecorder(path, http.DefaultTransport), srv.URL+"/tenant/oauth2/v2.0/token", "grant_type=client_credentials")
+	if !strings.Contains(live, "eyJ0eXAi.aad.token") {
+		t.Fatalf("录制时调用方应收到原始 token: %s", live)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "eyJ0eXAi.aad.token") {
+		t.Fatalf("cassette 中不应出现 access token:\n%s", data)
+	}
+	if !strings.Contains(string(data), `\"access_token\": \"`+scrubbed+`\"`) {
+		t.Errorf("access token 应替换为占位符:\n%s", data)
+	}
+}
@@ -0,0 +1,49 @@
+package catalog
+
+import "strings"
+
+// Model 内置目录中的模型信息，价格见 usage.DefaultPrices
+type Model struct {
+	ID            string
+	ContextWindow int // 上下文窗口（token）
+	MaxOutput     int // 单次回复的输出上限（token），0 表示未知
+}
+
+// Models 内置的常用模型
+var Models = []Model{
+	{ID: "gpt-3.5-turbo", ContextWindow: 16385, MaxOutput: 4096},
+	{ID: "gpt-4o", ContextWindow: 128000, MaxOutput: 16384},
+	{ID: "gpt-4o-mini", ContextWindow: 128000, MaxOutput: 16384},
+	{ID: "gpt-4.1", ContextWindow: 1047576, MaxOutput: 32768},
+	{ID: "gpt-4.1-mini", ContextWindow: 1047576, MaxOutput: 32768},
+	{ID: "gpt-4.1-nano", ContextWindow: 1047576, MaxOutput: 32768},
+	{ID: "gpt-5", ContextWindow: 400000, MaxOutput: 128000},
+	{ID: "gpt-5-mini", ContextWindow: 400000, MaxOutput: 128000},
+	{ID: "gpt-5-nano", ContextWindow: 400000, MaxOutput: 128000},
+	{ID: "o1", ContextWindow: 200000, MaxOutput: 100000},
+	{ID: "o3", ContextWindow: 200000, MaxOutput: 100000},
+	{ID: "o3-mini", ContextWindow: 200000, MaxOutput: 100000},
+	{ID: "o4-mini", ContextWindow: 200000, MaxOutput: 100000},
+	{ID: "llama3.1", ContextWindow: 131072},
+	{ID: "qwen2.5-coder", ContextWindow: 32768},
+}
+
+// Lookup 查找模型信息，先精确匹配，再按最长前缀匹配
+// （如 gpt-4o-2024-08-06 匹配 gpt-4o，qwen2.5-coder:7b 匹配 qwen2.5-coder）
+func Lookup(id string) (Model, bool) {
+	id = strings.ToLower(id)
+	best := -1
+	for i, m := range Models {
+		if m.ID == id {
+			return m, true
+		}
+		if (strings.HasPrefix(id, m.ID+"-") || strings.HasPrefix(id, m.ID+":")) &&
+			(best < 0 || len(m.ID) > len(Models[best].ID)) {
+			best = i
+		}
+	}
+	if best < 0 {
+		return Model{}, false
+	}
+	return Models[best], true
+}
@@ -0,0 +1,73 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"ai_code_reviewer/internal/cli/progress"
+	"ai_code_reviewer/internal/state"
+	"ai_code_reviewer/internal/suppress"
+
+	"github.com/spf13/cobra"
+)
+
+func CreateBaselineCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "baseline",
+		Short: "管理已知发现的基线",
+	}
+
+	var force bool
+	create := &cobra.Command{
+		Use:     "create",
+		Short:   "将上次审查的所有发现追加到 .acr-baseline.json",
+		Args:    cobra.NoArgs,
+		Example: "  # 先进行结构化审查，再记录基线\n  review main --focus general,security\n  baseline create\n\n  # 丢弃已有基线，只保留上次审查的发现\n  baseline create --force",
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := handleBaselineCreate(force); err != nil {
+				os.Exit(1)
+			}
+		},
+	}
+	create.Flags().BoolVar(&force, "force", false, "覆盖已有的基线，而不是与之合并")
+	cmd.AddCommand(create)
+
+	return cmd
+}
+
+func handleBaselineCreate(force bool) error {
+	progressTracker := progress.NewSimpleProgress("基线")
+
+	conv, err := state.LoadConversation()
+	if err != nil {
+		progressTracker.Error(err.Error())
+		return err
+	}
+	if len(conv.Findings) == 0 {
+		err := fmt.Errorf("上次审查没有结构化发现，请使用 acr review --focus 进行审查后再创建基线")
+		progressTracker.Error(err.Error())
+		return err
+	}
+
+	baseline := suppress.NewBaseline(conv.Findings)
+	added := len(baseline.Findings)
+	if !force {
+		// 增量审查或只审查部分路径时，上次审查只覆盖部分文件，合并以免丢失已有的条目
+		existing, err := suppress.LoadBaseline()
+		if err != nil {
+			progressTracker.Error(err.Error())
+			return err
+		}
+		if existing != nil {
+			added = existing.Merge(baseline)
+			baseline = existing
+		}
+	}
+	path, err := baseline.Save()
+	if err != nil {
+		progressTracker.Error(err.Error())
+		return err
+	}
+	progressTracker.Success(fmt.Sprintf("新增 %d 条发现，基线共 %d 条：%s", added, len(baseline.Findings), path))
+	return nil
+}
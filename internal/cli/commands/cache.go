@@ -0,0 +1,46 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"ai_code_reviewer/internal/cache"
+	"ai_code_reviewer/internal/cli/progress"
+	"ai_code_reviewer/internal/config"
+
+	"github.com/spf13/cobra"
+)
+
+func CreateCacheCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "管理审查结果缓存",
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "clear",
+		Short: "清空审查结果缓存",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := handleCacheClear(); err != nil {
+				os.Exit(1)
+			}
+		},
+	})
+
+	return cmd
+}
+
+func handleCacheClear() error {
+	progressTracker := progress.NewSimpleProgress("缓存清理")
+
+	dir := config.HomePath(config.DefaultCacheDir)
+	removed, err := cache.New(dir, 0, 0).Clear()
+	if err != nil {
+		progressTracker.Error(fmt.Sprintf("清理失败: %v", err))
+		return err
+	}
+
+	progressTracker.Success(fmt.Sprintf("已删除 %d 条缓存：%s", removed, dir))
+	return nil
+}
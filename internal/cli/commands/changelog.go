@@ -0,0 +1,112 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"ai_code_reviewer/internal/cli/progress"
+	"ai_code_reviewer/internal/cli/renderer"
+	"ai_code_reviewer/internal/config"
+	"ai_code_reviewer/internal/describe"
+	"ai_code_reviewer/internal/gitutil"
+	"ai_code_reviewer/internal/provider"
+
+	"github.com/spf13/cobra"
+)
+
+type ChangelogOptions struct {
+	From string
+	To   string
+	NoAI bool
+	Raw  bool
+}
+
+func CreateChangelogCommand() *cobra.Command {
+	opts := &ChangelogOptions{}
+
+	cmd := &cobra.Command{
+		Use:     "changelog",
+		Short:   "根据提交记录生成按类型分组的发布说明",
+		Args:    cobra.NoArgs,
+		Example: "  # 生成 v1.2 到 v1.3 的发布说明\n  changelog --from v1.2 --to v1.3\n\n  # 只按提交类型分组，不调用模型\n  changelog --from v1.2 --no-ai",
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := handleChangelog(opts); err != nil {
+				os.Exit(1)
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.From, "from", "", "起始引用（不包含），如上一个版本的 tag")
+	cmd.Flags().StringVar(&opts.To, "to", "HEAD", "结束引用（包含）")
+	cmd.Flags().BoolVar(&opts.NoAI, "no-ai", false, "不调用模型，直接输出按类型分组的提交列表")
+	cmd.Flags().BoolVar(&opts.Raw, "raw", false, "输出未渲染的 Markdown 文本")
+	_ = cmd.MarkFlagRequired("from")
+
+	return cmd
+}
+
+func handleChangelog(opts *ChangelogOptions) error {
+	progressTracker := progress.NewSimpleProgress("")
+	renderer, err := renderer.NewRenderer()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "初始化渲染器失败：%v\n", err)
+		return err
+	}
+
+	log, err := gitutil.CommitLog(opts.From, opts.To)
+	if err != nil {
+		progressTracker.Error(err.Error())
+		return err
+	}
+	commits := describe.ParseCommits(log)
+	if len(commits) == 0 {
+		progressTracker.Info(fmt.Sprintf("%s..%s 之间没有提交", opts.From, opts.To))
+		return nil
+	}
+
+	output := describe.FormatGroups(describe.GroupCommits(commits))
+	if !opts.NoAI {
+		cfg, err := config.LoadConfig(config.DefaultConfigFile)
+		if err != nil {
+			progressTracker.Error(fmt.Sprintf("获取配置失败：%v", err))
+			return err
+		}
+		project, err := config.LoadProjectConfig()
+		if err != nil {
+			progressTracker.Error(err.Error())
+			return err
+		}
+		prov, err := provider.New(cfg)
+		if err != nil {
+			progressTracker.Error(fmt.Sprintf("初始化模型服务失败：%v", err))
+			return err
+		}
+
+		progressTracker.Show(fmt.Sprintf("生成发布说明（%d 个提交）...", len(commits)))
+		resp, err := prov.Complete(context.Background(), provider.Request{
+			Model:    cfg.Model,
+			Messages: describe.ChangelogMessages(project.ChangelogTemplate, opts.From, opts.To, commits),
+		})
+		if resp != nil {
+			if _, recordErr := recordProviderUsage("changelog", prov, cfg, resp.Usage, 1); recordErr != nil {
+				renderer.RenderWarning(fmt.Sprintf("记录用量失败: %v", recordErr))
+			}
+		}
+		if err != nil {
+			progressTracker.Error(fmt.Sprintf("生成发布说明失败: %v", err))
+			return err
+		}
+		output = resp.Content
+	}
+
+	if opts.Raw {
+		renderer.RenderPlain(output)
+		return nil
+	}
+	if err := renderer.RenderMarkdown(output); err != nil {
+		progressTracker.Error(fmt.Sprintf("输出结果失败: %v", err))
+		return err
+	}
+	return nil
+}
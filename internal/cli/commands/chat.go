@@ -0,0 +1,280 @@
+package commands
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"ai_code_reviewer/internal/cli/progress"
+	"ai_code_reviewer/internal/cli/renderer"
+	"ai_code_reviewer/internal/config"
+	"ai_code_reviewer/internal/gitutil"
+	"ai_code_reviewer/internal/provider"
+	"ai_code_reviewer/internal/redact"
+	"ai_code_reviewer/internal/state"
+	"ai_code_reviewer/internal/usage"
+
+	"github.com/spf13/cobra"
+)
+
+const chatHelp = `可用命令：
+  /file <路径>[:起始行-结束行]  添加文件内容作为上下文
+  /diff                        添加当前工作区的 diff 作为上下文
+  /save [路径]                 将对话保存为 Markdown 文件
+  /help                        显示帮助
+  /exit                        退出（也可使用 Ctrl+D）`
+
+func CreateChatCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:     "chat",
+		Short:   "就上次审查结果继续提问",
+		Args:    cobra.NoArgs,
+		Example: "  # 先审查再追问\n  review main\n  chat",
+		Run:     runChat,
+	}
+}
+
+// chatSession 一次交互式追问会话
+type chatSession struct {
+	cfg             *config.Config
+	provider        provider.Provider
+	messages        []provider.Message
+	progressTracker *progress.SimpleProgress
+	renderer        *renderer.Renderer
+	used            usage.Usage
+	requests        int
+}
+
+func runChat(cmd *cobra.Command, args []string) {
+	progressTracker := progress.NewSimpleProgress("")
+	renderer, err := renderer.NewRenderer()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "初始化渲染器失败：%v\n", err)
+		os.Exit(1)
+	}
+
+	cfg, err := config.LoadConfig(config.DefaultConfigFile)
+	if err != nil {
+		progressTracker.Error(fmt.Sprintf("获取配置失败：%v", err))
+		os.Exit(1)
+	}
+
+	conv, err := state.LoadConversation()
+	if err != nil {
+		progressTracker.Error(err.Error())
+		os.Exit(1)
+	}
+	// 沿用审查时的模型，保证追问与审查结论一致
+	if conv.Model != "" {
+		cfg.Model = conv.Model
+	}
+
+	prov, err := provider.New(cfg)
+	if err != nil {
+		progressTracker.Error(fmt.Sprintf("初始化模型服务失败：%v", err))
+		os.Exit(1)
+	}
+
+	session := &chatSession{
+		cfg:             cfg,
+		provider:        prov,
+		messages:        conv.Messages,
+		progressTracker: progressTracker,
+		renderer:        renderer,
+	}
+	progressTracker.Info(fmt.Sprintf("已载入 %s 的审查对话（模型 %s），输入 /help 查看命令",
+		conv.CreatedAt.Local().Format("2006-01-02 15:04"), cfg.Model))
+
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		fmt.Fprint(os.Stderr, "\n> ")
+		line, err := reader.ReadString('\n')
+		if err != nil && (err != io.EOF || line == "") {
+			fmt.Fprintln(os.Stderr)
+			break
+		}
+
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "/") {
+			if quit := session.handleCommand(line); quit {
+				break
+			}
+			continue
+		}
+		session.ask(line)
+	}
+
+	if session.requests > 0 {
+		summary, err := recordProviderUsage("chat", prov, cfg, session.used, session.requests)
+		if err != nil {
+			renderer.RenderWarning(fmt.Sprintf("记录用量失败: %v", err))
+		}
+		progressTracker.Info(summary)
+	}
+}
+
+// ask 发送问题并流式输出回复
+func (s *chatSession) ask(question string) {
+	s.messages = append(s.messages, provider.Message{Role: provider.RoleUser, Content: question})
+
+	req := provider.Request{Model: s.cfg.Model, Messages: s.messages}
+	resp, err := s.provider.Stream(context.Background(), req, func(delta string) {
+		fmt.Print(delta)
+	})
+	fmt.Println()
+	s.requests++
+	if resp != nil {
+		s.used.Add(resp.Usage)
+	}
+
+	if err != nil {
+		// 请求失败时撤回本次提问，避免历史中出现没有回复的问题
+		s.messages = s.messages[:len(s.messages)-1]
+		s.progressTracker.Error(fmt.Sprintf("请求失败: %v", err))
+		return
+	}
+	s.messages = append(s.messages, provider.Message{Role: provider.RoleAssistant, Content: resp.Content})
+}
+
+// handleCommand 处理斜杠命令，返回 true 表示退出会话
+func (s *chatSession) handleCommand(line string) bool {
+	fields := strings.Fields(line)
+	arg := strings.TrimSpace(strings.TrimPrefix(line, fields[0]))
+
+	switch fields[0] {
+	case "/exit", "/quit":
+		return true
+	case "/help":
+		fmt.Fprintln(os.Stderr, chatHelp)
+	case "/file":
+		if arg == "" {
+			s.progressTracker.Error("用法: /file <路径>[:起始行-结束行]")
+			return false
+		}
+		if err := s.addFile(arg); err != nil {
+			s.progressTracker.Error(err.Error())
+		}
+	case "/diff":
+		if err := s.addDiff(); err != nil {
+			s.progressTracker.Error(err.Error())
+		}
+	case "/save":
+		if arg == "" {
+			arg = fmt.Sprintf("acr-chat-%s.md", time.Now().Format("20060102-150405"))
+		}
+		if err := os.WriteFile(arg, []byte(s.transcript()), 0644); err != nil {
+			s.progressTracker.Error(fmt.Sprintf("保存失败: %v", err))
+			return false
+		}
+		s.progressTracker.Success(fmt.Sprintf("对话已保存到 %s", arg))
+	default:
+		s.progressTracker.Error(fmt.Sprintf("未知命令: %s，输入 /help 查看可用命令", fields[0]))
+	}
+	return false
+}
+
+// addFile 读取文件（可指定行范围），脱敏后作为上下文加入对话
+func (s *chatSession) addFile(spec string) error {
+	path, start, end, err := parseFileSpec(spec)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("读取文件失败: %w", err)
+	}
+
+	lines := strings.Split(string(data), "\n")
+	if end == 0 || end > len(lines) {
+		end = len(lines)
+	}
+	if start < 1 || start > end {
+		return fmt.Errorf("无效的行范围: %d-%d", start, end)
+	}
+	content := strings.Join(lines[start-1:end], "\n")
+
+	content, redactions, err := redact.ApplyText(s.cfg.RedactMode, path, content)
+	if err != nil {
+		return fmt.Errorf("文件中%v", err)
+	}
+	if len(redactions) > 0 {
+		s.renderer.RenderWarning(fmt.Sprintf("已替换 %d 处敏感信息", len(redactions)))
+	}
+
+	s.messages = append(s.messages, provider.Message{
+		Role:    provider.RoleUser,
+		Content: fmt.Sprintf("以下是文件 `%s` 第 %d-%d 行的内容，供后续提问参考：\n\n```\n%s\n```", path, start, end, content),
+	})
+	s.progressTracker.Success(fmt.Sprintf("已添加 %s 第 %d-%d 行", path, start, end))
+	return nil
+}
+
+// addDiff 将当前工作区的 diff 脱敏后作为上下文加入对话
+func (s *chatSession) addDiff() error {
+	diff, err := gitutil.GetGitDiff("", "")
+	if err != nil {
+		return fmt.Errorf("获取 git diff 失败: %w", err)
+	}
+	if diff == "" {
+		s.progressTracker.Info("当前工作区无 diff 变更")
+		return nil
+	}
+
+	files, redactions, err := redact.Apply(s.cfg.RedactMode, gitutil.SplitDiff(diff))
+	if err != nil {
+		return fmt.Errorf("diff 中%v", err)
+	}
+	if len(redactions) > 0 {
+		s.renderer.RenderWarning(fmt.Sprintf("已替换 %d 处敏感信息", len(redactions)))
+	}
+
+	s.messages = append(s.messages, provider.Message{
+		Role:    provider.RoleUser,
+		Content: "以下是当前工作区最新的 diff，供后续提问参考：\n\n" + joinFileDiffs(files),
+	})
+	s.progressTracker.Success(fmt.Sprintf("已添加当前 diff（%d 个文件）", len(files)))
+	return nil
+}
+
+// transcript 将对话导出为 Markdown，省略系统提示词
+func (s *chatSession) transcript() string {
+	titles := map[string]string{provider.RoleUser: "🧑 提问", provider.RoleAssistant: "🤖 回复"}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# acr chat %s\n\n", time.Now().Format("2006-01-02 15:04"))
+	for _, m := range s.messages {
+		title, ok := titles[m.Role]
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(&b, "## %s\n\n%s\n\n", title, strings.TrimSpace(m.Content))
+	}
+	return b.String()
+}
+
+// parseFileSpec 解析 path[:start-end]，未指定范围时返回整个文件
+func parseFileSpec(spec string) (string, int, int, error) {
+	idx := strings.LastIndex(spec, ":")
+	if idx < 0 {
+		return spec, 1, 0, nil
+	}
+	rng := spec[idx+1:]
+	startStr, endStr, ok := strings.Cut(rng, "-")
+	if !ok {
+		return spec, 1, 0, nil
+	}
+	start, err1 := strconv.Atoi(startStr)
+	end, err2 := strconv.Atoi(endStr)
+	if err1 != nil || err2 != nil {
+		return "", 0, 0, fmt.Errorf("无效的行范围: %s，应为 起始行-结束行", rng)
+	}
+	return spec[:idx], start, end, nil
+}
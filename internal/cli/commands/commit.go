@@ -0,0 +1,183 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"ai_code_reviewer/internal/cli/progress"
+	"ai_code_reviewer/internal/cli/renderer"
+	"ai_code_reviewer/internal/commitmsg"
+	"ai_code_reviewer/internal/config"
+	"ai_code_reviewer/internal/gitutil"
+	"ai_code_reviewer/internal/provider"
+	"ai_code_reviewer/internal/redact"
+
+	"github.com/spf13/cobra"
+)
+
+type CommitOptions struct {
+	NoEdit        bool
+	InstallHook   bool
+	UninstallHook bool
+	Force         bool
+	HookFile      string
+}
+
+func CreateCommitCommand() *cobra.Command {
+	opts := &CommitOptions{}
+
+	cmd := &cobra.Command{
+		Use:     "commit",
+		Short:   "根据暂存区变更生成提交信息并提交",
+		Args:    cobra.NoArgs,
+		Example: "  # 生成提交信息，编辑后提交\n  commit\n\n  # 不打开编辑器直接提交\n  commit --no-edit\n\n  # 安装 prepare-commit-msg hook，在 git commit 时自动生成\n  commit --install-hook",
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := handleCommit(opts); err != nil {
+				os.Exit(1)
+			}
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.NoEdit, "no-edit", false, "不打开编辑器，直接使用生成的提交信息")
+	cmd.Flags().BoolVar(&opts.InstallHook, "install-hook", false, "安装 prepare-commit-msg hook")
+	cmd.Flags().BoolVar(&opts.UninstallHook, "uninstall-hook", false, "删除由 acr 安装的 prepare-commit-msg hook")
+	cmd.Flags().BoolVar(&opts.Force, "force", false, "安装 hook 时覆盖已有的 prepare-commit-msg hook")
+	cmd.Flags().StringVar(&opts.HookFile, "hook-file", "", "由 hook 调用：将提交信息写入指定文件")
+	_ = cmd.Flags().MarkHidden("hook-file")
+
+	return cmd
+}
+
+func handleCommit(opts *CommitOptions) error {
+	progressTracker := progress.NewSimpleProgress("")
+
+	switch {
+	case opts.InstallHook:
+		path, err := commitmsg.InstallHook(opts.Force)
+		if err != nil {
+			progressTracker.Error(err.Error())
+			return err
+		}
+		progressTracker.Success(fmt.Sprintf("已安装 hook: %s", path))
+		return nil
+	case opts.UninstallHook:
+		path, err := commitmsg.UninstallHook()
+		if err != nil {
+			progressTracker.Error(err.Error())
+			return err
+		}
+		progressTracker.Success(fmt.Sprintf("已删除 hook: %s", path))
+		return nil
+	}
+
+	renderer, err := renderer.NewRenderer()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "初始化渲染器失败：%v\n", err)
+		return err
+	}
+
+	cfg, err := config.LoadConfig(config.DefaultConfigFile)
+	if err != nil {
+		progressTracker.Error(fmt.Sprintf("获取配置失败：%v", err))
+		return err
+	}
+
+	diff, err := gitutil.StagedDiff()
+	if err != nil {
+		progressTracker.Error(err.Error())
+		return err
+	}
+	if diff == "" {
+		progressTracker.Info("暂存区没有变更，请先使用 git add 暂存要提交的文件")
+		// hook 模式下不能阻止 git commit 继续
+		if opts.HookFile != "" {
+			return nil
+		}
+		return fmt.Errorf("no staged changes")
+	}
+
+	files, redactions, err := redact.Apply(cfg.RedactMode, gitutil.SplitDiff(diff))
+	if err != nil {
+		progressTracker.Error(fmt.Sprintf("%v：\n%s", err, formatRedactions(redactions)))
+		return fmt.Errorf("secrets detected")
+	}
+	if len(redactions) > 0 {
+		renderer.RenderWarning(fmt.Sprintf("检测到 %d 处敏感信息，已替换为占位符：\n%s", len(redactions), formatRedactions(redactions)))
+		diff = joinFileDiffs(files)
+	}
+
+	prov, err := provider.New(cfg)
+	if err != nil {
+		progressTracker.Error(fmt.Sprintf("初始化模型服务失败：%v", err))
+		return err
+	}
+
+	progressTracker.Show("生成提交信息...")
+	resp, err := prov.Complete(context.Background(), provider.Request{
+		Model:    cfg.Model,
+		Messages: commitmsg.Messages(cfg.CommitTemplate, diff),
+	})
+	if resp != nil {
+		if _, recordErr := recordProviderUsage("commit", prov, cfg, resp.Usage, 1); recordErr != nil {
+			renderer.RenderWarning(fmt.Sprintf("记录用量失败: %v", recordErr))
+		}
+	}
+	if err != nil {
+		progressTracker.Error(fmt.Sprintf("生成提交信息失败: %v", err))
+		return err
+	}
+	message := commitmsg.Clean(resp.Content)
+
+	// hook 模式：写在 git 预置内容之前，由 git 负责打开编辑器
+	if opts.HookFile != "" {
+		existing, _ := os.ReadFile(opts.HookFile)
+		if err := os.WriteFile(opts.HookFile, []byte(message+string(existing)), 0644); err != nil {
+			progressTracker.Error(fmt.Sprintf("写入提交信息失败: %v", err))
+			return err
+		}
+		return nil
+	}
+
+	if !opts.NoEdit {
+		edited, err := editText(message+commitEditHint, "acr-commit-*.txt")
+		if err != nil {
+			progressTracker.Error(err.Error())
+			return err
+		}
+		message = commitmsg.StripComments(edited)
+		if message == "" {
+			progressTracker.Info("提交信息为空，已取消提交")
+			return nil
+		}
+	}
+
+	return runGitCommit(message)
+}
+
+// commitEditHint 编辑提交信息时附加的注释提示
+const commitEditHint = `
+# 请编辑提交信息，以 # 开头的行会被忽略。
+# 清空全部内容将取消本次提交。
+`
+
+// runGitCommit 使用给定提交信息执行 git commit，输出直接显示在终端
+func runGitCommit(message string) error {
+	f, err := os.CreateTemp("", "acr-commit-*.txt")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString(strings.TrimSpace(message) + "\n"); err != nil {
+		f.Close()
+		return err
+	}
+	f.Close()
+
+	// 通过 -F 提交时 hook 收到的来源参数为 message，acr 安装的 hook 不会重复生成
+	cmd := exec.Command("git", "commit", "--cleanup=strip", "-F", f.Name())
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	return cmd.Run()
+}
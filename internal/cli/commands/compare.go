@@ -0,0 +1,166 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"ai_code_reviewer/internal/cli/progress"
+	"ai_code_reviewer/internal/config"
+	"ai_code_reviewer/internal/provider"
+	"ai_code_reviewer/internal/review"
+	"ai_code_reviewer/internal/usage"
+
+	"github.com/spf13/cobra"
+)
+
+func CreateCompareCommand() *cobra.Command {
+	opts := &ReviewOptions{Compare: true, Format: "markdown"}
+
+	cmd := &cobra.Command{
+		Use:     "compare [source] [target]",
+		Short:   "使用多个模型审查同一 diff 并对比结果",
+		Args:    cobra.MaximumNArgs(2),
+		Example: "  # 对比两个模型在同一分支上的审查结果\n  compare main --models gpt-4o,claude-sonnet\n\n  # 只对比安全审查\n  compare main --models gpt-4o,deepseek --focus security",
+		Run:     runReview(opts),
+	}
+
+	cmd.Flags().StringVarP(&opts.SourceRef, "source", "s", "", "源分支")
+	cmd.Flags().StringVarP(&opts.TargetRef, "target", "t", "", "目标分支")
+	cmd.Flags().StringSliceVar(&opts.Models, "models", nil, "参与对比的模型，逗号分隔，至少两个，可使用模型 ID 或 profiles 中的档案名")
+	cmd.Flags().StringSliceVar(&opts.Focus, "focus", nil, "专项审查维度，逗号分隔，默认为 general")
+	cmd.Flags().BoolVar(&opts.NoCache, "no-cache", false, "不使用缓存，重新审查所有文件")
+	cmd.Flags().BoolVar(&opts.Tools, "tools", false, "允许模型调用工具读取仓库文件")
+	opts.Params.register(cmd)
+	_ = cmd.MarkFlagRequired("models")
+
+	return cmd
+}
+
+// modelRun 一个模型的审查任务及其结果
+type modelRun struct {
+	label    string // --models 中的名称，未指定时为配置的模型
+	cfg      *config.Config
+	provider provider.Provider
+	reviewer *review.Reviewer
+	plan     *review.Plan
+	results  []review.FileResult
+	err      error
+	duration time.Duration
+}
+
+// executeRuns 并发执行各模型的审查，共用一个进度条
+func executeRuns(ctx context.Context, runs []*modelRun) {
+	total := 0
+	for _, run := range runs {
+		total += len(run.plan.Pending)
+	}
+	var bar *progress.ProgressBar
+	if total > 0 {
+		bar = progress.NewProgressBar(total, "AI正在分析代码")
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	done := make([]int, len(runs))
+	for i, run := range runs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			start := time.Now()
+			run.results, run.err = run.reviewer.Execute(ctx, run.plan, func(n, _ int) {
+				if bar == nil {
+					return
+				}
+				mu.Lock()
+				defer mu.Unlock()
+				done[i] = n
+				sum := 0
+				for _, d := range done {
+					sum += d
+				}
+				bar.Update(sum)
+			})
+			run.duration = time.Since(start)
+		}()
+	}
+	wg.Wait()
+	if bar != nil {
+		bar.Finish()
+	}
+}
+
+// formatComparison 渲染多模型对比结果：每条发现由哪些模型给出，以及各模型的发现数、用量和耗时
+func formatComparison(runs []*modelRun, merged []review.FileResult) string {
+	findings := review.AllFindings(merged)
+
+	var b strings.Builder
+	b.WriteString("## 模型对比\n\n")
+	if len(findings) == 0 {
+		b.WriteString("各模型均未给出发现。\n\n")
+	} else {
+		b.WriteString("| 位置 | 严重程度 | 分类 | 问题 |")
+		for _, run := range runs {
+			fmt.Fprintf(&b, " %s |", run.label)
+		}
+		b.WriteString("\n|---|---|---|---|")
+		b.WriteString(strings.Repeat(":---:|", len(runs)))
+		b.WriteString("\n")
+		for _, f := range findings {
+			fmt.Fprintf(&b, "| %s:%s | %s | %s | %s |", f.Path, f.Location(), f.Severity, tableCell(f.Category), tableCell(f.Title))
+			for _, run := range runs {
+				mark := " "
+				if slices.Contains(f.Models, run.label) {
+					mark = "✓"
+				}
+				fmt.Fprintf(&b, " %s |", mark)
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("### 汇总\n\n")
+	b.WriteString("| 模型 | 发现 | 独有 | 请求数 | Token | 费用(USD) | 耗时 |\n")
+	b.WriteString("|---|---:|---:|---:|---:|---:|---:|\n")
+	for _, run := range runs {
+		found, unique := 0, 0
+		for _, f := range findings {
+			if slices.Contains(f.Models, run.label) {
+				found++
+				if f.Agreement == 1 {
+					unique++
+				}
+			}
+		}
+		used, requests := run.reviewer.Usage()
+		cost := "-"
+		if price, ok := usage.LookupPrice(run.cfg.Model, run.cfg.Prices); ok {
+			cost = fmt.Sprintf("%.4f", usage.Cost(used, price))
+		}
+		fmt.Fprintf(&b, "| %s | %d | %d | %d | %d | %s | %s |\n",
+			run.label, found, unique, requests, used.PromptTokens+used.CompletionTokens, cost, run.duration.Round(100*time.Millisecond))
+	}
+
+	// 无法解析为结构化结果的结论附在最后，便于人工比较
+	var raw []review.FileResult
+	for _, res := range merged {
+		if !res.Structured {
+			raw = append(raw, res)
+		}
+	}
+	if len(raw) > 0 {
+		b.WriteString("\n")
+		b.WriteString(review.Render(raw))
+	}
+	return b.String()
+}
+
+// tableCell 转义 Markdown 表格单元格中的竖线和换行
+func tableCell(s string) string {
+	s = strings.ReplaceAll(s, "|", "\\|")
+	return strings.Join(strings.Fields(s), " ")
+}
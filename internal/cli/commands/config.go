@@ -1,14 +1,17 @@
 package commands
 
 import (
+	"bufio"
 	"fmt"
 	"os"
-	"reflect"
+	"slices"
 	"strings"
+	"text/tabwriter"
 
 	"ai_code_reviewer/internal/cli/progress"
 	"ai_code_reviewer/internal/cli/renderer"
 	"ai_code_reviewer/internal/config"
+	"ai_code_reviewer/internal/provider"
 
 	"github.com/spf13/cobra"
 )
@@ -48,8 +51,52 @@ func CreateConfigCommand() *cobra.Command {
 	}
 
 	cmd.Flags().BoolVarP(&opts.Print, "print", "p", false, "查看当前配置")
-	cmd.Flags().StringArrayVarP(&opts.Set, "set", "s", nil, "设置配置项，如 -s key=value，可多次使用; 支持: token，prompt，model，url")
+	cmd.Flags().StringArrayVarP(&opts.Set, "set", "s", nil, "设置配置项，如 -s key=value，可多次使用; 支持的配置项见 acr config list-keys")
 	cmd.Flags().BoolVarP(&opts.Init, "init", "i", false, "初始化配置文件（如果不存在则新建）")
+	_ = cmd.RegisterFlagCompletionFunc("set", completeConfigSet)
+
+	cmd.AddCommand(&cobra.Command{
+		Use:               "get <key>",
+		Short:             "查看单个配置项的取值",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeConfigKeys,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := handleConfigGet(args[0]); err != nil {
+				os.Exit(1)
+			}
+		},
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:               "unset <key>...",
+		Short:             "从配置文件中删除配置项，恢复为默认值",
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: completeConfigKeys,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := handleConfigUnset(args); err != nil {
+				os.Exit(1)
+			}
+		},
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:   "edit",
+		Short: "用 $EDITOR 编辑配置文件，保存时校验",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := handleConfigEdit(); err != nil {
+				os.Exit(1)
+			}
+		},
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:   "list-keys",
+		Short: "列出支持的配置项、类型、默认值和说明",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := handleConfigListKeys(); err != nil {
+				os.Exit(1)
+			}
+		},
+	})
 
 	return cmd
 }
@@ -85,13 +132,12 @@ func handleConfigPrint() error {
 		return err
 	}
 
-	val := reflect.ValueOf(*cfg)
-	typ := val.Type()
-
-	for i := 0; i < val.NumField(); i++ {
-		field := typ.Field(i)
-		value := val.Field(i)
-		renderer.RenderConfig(field.Name, fmt.Sprintf("%v", value.Interface()))
+	for _, k := range config.Schema {
+		value := k.Format(cfg)
+		if k.Secret {
+			value = maskSecret(value)
+		}
+		renderer.RenderConfig(k.Name, value)
 	}
 	return nil
 }
@@ -100,40 +146,218 @@ func handleConfigSet(kvPairs []string) error {
 	progressTracker := progress.NewSimpleProgress("配置设置")
 	progressTracker.Show("解析配置参数...")
 
-	var updates config.Config
-
+	values := make(map[string]any)
 	for _, kv := range kvPairs {
 		key, val, err := parseConfigKeyValue(kv)
 		if err != nil {
 			progressTracker.Error(fmt.Sprintf("参数解析失败: %v", err))
 			return err
 		}
-
-		switch key {
-		case "token":
-			updates.Token = val
-		case "prompt":
-			updates.Prompt = val
-		case "model":
-			updates.Model = val
-		case "url":
-			updates.Url = val
-		default:
-			progressTracker.Error(fmt.Sprintf("不支持的配置项: %s", key))
-			return fmt.Errorf("invalid config key")
+		k, err := lookupConfigKey(key)
+		if err != nil {
+			progressTracker.Error(err.Error())
+			return err
 		}
+		parsed, err := k.Parse(val)
+		if err != nil {
+			progressTracker.Error(err.Error())
+			return err
+		}
+		values[key] = parsed
 	}
 
 	progressTracker.Show("更新配置文件...")
-	if err := config.UpdateConfigFile(config.DefaultConfigFile, updates); err != nil {
+	if err := config.UpdateConfigFile(config.DefaultConfigFile, values); err != nil {
 		progressTracker.Error(fmt.Sprintf("写入配置失败: %v", err))
 		return err
 	}
 
 	progressTracker.Success("配置已更新")
+	if _, ok := values["model"]; ok {
+		warnUnknownModel(progressTracker)
+	}
 	return nil
 }
 
+func handleConfigGet(name string) error {
+	progressTracker := progress.NewSimpleProgress("")
+
+	k, err := lookupConfigKey(name)
+	if err != nil {
+		progressTracker.Error(err.Error())
+		return err
+	}
+	cfg, err := config.LoadConfig(config.DefaultConfigFile)
+	if err != nil {
+		progressTracker.Error(fmt.Sprintf("获取配置失败：%v", err))
+		return err
+	}
+	fmt.Println(k.Format(cfg))
+	return nil
+}
+
+func handleConfigUnset(names []string) error {
+	progressTracker := progress.NewSimpleProgress("配置删除")
+
+	for _, name := range names {
+		if _, err := lookupConfigKey(name); err != nil {
+			progressTracker.Error(err.Error())
+			return err
+		}
+	}
+	removed, err := config.UnsetConfigKeys(config.DefaultConfigFile, names)
+	if err != nil {
+		progressTracker.Error(err.Error())
+		return err
+	}
+	if len(removed) == 0 {
+		progressTracker.Info(fmt.Sprintf("配置文件中未设置 %s", strings.Join(names, "，")))
+		return nil
+	}
+	progressTracker.Success(fmt.Sprintf("已删除 %s，将使用默认值", strings.Join(removed, "，")))
+	return nil
+}
+
+// handleConfigEdit 用 $EDITOR 编辑配置文件，保存时按 Schema 校验，校验通过才写回
+func handleConfigEdit() error {
+	progressTracker := progress.NewSimpleProgress("配置编辑")
+
+	path := config.HomePath(config.DefaultConfigFile)
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		progressTracker.Error(fmt.Sprintf("读取配置文件失败：%v", err))
+		return err
+	}
+
+	renderer, err := renderer.NewRenderer()
+	if err != nil {
+		progressTracker.Error(fmt.Sprintf("初始化渲染器失败：%v", err))
+		return err
+	}
+	reader := bufio.NewReader(os.Stdin)
+	content := string(data)
+	for {
+		edited, err := editText(content, "acr-config-*.yaml")
+		if err != nil {
+			progressTracker.Error(err.Error())
+			return err
+		}
+		if edited == string(data) {
+			progressTracker.Info("配置未修改")
+			return nil
+		}
+
+		errs := config.ValidateConfigData([]byte(edited))
+		if len(errs) == 0 {
+			if err := config.SaveConfigFile(config.DefaultConfigFile, []byte(edited)); err != nil {
+				progressTracker.Error(err.Error())
+				return err
+			}
+			progressTracker.Success(fmt.Sprintf("配置已保存：%s", path))
+			return nil
+		}
+
+		for _, e := range errs {
+			renderer.RenderWarning(e.Error())
+		}
+		fmt.Fprint(os.Stderr, "配置校验未通过，重新编辑？[Y/n]: ")
+		line, err := reader.ReadString('\n')
+		answer := strings.ToLower(strings.TrimSpace(line))
+		if (err != nil && line == "") || answer == "n" || answer == "no" {
+			progressTracker.Error("已放弃修改，配置文件未变更")
+			return fmt.Errorf("invalid config")
+		}
+		content = edited
+	}
+}
+
+func handleConfigListKeys() error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "配置项\t类型\t默认值\t说明")
+	for _, k := range config.Schema {
+		def := "-"
+		if k.Default != nil {
+			def = fmt.Sprint(k.Default)
+		}
+		desc := k.Description
+		if len(k.Enum) > 0 {
+			desc += "，可选: " + strings.Join(k.Enum, "，")
+		}
+		if k.Secret {
+			desc += "（敏感信息）"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", k.Name, k.Type, def, desc)
+	}
+	return w.Flush()
+}
+
+// lookupConfigKey 查找配置项，不存在时返回带提示的错误
+func lookupConfigKey(name string) (*config.Key, error) {
+	k, ok := config.LookupKey(name)
+	if !ok {
+		return nil, fmt.Errorf("不支持的配置项: %s，可执行 acr config list-keys 查看", name)
+	}
+	return k, nil
+}
+
+// completeConfigKeys 补全配置项名称，已在参数中出现的不再列出
+func completeConfigKeys(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	var names []string
+	for _, k := range config.Schema {
+		if !slices.Contains(args, k.Name) {
+			names = append(names, k.Name+"\t"+k.Description)
+		}
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeConfigSet 补全 --set 的 key=value：先补全配置项名称，再补全可选值
+func completeConfigSet(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if key, _, ok := strings.Cut(toComplete, "="); ok {
+		k, found := config.LookupKey(key)
+		if !found || k.Type != config.TypeEnum {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		var values []string
+		for _, v := range k.Enum {
+			values = append(values, key+"="+v)
+		}
+		return values, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var names []string
+	for _, k := range config.Schema {
+		if k.Settable() {
+			names = append(names, k.Name+"=\t"+k.Description)
+		}
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+}
+
+// warnUnknownModel 按更新后的配置获取服务端的模型列表，模型 ID 不在列表中时给出提示，不阻止设置
+func warnUnknownModel(progressTracker *progress.SimpleProgress) {
+	cfg, err := config.LoadConfig(config.DefaultConfigFile)
+	if err != nil || cfg.Provider == provider.AzureName || cfg.Provider == provider.FakeName {
+		// Azure 按部署名调用，模型列表中不包含部署
+		return
+	}
+	models, err := fetchModels(cfg)
+	if err != nil {
+		progressTracker.Info(fmt.Sprintf("未能校验模型 ID：%v", err))
+		return
+	}
+	if provider.HasModel(models, cfg.Model) {
+		return
+	}
+	msg := fmt.Sprintf("服务端没有模型 %s，请确认模型 ID 是否正确（可执行 acr models list 查看）", cfg.Model)
+	if similar := similarModels(models, cfg.Model, 5); len(similar) > 0 {
+		msg += "，相近的模型：" + strings.Join(similar, "、")
+	}
+	if r, err := renderer.NewRenderer(); err == nil {
+		r.RenderWarning(msg)
+	}
+}
+
 func parseConfigKeyValue(kv string) (string, string, error) {
 	parts := strings.SplitN(kv, "=", 2)
 	if len(parts) != 2 {
@@ -0,0 +1,133 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"ai_code_reviewer/internal/cli/progress"
+	"ai_code_reviewer/internal/cli/renderer"
+	"ai_code_reviewer/internal/config"
+	"ai_code_reviewer/internal/describe"
+	"ai_code_reviewer/internal/gitutil"
+	"ai_code_reviewer/internal/provider"
+	"ai_code_reviewer/internal/redact"
+
+	"github.com/spf13/cobra"
+)
+
+type DescribeOptions struct {
+	SourceRef string
+	TargetRef string
+	Raw       bool
+}
+
+func CreateDescribeCommand() *cobra.Command {
+	opts := &DescribeOptions{}
+
+	cmd := &cobra.Command{
+		Use:     "describe [source] [target]",
+		Short:   "根据 diff 和提交记录生成 PR 标题和描述",
+		Args:    cobra.MaximumNArgs(2),
+		Example: "  # 描述 feature 分支相对 main 的变更\n  describe feature main\n\n  # 输出纯文本，便于传给其他工具\n  describe feature main --raw > pr.md",
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) > 0 {
+				opts.SourceRef = args[0]
+			}
+			if len(args) > 1 {
+				opts.TargetRef = args[1]
+			}
+			if err := handleDescribe(opts); err != nil {
+				os.Exit(1)
+			}
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.SourceRef, "source", "s", "", "源分支")
+	cmd.Flags().StringVarP(&opts.TargetRef, "target", "t", "", "目标分支")
+	cmd.Flags().BoolVar(&opts.Raw, "raw", false, "输出未渲染的 Markdown 文本")
+
+	return cmd
+}
+
+func handleDescribe(opts *DescribeOptions) error {
+	progressTracker := progress.NewSimpleProgress("")
+	renderer, err := renderer.NewRenderer()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "初始化渲染器失败：%v\n", err)
+		return err
+	}
+
+	cfg, err := config.LoadConfig(config.DefaultConfigFile)
+	if err != nil {
+		progressTracker.Error(fmt.Sprintf("获取配置失败：%v", err))
+		return err
+	}
+	project, err := config.LoadProjectConfig()
+	if err != nil {
+		progressTracker.Error(err.Error())
+		return err
+	}
+
+	progressTracker.Show("获取 git diff 和提交记录...")
+	diff, err := gitutil.GetGitDiff(opts.SourceRef, opts.TargetRef)
+	if err != nil {
+		progressTracker.Error(fmt.Sprintf("获取 git diff 失败: %v", err))
+		return err
+	}
+	if diff == "" {
+		progressTracker.Info("无 diff 变更，无需生成描述")
+		return nil
+	}
+	var commits []describe.Commit
+	if !isWorkingTree(opts.SourceRef, opts.TargetRef) {
+		log, err := gitutil.CommitLog(opts.TargetRef, opts.SourceRef)
+		if err != nil {
+			progressTracker.Error(err.Error())
+			return err
+		}
+		commits = describe.ParseCommits(log)
+	}
+
+	files, redactions, err := redact.Apply(cfg.RedactMode, gitutil.SplitDiff(diff))
+	if err != nil {
+		progressTracker.Error(fmt.Sprintf("%v：\n%s", err, formatRedactions(redactions)))
+		return fmt.Errorf("secrets detected")
+	}
+	if len(redactions) > 0 {
+		renderer.RenderWarning(fmt.Sprintf("检测到 %d 处敏感信息，已替换为占位符：\n%s", len(redactions), formatRedactions(redactions)))
+	}
+
+	prov, err := provider.New(cfg)
+	if err != nil {
+		progressTracker.Error(fmt.Sprintf("初始化模型服务失败：%v", err))
+		return err
+	}
+
+	progressTracker.Show(fmt.Sprintf("生成 PR 描述（%d 个文件，%d 个提交）...", len(files), len(commits)))
+	resp, err := prov.Complete(context.Background(), provider.Request{
+		Model:    cfg.Model,
+		Messages: describe.Messages(project.DescribeTemplate, commits, files),
+	})
+	if resp != nil {
+		if _, recordErr := recordProviderUsage("describe", prov, cfg, resp.Usage, 1); recordErr != nil {
+			renderer.RenderWarning(fmt.Sprintf("记录用量失败: %v", recordErr))
+		}
+	}
+	if err != nil {
+		progressTracker.Error(fmt.Sprintf("生成 PR 描述失败: %v", err))
+		return err
+	}
+
+	title, body := describe.SplitTitle(resp.Content)
+	output := fmt.Sprintf("# %s\n\n%s\n", title, body)
+	if opts.Raw {
+		renderer.RenderPlain(output)
+		return nil
+	}
+	if err := renderer.RenderMarkdown(output); err != nil {
+		progressTracker.Error(fmt.Sprintf("输出结果失败: %v", err))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,375 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"ai_code_reviewer/internal/cli/renderer"
+	"ai_code_reviewer/internal/config"
+	"ai_code_reviewer/internal/gitutil"
+	"ai_code_reviewer/internal/provider"
+
+	"github.com/charmbracelet/glamour"
+	"github.com/muesli/termenv"
+	"github.com/openai/openai-go"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// doctorTimeout 连通性检查的超时时间
+const doctorTimeout = 15 * time.Second
+
+// 检查结果
+const (
+	checkPass = "pass"
+	checkWarn = "warn"
+	checkFail = "fail"
+	checkSkip = "skip"
+)
+
+// check 一项诊断的结果，Hint 为失败或警告时的处理建议
+type check struct {
+	Name   string
+	Status string
+	Detail string
+	Hint   string
+}
+
+func CreateDoctorCommand() *cobra.Command {
+	var offline bool
+
+	cmd := &cobra.Command{
+		Use:     "doctor",
+		Short:   "检查运行环境和配置是否正确",
+		Args:    cobra.NoArgs,
+		Example: "  # 检查 Git、配置、Token、服务连通性、模型和终端\n  doctor\n\n  # 不访问模型服务，只检查本地环境\n  doctor --offline",
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := handleDoctor(offline); err != nil {
+				os.Exit(1)
+			}
+		},
+	}
+
+	cmd.Flags().BoolVar(&offline, "offline", false, "跳过需要访问模型服务的检查")
+
+	return cmd
+}
+
+func handleDoctor(offline bool) error {
+	renderer, err := renderer.NewRenderer()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "初始化渲染器失败：%v\n", err)
+		return err
+	}
+
+	var checks []check
+	report := func(c check) {
+		checks = append(checks, c)
+		switch c.Status {
+		case checkPass:
+			renderer.RenderSuccess(fmt.Sprintf("%s：%s", c.Name, c.Detail))
+		case checkWarn:
+			renderer.RenderWarning(fmt.Sprintf("%s：%s", c.Name, c.Detail))
+		case checkFail:
+			renderer.RenderError(fmt.Sprintf("%s：%s", c.Name, c.Detail))
+		default:
+			renderer.RenderInfo(fmt.Sprintf("%s：跳过，%s", c.Name, c.Detail))
+		}
+		if c.Hint != "" && (c.Status == checkWarn || c.Status == checkFail) {
+			fmt.Fprintf(os.Stderr, "   → %s\n", strings.ReplaceAll(c.Hint, "\n", "\n     "))
+		}
+	}
+
+	report(checkGit())
+	report(checkRepo())
+	cfg, c := checkConfig()
+	report(c)
+	if cfg != nil {
+		report(checkToken(cfg))
+		if offline {
+			report(check{Name: "服务连通性", Status: checkSkip, Detail: "已指定 --offline"})
+			report(check{Name: "模型", Status: checkSkip, Detail: "已指定 --offline"})
+		} else {
+			for _, c := range checkEndpoint(cfg) {
+				report(c)
+			}
+		}
+	}
+	report(checkTerminal())
+
+	passed, warned, failed := 0, 0, 0
+	for _, c := range checks {
+		switch c.Status {
+		case checkPass:
+			passed++
+		case checkWarn:
+			warned++
+		case checkFail:
+			failed++
+		}
+	}
+	fmt.Fprintf(os.Stderr, "\n共 %d 项通过，%d 项警告，%d 项失败\n", passed, warned, failed)
+	if failed > 0 {
+		return fmt.Errorf("%d 项检查失败", failed)
+	}
+	return nil
+}
+
+// checkGit 检查 git 是否已安装
+func checkGit() check {
+	c := check{Name: "Git"}
+	path, err := exec.LookPath("git")
+	if err != nil {
+		c.Status, c.Detail = checkFail, "未找到 git 命令"
+		c.Hint = "安装 Git 并确认 git 所在目录已加入 PATH"
+		return c
+	}
+	out, err := exec.Command(path, "--version").Output()
+	if err != nil {
+		c.Status, c.Detail = checkFail, fmt.Sprintf("执行 git --version 失败: %v", err)
+		c.Hint = "确认 " + path + " 是可执行的 Git"
+		return c
+	}
+	c.Status, c.Detail = checkPass, strings.TrimSpace(string(out))
+	return c
+}
+
+// checkRepo 检查当前目录是否位于 Git 仓库中
+func checkRepo() check {
+	c := check{Name: "Git 仓库"}
+	top, err := gitutil.TopLevel()
+	if err != nil {
+		c.Status, c.Detail = checkFail, "当前目录不在 Git 仓库中"
+		c.Hint = "在需要审查的仓库目录中执行 acr"
+		return c
+	}
+	c.Status, c.Detail = checkPass, top
+	return c
+}
+
+// checkConfig 检查配置能否加载，并列出参与合并的配置来源
+func checkConfig() (*config.Config, check) {
+	c := check{Name: "配置"}
+	cfg, err := config.LoadConfig(config.DefaultConfigFile)
+	if err != nil {
+		c.Status, c.Detail = checkFail, err.Error()
+		c.Hint = fmt.Sprintf("检查 ~/%s 的 YAML 格式，或删除后执行 acr config --init 重新创建", config.DefaultConfigFile)
+		return nil, c
+	}
+
+	var sources []string
+	userFile := config.HomePath(config.DefaultConfigFile)
+	if _, err := os.Stat(userFile); err == nil {
+		sources = append(sources, userFile)
+	}
+	if top, err := gitutil.TopLevel(); err == nil {
+		project := filepath.Join(top, config.ProjectConfigFile)
+		if _, err := os.Stat(project); err == nil {
+			if _, err := config.LoadProjectConfig(); err != nil {
+				c.Status, c.Detail = checkFail, err.Error()
+				c.Hint = "检查仓库根目录下 " + config.ProjectConfigFile + " 的 YAML 格式"
+				return cfg, c
+			}
+			sources = append(sources, project)
+		}
+	}
+	env := config.SetEnvVars()
+	if cfg.TokenEnv != "" {
+		env = append(env, cfg.TokenEnv)
+	}
+	if len(env) > 0 {
+		sources = append(sources, "环境变量 "+strings.Join(env, "、"))
+	}
+
+	if len(sources) == 0 {
+		c.Status, c.Detail = checkWarn, "未找到配置文件，使用默认配置"
+		c.Hint = "执行 acr config --init 创建配置文件，再通过 acr config --set 设置 token 和 model"
+		return cfg, c
+	}
+	c.Status, c.Detail = checkPass, "已合并 "+strings.Join(sources, "，")
+	if cfg.Fallback != "" {
+		c.Detail += fmt.Sprintf("；模型 %s，回退 %s", cfg.Model, cfg.Fallback)
+	}
+	return cfg, c
+}
+
+// checkToken 检查认证信息是否已设置，token 只显示首尾几位
+func checkToken(cfg *config.Config) check {
+	c := check{Name: "Token"}
+	switch {
+	case cfg.Provider == provider.FakeName:
+		c.Status, c.Detail = checkSkip, "fake 服务提供方不需要认证"
+	case cfg.Provider == provider.AzureName && cfg.AuthMode == provider.AzureAuthAAD:
+		var missing []string
+		for _, name := range []string{"AZURE_TENANT_ID", "AZURE_CLIENT_ID", "AZURE_CLIENT_SECRET"} {
+			if os.Getenv(name) == "" {
+				missing = append(missing, name)
+			}
+		}
+		if len(missing) > 0 {
+			c.Status, c.Detail = checkFail, "aad 认证缺少环境变量 "+strings.Join(missing, "、")
+			c.Hint = "设置 Azure AD 应用的租户、客户端 ID 和密钥，或改用 acr config --set auth_mode=api-key"
+		} else {
+			c.Status, c.Detail = checkPass, "使用 Azure AD 客户端凭据（"+maskSecret(os.Getenv("AZURE_CLIENT_ID"))+"）"
+		}
+	case cfg.Token != "":
+		c.Status, c.Detail = checkPass, maskSecret(cfg.Token)
+		if cfg.TokenEnv != "" {
+			c.Detail += "（取自 " + cfg.TokenEnv + "）"
+		}
+	case cfg.Url != "" && cfg.Provider != provider.AzureName:
+		c.Status, c.Detail = checkWarn, "未设置 token，只能访问不需要认证的服务"
+		c.Hint = "服务需要认证时执行 acr config --set token=<API Key>"
+	default:
+		c.Status, c.Detail = checkFail, "未设置 token"
+		c.Hint = "执行 acr config --set token=<API Key>，或设置环境变量 ACR_TOKEN"
+	}
+	return c
+}
+
+// maskSecret 只保留首尾几位，避免在终端和日志中泄露
+func maskSecret(s string) string {
+	if len(s) < 12 {
+		return strings.Repeat("*", len(s))
+	}
+	return s[:4] + strings.Repeat("*", 8) + s[len(s)-4:]
+}
+
+// checkEndpoint 通过列出模型检查服务是否可访问，并确认配置的模型存在
+func checkEndpoint(cfg *config.Config) []check {
+	conn := check{Name: "服务连通性"}
+	model := check{Name: "模型"}
+
+	// 只检查主模型，回退模型在各自的档案中配置
+	primary := *cfg
+	primary.Fallback = ""
+	prov, err := provider.New(&primary)
+	if err != nil {
+		conn.Status, conn.Detail = checkFail, fmt.Sprintf("初始化模型服务失败：%v", err)
+		conn.Hint = "检查 provider、url 等配置"
+		model.Status, model.Detail = checkSkip, "模型服务未初始化"
+		return []check{conn, model}
+	}
+	lister, ok := prov.(provider.ModelLister)
+	if !ok {
+		conn.Status, conn.Detail = checkSkip, fmt.Sprintf("%s 服务提供方不支持列出模型", prov.Name())
+		model.Status, model.Detail = checkSkip, "无法获取模型列表"
+		return []check{conn, model}
+	}
+
+	endpoint := cfg.Url
+	if endpoint == "" {
+		endpoint = "https://api.openai.com/v1"
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), doctorTimeout)
+	defer cancel()
+	models, err := lister.ListModels(ctx)
+	if err != nil {
+		conn.Status, conn.Detail = checkFail, fmt.Sprintf("访问 %s 失败：%v", endpoint, err)
+		conn.Hint = endpointHint(err)
+		model.Status, model.Detail = checkSkip, "无法获取模型列表"
+		return []check{conn, model}
+	}
+	conn.Status, conn.Detail = checkPass, fmt.Sprintf("%s 可访问，共 %d 个模型", endpoint, len(models))
+
+	switch {
+	case cfg.Provider == provider.AzureName:
+		model.Status, model.Detail = checkSkip, "Azure 按部署名调用，模型列表中不包含部署"
+	case provider.HasModel(models, cfg.Model):
+		model.Status, model.Detail = checkPass, cfg.Model
+	default:
+		model.Status, model.Detail = checkFail, fmt.Sprintf("服务端没有模型 %s", cfg.Model)
+		model.Hint = "执行 acr config --set model=<模型 ID> 设置为可用的模型"
+		if similar := similarModels(models, cfg.Model, 5); len(similar) > 0 {
+			model.Hint += "，相近的模型：" + strings.Join(similar, "、")
+		}
+	}
+	return []check{conn, model}
+}
+
+// endpointHint 根据错误类型给出处理建议
+func endpointHint(err error) string {
+	var apiErr *openai.Error
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusUnauthorized, http.StatusForbidden:
+			return "token 无效、已过期或没有权限，执行 acr config --set token=<API Key> 更新"
+		case http.StatusNotFound:
+			return "url 路径可能不正确，OpenAI 兼容接口通常以 /v1 结尾"
+		}
+		return "服务端返回错误，稍后重试或检查服务状态"
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return "请求超时，检查网络或代理设置（HTTPS_PROXY）"
+	}
+	return "检查 url 是否正确、服务是否启动，以及网络和代理设置（HTTPS_PROXY）"
+}
+
+// similarModels 返回与 model 有共同前缀的模型，最多 n 个
+func similarModels(models []provider.RemoteModel, model string, n int) []string {
+	prefix := strings.ToLower(model)
+	if i := strings.IndexAny(prefix, "-:/"); i > 0 {
+		prefix = prefix[:i]
+	}
+	var list []string
+	for _, m := range models {
+		if strings.HasPrefix(strings.ToLower(m.ID), prefix) {
+			list = append(list, m.ID)
+			if len(list) == n {
+				break
+			}
+		}
+	}
+	return list
+}
+
+// checkTerminal 检查终端能否显示 glamour 渲染的 Markdown 样式，判断方式与 glamour.WithAutoStyle 一致
+func checkTerminal() check {
+	c := check{Name: "终端"}
+	if style := os.Getenv("GLAMOUR_STYLE"); style != "" {
+		if _, err := glamour.NewTermRenderer(glamour.WithEnvironmentConfig()); err != nil {
+			c.Status, c.Detail = checkFail, fmt.Sprintf("GLAMOUR_STYLE=%s 无效：%v", style, err)
+			c.Hint = "将 GLAMOUR_STYLE 设置为 dark、light、notty 等内置样式或样式文件路径，或取消设置"
+			return c
+		}
+		c.Status, c.Detail = checkPass, "使用 GLAMOUR_STYLE="+style
+		return c
+	}
+
+	if !term.IsTerminal(int(os.Stdout.Fd())) {
+		c.Status, c.Detail = checkWarn, "标准输出不是终端，审查结果将以纯文本输出"
+		c.Hint = "重定向到文件或管道时属于正常情况；在终端中仍看到此提示时检查终端模拟器"
+		return c
+	}
+	profile := termenv.NewOutput(os.Stdout).Profile
+	if profile == termenv.Ascii {
+		c.Status, c.Detail = checkWarn, fmt.Sprintf("终端不支持颜色（TERM=%s）", os.Getenv("TERM"))
+		c.Hint = "设置 TERM=xterm-256color，或设置 GLAMOUR_STYLE=notty 使用无颜色样式"
+		return c
+	}
+	background := "浅色"
+	if termenv.HasDarkBackground() {
+		background = "深色"
+	}
+	c.Status, c.Detail = checkPass, fmt.Sprintf("支持 %s，使用%s样式", profileName(profile), background)
+	return c
+}
+
+// profileName 终端颜色能力的名称
+func profileName(p termenv.Profile) string {
+	switch p {
+	case termenv.TrueColor:
+		return "真彩色"
+	case termenv.ANSI256:
+		return "256 色"
+	default:
+		return "16 色"
+	}
+}
@@ -0,0 +1,217 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"ai_code_reviewer/internal/cache"
+	"ai_code_reviewer/internal/cli/progress"
+	"ai_code_reviewer/internal/cli/renderer"
+	"ai_code_reviewer/internal/config"
+	"ai_code_reviewer/internal/eval"
+	"ai_code_reviewer/internal/focus"
+	"ai_code_reviewer/internal/gitutil"
+	"ai_code_reviewer/internal/provider"
+	"ai_code_reviewer/internal/redact"
+	"ai_code_reviewer/internal/review"
+	"ai_code_reviewer/internal/usage"
+
+	"github.com/spf13/cobra"
+)
+
+type EvalOptions struct {
+	Focus     []string
+	Tolerance int
+	Save      string
+	Baseline  string
+	MinF1     float64
+	NoCache   bool
+	Verbose   bool
+	Params    paramFlags
+}
+
+func CreateEvalCommand() *cobra.Command {
+	opts := &EvalOptions{}
+
+	cmd := &cobra.Command{
+		Use:     "eval <dir>",
+		Short:   "使用标注好的 diff 用例评测审查质量",
+		Args:    cobra.ExactArgs(1),
+		Example: "  # 评测当前配置并保存结果\n  eval testdata/eval --save eval-main.json\n\n  # 修改提示词后与之前的结果对比\n  eval testdata/eval --baseline eval-main.json\n\n  # 在 CI 中 F1 低于 0.6 时失败\n  eval testdata/eval --min-f1 0.6",
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := handleEval(cmd, args[0], opts); err != nil {
+				os.Exit(1)
+			}
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&opts.Focus, "focus", nil, "审查维度，逗号分隔，用例未指定时使用；默认使用配置中的 focus 或 general")
+	cmd.Flags().IntVar(&opts.Tolerance, "tolerance", 3, "行号允许的误差")
+	cmd.Flags().StringVar(&opts.Save, "save", "", "将本次结果保存为 JSON 文件")
+	cmd.Flags().StringVar(&opts.Baseline, "baseline", "", "与之前保存的结果对比")
+	cmd.Flags().Float64Var(&opts.MinF1, "min-f1", 0, "总 F1 低于该值时以非零状态退出")
+	cmd.Flags().BoolVar(&opts.NoCache, "no-cache", false, "不使用缓存，重新审查所有用例")
+	cmd.Flags().BoolVarP(&opts.Verbose, "verbose", "v", false, "列出各用例漏报和误报的发现")
+	opts.Params.register(cmd)
+
+	return cmd
+}
+
+func handleEval(cmd *cobra.Command, dir string, opts *EvalOptions) error {
+	progressTracker := progress.NewSimpleProgress("")
+	renderer, err := renderer.NewRenderer()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "初始化渲染器失败：%v\n", err)
+		return err
+	}
+
+	cfg, err := config.LoadConfig(config.DefaultConfigFile)
+	if err != nil {
+		progressTracker.Error(fmt.Sprintf("获取配置失败：%v", err))
+		return err
+	}
+	opts.Params.apply(cmd, &cfg.Params)
+	fixtures, err := eval.LoadFixtures(dir)
+	if err != nil {
+		progressTracker.Error(err.Error())
+		return err
+	}
+	var base *eval.Run
+	if opts.Baseline != "" {
+		if base, err = eval.LoadRun(opts.Baseline); err != nil {
+			progressTracker.Error(err.Error())
+			return err
+		}
+	}
+
+	prov, err := provider.New(cfg)
+	if err != nil {
+		progressTracker.Error(fmt.Sprintf("初始化模型服务失败：%v", err))
+		return err
+	}
+	var resultCache *cache.Cache
+	if !opts.NoCache {
+		if resultCache, err = review.OpenCache(cfg); err != nil {
+			progressTracker.Error(fmt.Sprintf("打开缓存失败: %v", err))
+			return err
+		}
+	}
+
+	run := &eval.Run{Time: time.Now(), Provider: prov.Name(), Model: cfg.Model, Focus: opts.Focus, Tolerance: opts.Tolerance}
+	if !cfg.Params.IsZero() {
+		run.Params = &cfg.Params
+	}
+	var used usage.Usage
+	var requests int
+	var prompts []string
+
+	progressTracker.Show(fmt.Sprintf("评测 %d 个用例...", len(fixtures)))
+	bar := progress.NewProgressBar(len(fixtures), "AI正在审查用例")
+	for n, fx := range fixtures {
+		names := fx.Focus
+		if len(names) == 0 {
+			names = opts.Focus
+		}
+		passes, err := resolvePasses(cfg, names)
+		if err == nil && len(passes) == 0 {
+			passes, err = focus.Resolve([]string{focus.General}, nil, cfg.Prompt)
+		}
+		if err != nil {
+			bar.Finish()
+			progressTracker.Error(fmt.Sprintf("用例 %s: %v", fx.Name, err))
+			return err
+		}
+		for _, p := range passes {
+			if prompt := p.SystemPrompt(); !slices.Contains(prompts, prompt) {
+				prompts = append(prompts, prompt)
+			}
+		}
+
+		files, _, err := redact.Apply(cfg.RedactMode, gitutil.SplitDiff(fx.Diff))
+		if err != nil {
+			// 拒绝发送的用例不审查，同样视为全部漏报
+			run.Results = append(run.Results, eval.Result{Name: fx.Name, Score: eval.Score{FN: len(fx.Expected)}, Missed: fx.Expected, Error: err.Error()})
+			bar.Update(n + 1)
+			continue
+		}
+		reviewer := review.NewReviewer(cfg, prov, resultCache)
+		reviewer.SetPasses(passes)
+		results, err := reviewer.Execute(context.Background(), reviewer.Prepare(files), nil)
+		u, r := reviewer.Usage()
+		used.Add(u)
+		requests += r
+
+		if err != nil {
+			// 审查失败的用例视为全部漏报
+			run.Results = append(run.Results, eval.Result{Name: fx.Name, Score: eval.Score{FN: len(fx.Expected)}, Missed: fx.Expected, Error: err.Error()})
+		} else {
+			run.Results = append(run.Results, eval.Match(fx.Name, fx.Expected, review.AllFindings(results), opts.Tolerance))
+		}
+		bar.Update(n + 1)
+	}
+	bar.Finish()
+	if summary := failoverSummary(prov); summary != "" {
+		renderer.RenderWarning(fmt.Sprintf("%s\n部分用例不是由 %s 审查的，评测结果不能完全代表该模型", summary, cfg.Model))
+	}
+	run.Prompt = cache.Key(prompts...)[:12]
+	run.Finish()
+
+	if requests > 0 {
+		summary, recordErr := recordProviderUsage("eval", prov, cfg, used, requests)
+		if recordErr != nil {
+			renderer.RenderWarning(fmt.Sprintf("记录用量失败: %v", recordErr))
+		}
+		progressTracker.Info(summary)
+	}
+
+	fmt.Println()
+	run.WriteTable(os.Stdout)
+	for _, res := range run.Results {
+		if res.Error != "" {
+			renderer.RenderWarning(fmt.Sprintf("用例 %s 审查失败: %s", res.Name, res.Error))
+		}
+	}
+	if opts.Verbose {
+		fmt.Print(formatMisses(run))
+	}
+	if base != nil {
+		fmt.Println()
+		eval.WriteComparison(os.Stdout, base, run)
+	}
+
+	if opts.Save != "" {
+		if err := run.Save(opts.Save); err != nil {
+			progressTracker.Error(err.Error())
+			return err
+		}
+		progressTracker.Success(fmt.Sprintf("评测结果已保存到 %s", opts.Save))
+	}
+	if opts.MinF1 > 0 && run.Total.F1() < opts.MinF1 {
+		err := fmt.Errorf("F1 %.3f 低于 %.3f", run.Total.F1(), opts.MinF1)
+		progressTracker.Error(err.Error())
+		return err
+	}
+	return nil
+}
+
+// formatMisses 列出各用例漏报的期望和多出的发现
+func formatMisses(run *eval.Run) string {
+	var b strings.Builder
+	for _, res := range run.Results {
+		if len(res.Missed) == 0 && len(res.Extra) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n%s\n", res.Name)
+		for _, e := range res.Missed {
+			fmt.Fprintf(&b, "  漏报 %s:%d %s\n", e.File, e.Line, e.Category)
+		}
+		for _, f := range res.Extra {
+			fmt.Fprintf(&b, "  误报 %s:%s %s %s\n", f.Path, f.Location(), f.Category, f.Title)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,69 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"ai_code_reviewer/internal/config"
+	"ai_code_reviewer/internal/provider"
+	"ai_code_reviewer/internal/review"
+	"ai_code_reviewer/internal/usage"
+)
+
+// recordProviderUsage 记录用量；发生过模型回退时按实际使用的模型分别记录和计价
+func recordProviderUsage(command string, prov provider.Provider, cfg *config.Config, u usage.Usage, requests int) (string, error) {
+	fb, ok := prov.(*provider.Fallback)
+	if !ok || len(fb.Failovers()) == 0 {
+		return recordUsage(command, prov.Name(), cfg, u, requests)
+	}
+	var summaries []string
+	var errs []error
+	for _, s := range fb.Served() {
+		summary, err := recordUsage(command, s.Provider, cfg.ForModel(s.Model), s.Usage, s.Requests)
+		summaries = append(summaries, fmt.Sprintf("[%s] %s", s.Model, summary))
+		errs = append(errs, err)
+	}
+	return strings.Join(summaries, "\n"), errors.Join(errs...)
+}
+
+// failoverSummary 汇总模型回退的次数和原因，没有发生回退时返回空字符串
+func failoverSummary(prov provider.Provider) string {
+	fb, ok := prov.(*provider.Fallback)
+	if !ok {
+		return ""
+	}
+	type key struct{ from, to, reason string }
+	counts := make(map[key]int)
+	var order []key
+	for _, f := range fb.Failovers() {
+		k := key{f.From, f.To, f.Reason}
+		if counts[k] == 0 {
+			order = append(order, k)
+		}
+		counts[k]++
+	}
+	var lines []string
+	for _, k := range order {
+		lines = append(lines, fmt.Sprintf("模型 %s 请求失败（%s）%d 次，已改用 %s", k.from, k.reason, counts[k], k.to))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// modelInfos 返回实际给出回复的模型，未配置回退时为配置的模型
+func modelInfos(prov provider.Provider, cfg *config.Config) []review.ModelInfo {
+	fb, ok := prov.(*provider.Fallback)
+	if !ok {
+		return []review.ModelInfo{review.NewModelInfo(prov.Name(), cfg)}
+	}
+	var infos []review.ModelInfo
+	for i, s := range fb.Served() {
+		if s.Replies == 0 {
+			continue
+		}
+		info := review.NewModelInfo(s.Provider, cfg.ForModel(s.Model))
+		info.Fallback = i > 0 || s.Model != cfg.Model
+		infos = append(infos, info)
+	}
+	return infos
+}
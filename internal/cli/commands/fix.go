@@ -0,0 +1,291 @@
+package commands
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"ai_code_reviewer/internal/cli/progress"
+	"ai_code_reviewer/internal/cli/renderer"
+	"ai_code_reviewer/internal/config"
+	"ai_code_reviewer/internal/fix"
+	"ai_code_reviewer/internal/gitutil"
+	"ai_code_reviewer/internal/provider"
+	"ai_code_reviewer/internal/state"
+
+	"github.com/spf13/cobra"
+)
+
+type FixOptions struct {
+	Yes    bool
+	Verify string
+}
+
+func CreateFixCommand() *cobra.Command {
+	opts := &FixOptions{}
+
+	cmd := &cobra.Command{
+		Use:     "fix",
+		Short:   "根据上次审查结果生成并应用修复补丁",
+		Args:    cobra.NoArgs,
+		Example: "  # 逐个确认补丁\n  fix\n\n  # 应用全部补丁，测试失败时回滚\n  fix --yes --verify \"go test ./...\"",
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := handleFix(opts); err != nil {
+				os.Exit(1)
+			}
+		},
+	}
+
+	cmd.Flags().BoolVarP(&opts.Yes, "yes", "y", false, "不逐个确认，直接应用所有可用补丁")
+	cmd.Flags().StringVar(&opts.Verify, "verify", "", "应用每个补丁后执行的验证命令，失败时回滚该补丁")
+
+	return cmd
+}
+
+func handleFix(opts *FixOptions) error {
+	progressTracker := progress.NewSimpleProgress("")
+	renderer, err := renderer.NewRenderer()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "初始化渲染器失败：%v\n", err)
+		return err
+	}
+
+	cfg, err := config.LoadConfig(config.DefaultConfigFile)
+	if err != nil {
+		progressTracker.Error(fmt.Sprintf("获取配置失败：%v", err))
+		return err
+	}
+
+	conv, err := state.LoadConversation()
+	if err != nil {
+		progressTracker.Error(err.Error())
+		return err
+	}
+	if conv.Model != "" {
+		cfg.Model = conv.Model
+	}
+
+	// 补丁基于当前工作区的文件内容生成，须与上次审查的代码一致
+	warning, err := checkReviewedTree(conv)
+	if err != nil {
+		progressTracker.Error(err.Error())
+		return err
+	}
+	if warning != "" {
+		renderer.RenderWarning(warning)
+	}
+
+	var diff string
+	for _, m := range conv.Messages {
+		if m.Role == provider.RoleUser {
+			diff = m.Content
+			break
+		}
+	}
+	messages, redactions, err := fix.Messages(conv.Messages, diff, cfg.RedactMode)
+	if err != nil {
+		progressTracker.Error(err.Error())
+		return err
+	}
+	if len(redactions) > 0 {
+		renderer.RenderWarning(fmt.Sprintf("检测到 %d 处敏感信息，已替换为占位符：\n%s", len(redactions), formatRedactions(redactions)))
+	}
+
+	prov, err := provider.New(cfg)
+	if err != nil {
+		progressTracker.Error(fmt.Sprintf("初始化模型服务失败：%v", err))
+		return err
+	}
+
+	progressTracker.Show("请求修复补丁...")
+	resp, err := prov.Complete(context.Background(), provider.Request{Model: cfg.Model, Messages: messages})
+	if resp != nil {
+		summary, recordErr := recordProviderUsage("fix", prov, cfg, resp.Usage, 1)
+		if recordErr != nil {
+			renderer.RenderWarning(fmt.Sprintf("记录用量失败: %v", recordErr))
+		}
+		defer progressTracker.Info(summary)
+	}
+	if err != nil {
+		progressTracker.Error(fmt.Sprintf("请求补丁失败: %v", err))
+		return err
+	}
+
+	patches := fix.Parse(resp.Content)
+	if len(patches) == 0 {
+		progressTracker.Info("模型没有给出可应用的补丁")
+		return nil
+	}
+	progressTracker.Success(fmt.Sprintf("收到 %d 个补丁", len(patches)))
+
+	reader := bufio.NewReader(os.Stdin)
+	var applied, skipped, failed int
+	for i, p := range patches {
+		fmt.Fprintf(os.Stderr, "\n[%d/%d] %s（%s）\n", i+1, len(patches), p.Title, strings.Join(p.Files(), ", "))
+
+		if err := fix.Check(p); err != nil {
+			renderer.RenderWarning(fmt.Sprintf("补丁无法应用到当前工作区，已跳过：%v", err))
+			failed++
+			continue
+		}
+
+		if !opts.Yes {
+			var quit bool
+			p, quit = confirmPatch(reader, renderer, p)
+			if quit {
+				break
+			}
+			if p.Diff == "" {
+				skipped++
+				continue
+			}
+		}
+
+		if err := fix.Apply(p); err != nil {
+			renderer.RenderWarning(fmt.Sprintf("应用补丁失败：%v", err))
+			failed++
+			continue
+		}
+
+		if opts.Verify != "" {
+			progressTracker.Show(fmt.Sprintf("执行验证命令: %s", opts.Verify))
+			if err := runVerify(opts.Verify); err != nil {
+				if revertErr := fix.Revert(p); revertErr != nil {
+					progressTracker.Error(fmt.Sprintf("验证失败且回滚补丁失败，请手动处理：%v", revertErr))
+					return revertErr
+				}
+				renderer.RenderWarning(fmt.Sprintf("验证失败，已回滚补丁：%v", err))
+				failed++
+				continue
+			}
+		}
+		progressTracker.Success("补丁已应用")
+		applied++
+	}
+
+	progressTracker.Info(fmt.Sprintf("已应用 %d 个补丁，跳过 %d 个，失败 %d 个", applied, skipped, failed))
+	return nil
+}
+
+// checkReviewedTree 核对当前工作区与上次审查的代码：HEAD 与审查的提交不同时返回错误，
+// 审查之后工作区又有改动时返回警告
+func checkReviewedTree(conv *state.Conversation) (string, error) {
+	if conv.Commit == "" {
+		// 旧版本保存的审查记录或尚无提交的仓库，无法核对
+		return "", nil
+	}
+	head, err := gitutil.RevParse("")
+	if err != nil {
+		return "", err
+	}
+
+	reviewed := "工作区"
+	if !conv.WorkingTree {
+		reviewed = conv.Ref
+		if reviewed == "" {
+			reviewed = "HEAD"
+		}
+	}
+	if head != conv.Commit {
+		return "", fmt.Errorf("上次审查的是 %s（提交 %s），当前 HEAD 为 %s，补丁会基于当前工作区生成，与审查的代码不一致；请检出被审查的提交或重新运行 acr review",
+			reviewed, shortSHA(conv.Commit), shortSHA(head))
+	}
+
+	diff, err := gitutil.GetGitDiff("", "")
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case conv.WorkingTree && diffDigest(diff) != conv.DiffDigest:
+		return "工作区在上次审查之后有改动，补丁可能与当前文件内容对不上", nil
+	case !conv.WorkingTree && diff != "":
+		return fmt.Sprintf("上次审查的是 %s 的提交，工作区中有未提交的修改，补丁可能与当前文件内容对不上", reviewed), nil
+	}
+	return "", nil
+}
+
+// confirmPatch 展示补丁并询问处理方式，返回最终要应用的补丁（Diff 为空表示跳过）以及是否退出
+func confirmPatch(reader *bufio.Reader, r *renderer.Renderer, p fix.Patch) (fix.Patch, bool) {
+	for {
+		r.RenderDiff(p.Diff)
+		fmt.Fprint(os.Stderr, "应用此补丁？[a]应用 [s]跳过 [e]编辑 [q]退出: ")
+		line, err := reader.ReadString('\n')
+		if err != nil && line == "" {
+			fmt.Fprintln(os.Stderr)
+			return fix.Patch{}, true
+		}
+
+		switch strings.ToLower(strings.TrimSpace(line)) {
+		case "a", "y", "yes":
+			return p, false
+		case "s", "n", "no":
+			return fix.Patch{}, false
+		case "q", "quit":
+			return fix.Patch{}, true
+		case "e", "edit":
+			edited, err := editText(p.Diff, "acr-fix-*.patch")
+			if err != nil {
+				r.RenderWarning(fmt.Sprintf("编辑失败: %v", err))
+				continue
+			}
+			if strings.TrimSpace(edited) == "" {
+				return fix.Patch{}, false
+			}
+			candidate := fix.Patch{Title: p.Title, Diff: edited}
+			if err := fix.Check(candidate); err != nil {
+				r.RenderWarning(fmt.Sprintf("编辑后的补丁无法应用：%v", err))
+				continue
+			}
+			p = candidate
+		}
+	}
+}
+
+// editText 将内容写入临时文件并用 $EDITOR（默认 vi）打开，返回编辑后的内容
+func editText(content, pattern string) (string, error) {
+	f, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString(content); err != nil {
+		f.Close()
+		return "", err
+	}
+	f.Close()
+
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	// 通过 shell 执行，支持 EDITOR="code --wait" 这类带参数的写法
+	cmd := exec.Command("sh", "-c", editor+` "$1"`, "sh", f.Name())
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("运行编辑器 %s 失败: %w", editor, err)
+	}
+
+	data, err := os.ReadFile(f.Name())
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// runVerify 在仓库根目录执行验证命令
+func runVerify(command string) error {
+	top, err := gitutil.TopLevel()
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Dir = top
+	cmd.Stdout, cmd.Stderr = os.Stderr, os.Stderr
+	return cmd.Run()
+}
@@ -0,0 +1,55 @@
+package commands
+
+import (
+	"os"
+	"testing"
+
+	"ai_code_reviewer/internal/gitutil"
+	"ai_code_reviewer/internal/state"
+)
+
+func TestCheckReviewedTree(t *testing.T) {
+	useGitRepo(t)
+	reviewed := commitFile(t, "a.go", "package a\n")
+	gitRun(t, "branch", "feature")
+
+	// 审查工作区时记录的 diff 摘要
+	if err := os.WriteFile("a.go", []byte("package a\n\nvar x = 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	diff, err := gitutil.GetGitDiff("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	workingTree := &state.Conversation{Commit: reviewed, WorkingTree: true, DiffDigest: diffDigest(diff)}
+
+	tests := []struct {
+		name        string
+		conv        *state.Conversation
+		wantWarning bool
+		wantErr     bool
+	}{
+		{name: "旧版本的审查记录不核对", conv: &state.Conversation{}},
+		{name: "工作区与审查时一致", conv: workingTree},
+		{name: "工作区在审查后又有改动", conv: &state.Conversation{Commit: reviewed, WorkingTree: true, DiffDigest: diffDigest("")}, wantWarning: true},
+		{name: "审查分支后工作区有未提交修改", conv: &state.Conversation{Ref: "feature", Commit: reviewed}, wantWarning: true},
+		{name: "HEAD 不是审查的提交", conv: &state.Conversation{Ref: "feature", Commit: "0123456789abcdef"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warning, err := checkReviewedTree(tt.conv)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if (warning != "") != tt.wantWarning {
+				t.Errorf("warning = %q, wantWarning %v", warning, tt.wantWarning)
+			}
+		})
+	}
+
+	// 提交之后 HEAD 移动，工作区审查的记录不再适用
+	commitFile(t, "a.go", "package a\n\nvar x = 1\n")
+	if _, err := checkReviewedTree(workingTree); err == nil {
+		t.Error("HEAD 移动后应拒绝生成补丁")
+	}
+}
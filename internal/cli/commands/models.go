@@ -0,0 +1,150 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"ai_code_reviewer/internal/catalog"
+	"ai_code_reviewer/internal/cli/progress"
+	"ai_code_reviewer/internal/config"
+	"ai_code_reviewer/internal/provider"
+	"ai_code_reviewer/internal/usage"
+
+	"github.com/spf13/cobra"
+)
+
+// modelsTimeout 获取模型列表的超时时间
+const modelsTimeout = 15 * time.Second
+
+func CreateModelsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "models",
+		Short: "查看服务端可用的模型",
+	}
+
+	var fromCatalog bool
+	list := &cobra.Command{
+		Use:     "list",
+		Short:   "列出服务端可用的模型及上下文窗口和价格",
+		Args:    cobra.NoArgs,
+		Example: "  # 列出当前配置的服务可用的模型\n  models list\n\n  # 只查看内置目录，不访问服务\n  models list --catalog",
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := handleModelsList(fromCatalog); err != nil {
+				os.Exit(1)
+			}
+		},
+	}
+	list.Flags().BoolVar(&fromCatalog, "catalog", false, "只列出内置目录中的模型，不访问服务")
+	cmd.AddCommand(list)
+
+	return cmd
+}
+
+func handleModelsList(fromCatalog bool) error {
+	progressTracker := progress.NewSimpleProgress("")
+
+	cfg, err := config.LoadConfig(config.DefaultConfigFile)
+	if err != nil {
+		progressTracker.Error(fmt.Sprintf("获取配置失败：%v", err))
+		return err
+	}
+
+	var models []provider.RemoteModel
+	if fromCatalog {
+		for _, m := range catalog.Models {
+			models = append(models, provider.RemoteModel{ID: m.ID})
+		}
+	} else {
+		progressTracker.Show("获取模型列表...")
+		models, err = fetchModels(cfg)
+		if err != nil {
+			progressTracker.Error(err.Error())
+			return err
+		}
+		progressTracker.Success(fmt.Sprintf("共 %d 个模型", len(models)))
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "模型\t上下文\t最大输出\t输入\t缓存输入\t输出\t说明")
+	for _, m := range models {
+		window, output := "-", "-"
+		if info, ok := catalog.Lookup(m.ID); ok {
+			window = formatTokens(info.ContextWindow)
+			if info.MaxOutput > 0 {
+				output = formatTokens(info.MaxOutput)
+			}
+		}
+		input, cached, out := "-", "-", "-"
+		if price, ok := usage.LookupPrice(m.ID, cfg.Prices); ok {
+			input, out = formatPrice(price.Input), formatPrice(price.Output)
+			if price.CachedInput > 0 {
+				cached = formatPrice(price.CachedInput)
+			}
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", m.ID, window, output, input, cached, out, modelNote(m, cfg))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	fmt.Println("\n价格单位为美元/百万 token，上下文和价格来自内置目录，可在配置文件 prices 中补充价格")
+	return nil
+}
+
+// fetchModels 调用配置的服务列出模型
+func fetchModels(cfg *config.Config) ([]provider.RemoteModel, error) {
+	primary := *cfg
+	primary.Fallback = ""
+	prov, err := provider.New(&primary)
+	if err != nil {
+		return nil, fmt.Errorf("初始化模型服务失败：%w", err)
+	}
+	lister, ok := prov.(provider.ModelLister)
+	if !ok {
+		return nil, fmt.Errorf("%s 服务提供方不支持列出模型，可使用 --catalog 查看内置目录", prov.Name())
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), modelsTimeout)
+	defer cancel()
+	models, err := lister.ListModels(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取模型列表失败：%w", err)
+	}
+	return models, nil
+}
+
+// modelNote 说明列：当前使用的模型、所属组织或本地模型的大小
+func modelNote(m provider.RemoteModel, cfg *config.Config) string {
+	var notes []string
+	if strings.EqualFold(m.ID, cfg.Model) || strings.EqualFold(m.ID, cfg.Model+":latest") {
+		notes = append(notes, "当前")
+	}
+	if m.Size > 0 {
+		notes = append(notes, fmt.Sprintf("%.1f GB", float64(m.Size)/1e9))
+	}
+	if m.Details != "" {
+		notes = append(notes, m.Details)
+	}
+	if m.OwnedBy != "" {
+		notes = append(notes, m.OwnedBy)
+	}
+	return strings.Join(notes, "，")
+}
+
+// formatTokens 以 K/M 为单位显示 token 数
+func formatTokens(n int) string {
+	switch {
+	case n >= 1000000:
+		return fmt.Sprintf("%.2gM", float64(n)/1000000)
+	case n >= 1000:
+		return fmt.Sprintf("%dK", n/1000)
+	default:
+		return fmt.Sprint(n)
+	}
+}
+
+func formatPrice(p float64) string {
+	return fmt.Sprintf("%g", p)
+}
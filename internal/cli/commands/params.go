@@ -0,0 +1,51 @@
+package commands
+
+import (
+	"strings"
+
+	"ai_code_reviewer/internal/config"
+
+	"github.com/spf13/cobra"
+)
+
+// paramFlags 覆盖配置中模型参数的命令行标志
+type paramFlags struct {
+	temperature     float64
+	topP            float64
+	maxOutputTokens int
+	seed            int64
+	stop            []string
+	reasoningEffort string
+}
+
+// register 注册模型参数标志
+func (f *paramFlags) register(cmd *cobra.Command) {
+	cmd.Flags().Float64Var(&f.temperature, "temperature", 0, "采样温度（0-2），越低结果越稳定")
+	cmd.Flags().Float64Var(&f.topP, "top-p", 0, "核采样概率（0-1）")
+	cmd.Flags().IntVar(&f.maxOutputTokens, "max-output-tokens", 0, "单次回复的输出 token 上限")
+	cmd.Flags().Int64Var(&f.seed, "seed", 0, "随机种子，配合较低的 temperature 使结果可复现")
+	cmd.Flags().StringArrayVar(&f.stop, "stop", nil, "停止序列，可多次使用")
+	cmd.Flags().StringVar(&f.reasoningEffort, "reasoning-effort", "", "推理模型的推理强度: "+strings.Join(config.ReasoningEfforts, "，"))
+}
+
+// apply 用命令行中显式指定的标志覆盖配置中的模型参数，取值在创建服务提供方时校验
+func (f *paramFlags) apply(cmd *cobra.Command, p *config.ModelParams) {
+	if cmd.Flags().Changed("temperature") {
+		p.Temperature = &f.temperature
+	}
+	if cmd.Flags().Changed("top-p") {
+		p.TopP = &f.topP
+	}
+	if cmd.Flags().Changed("max-output-tokens") {
+		p.MaxOutputTokens = f.maxOutputTokens
+	}
+	if cmd.Flags().Changed("seed") {
+		p.Seed = &f.seed
+	}
+	if cmd.Flags().Changed("stop") {
+		p.Stop = f.stop
+	}
+	if cmd.Flags().Changed("reasoning-effort") {
+		p.ReasoningEffort = f.reasoningEffort
+	}
+}
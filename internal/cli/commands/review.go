@@ -1,21 +1,50 @@
 package commands
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
+	"ai_code_reviewer/internal/agent"
+	"ai_code_reviewer/internal/cache"
 	"ai_code_reviewer/internal/cli/progress"
 	"ai_code_reviewer/internal/cli/renderer"
 	"ai_code_reviewer/internal/config"
+	"ai_code_reviewer/internal/finding"
+	"ai_code_reviewer/internal/focus"
 	"ai_code_reviewer/internal/gitutil"
-	"ai_code_reviewer/internal/openaiutil"
+	"ai_code_reviewer/internal/lint"
+	"ai_code_reviewer/internal/provider"
+	"ai_code_reviewer/internal/redact"
+	"ai_code_reviewer/internal/repoctx"
+	"ai_code_reviewer/internal/review"
+	"ai_code_reviewer/internal/state"
+	"ai_code_reviewer/internal/suppress"
+	"ai_code_reviewer/internal/verify"
 
 	"github.com/spf13/cobra"
 )
 
 type ReviewOptions struct {
-	SourceRef string
-	TargetRef string
+	SourceRef     string
+	TargetRef     string
+	NoCache       bool
+	Incremental   bool
+	DryRun        bool
+	Tools         bool
+	Focus         []string
+	Format        string
+	Lint          bool
+	LintReports   []string
+	NoBaseline    bool
+	Models        []string
+	MinAgreement  int
+	Compare       bool // 由 acr compare 设置：对比各模型的结果而不合并
+	Verify        bool
+	MinConfidence float64
+	Params        paramFlags // 覆盖配置的模型参数
 }
 
 func CreateReviewCommand() *cobra.Command {
@@ -25,12 +54,26 @@ func CreateReviewCommand() *cobra.Command {
 		Use:     "review [args] |",
 		Short:   "发送diff给AI审查",
 		Args:    cobra.MaximumNArgs(2), // 允许 0-2 个位置参数
-		Example: "  # 标志参数用法\n  review --source master --target dev\n\n  # 位置参数用法\n  review master dev\n\n  # 混合用法\n  review master --target dev",
+		Example: "  # 标志参数用法\n  review --source master --target dev\n\n  # 位置参数用法\n  review master dev\n\n  # 混合用法\n  review master --target dev\n\n  # 忽略缓存重新审查\n  review --no-cache\n\n  # 只审查分支上次审查之后的新提交\n  review main --incremental\n\n  # 预估用量并查看请求内容\n  review --dry-run\n\n  # 允许模型按需读取仓库文件\n  review main --tools\n\n  # 分别进行安全和性能专项审查\n  review main --focus security,perf\n\n  # 输出 JSON 格式的结构化结果\n  review main --focus general --format json\n\n  # 执行配置的静态分析工具并让模型解读结果\n  review --lint\n\n  # 导入已有的 linter 报告\n  review main --lint-report golangci:lint.json\n\n  # 三个模型同时审查，只保留至少两个模型认同的发现\n  review main --models gpt-4o,claude-sonnet,local-qwen --min-agreement 2\n\n  # 逐条核实发现，只保留置信度不低于 0.7 的发现\n  review main --focus security --verify --min-confidence 0.7\n\n  # 固定温度和随机种子，使多次审查的结果尽量一致\n  review main --temperature 0 --seed 42",
 		Run:     runReview(opts),
 	}
 
 	cmd.Flags().StringVarP(&opts.SourceRef, "source", "s", "", "源分支")
 	cmd.Flags().StringVarP(&opts.TargetRef, "target", "t", "", "目标分支")
+	cmd.Flags().BoolVar(&opts.NoCache, "no-cache", false, "不使用缓存，重新审查所有文件")
+	cmd.Flags().BoolVar(&opts.Incremental, "incremental", false, "只审查分支上次审查之后的新提交，需要指定被审查的分支")
+	cmd.Flags().BoolVar(&opts.DryRun, "dry-run", false, "只估算 token 用量并输出请求内容，不调用模型")
+	cmd.Flags().BoolVar(&opts.Tools, "tools", false, "允许模型调用工具读取仓库文件（read_file、grep、list_dir、git_log）")
+	cmd.Flags().StringSliceVar(&opts.Focus, "focus", nil, "专项审查维度，逗号分隔，如 security,perf,concurrency,tests,general 或配置中自定义的维度")
+	cmd.Flags().StringVar(&opts.Format, "format", "markdown", "输出格式: markdown，json")
+	cmd.Flags().BoolVar(&opts.Lint, "lint", false, "执行配置的静态分析工具，将变更行上的诊断附加到审查请求中")
+	cmd.Flags().BoolVar(&opts.NoBaseline, "no-baseline", false, "不使用 .acr-baseline.json 过滤已知发现")
+	cmd.Flags().StringSliceVar(&opts.Models, "models", nil, "同时使用多个模型审查并合并结果，逗号分隔，可使用模型 ID 或 profiles 中的档案名")
+	cmd.Flags().IntVar(&opts.MinAgreement, "min-agreement", 1, "多模型审查时只保留至少 N 个模型给出的发现")
+	cmd.Flags().BoolVar(&opts.Verify, "verify", false, "审查后让模型逐条核实发现，移除置信度低的误报")
+	cmd.Flags().Float64Var(&opts.MinConfidence, "min-confidence", 0, "核实后保留发现的最低置信度（0-1），默认使用配置中的 min_confidence")
+	cmd.Flags().StringArrayVar(&opts.LintReports, "lint-report", nil, "导入 linter 输出文件，格式为 格式:路径，可多次使用; 格式: "+strings.Join(lint.Formats(), "，"))
+	opts.Params.register(cmd)
 
 	return cmd
 }
@@ -60,6 +103,61 @@ func runReview(opts *ReviewOptions) func(*cobra.Command, []string) {
 			os.Exit(1)
 		}
 		progressTracker.Success("配置加载完成")
+		opts.Params.apply(cmd, &cfg.Params)
+
+		if opts.Format != "markdown" && opts.Format != "json" {
+			progressTracker.Error(fmt.Sprintf("无效的输出格式: %s，可选: markdown，json", opts.Format))
+			os.Exit(1)
+		}
+		// 在发出审查请求之前校验，避免审查完成后才因参数错误退出
+		if opts.MinConfidence < 0 || opts.MinConfidence > 1 {
+			progressTracker.Error(fmt.Sprintf("无效的 --min-confidence: %g，应为 0 到 1 之间的小数", opts.MinConfidence))
+			os.Exit(1)
+		}
+		if cmd.Flags().Changed("min-agreement") && len(opts.Models) == 0 {
+			progressTracker.Error("--min-agreement 需要与 --models 一起使用")
+			os.Exit(1)
+		}
+		if len(opts.Models) > 0 && (opts.MinAgreement < 1 || opts.MinAgreement > len(opts.Models)) {
+			progressTracker.Error(fmt.Sprintf("无效的 --min-agreement: %d，应在 1 到 %d（--models 中的模型数）之间", opts.MinAgreement, len(opts.Models)))
+			os.Exit(1)
+		}
+		passes, err := resolvePasses(cfg, opts.Focus)
+		if err != nil {
+			progressTracker.Error(err.Error())
+			os.Exit(1)
+		}
+
+		// 审查分支时记录审查状态，供之后的增量审查使用；工作区的未提交变更没有对应的提交，不记录
+		var store *state.Store
+		var branch, commit string
+		var previous state.BranchState
+		if !isWorkingTree(opts.SourceRef, opts.TargetRef) {
+			store, branch, commit, err = loadReviewState(opts.SourceRef)
+			if err != nil {
+				progressTracker.Error(fmt.Sprintf("读取审查状态失败: %v", err))
+				os.Exit(1)
+			}
+		}
+
+		if opts.Incremental {
+			plan, err := planIncremental(store, branch, commit, opts.SourceRef, opts.TargetRef)
+			if err != nil {
+				progressTracker.Error(err.Error())
+				os.Exit(1)
+			}
+			switch {
+			case !plan.found:
+				progressTracker.Info(fmt.Sprintf("分支 %s 没有审查记录，执行完整审查", branch))
+			case plan.upToDate:
+				progressTracker.Info(fmt.Sprintf("分支 %s 自上次审查以来没有新提交", branch))
+				return
+			default:
+				progressTracker.Info(fmt.Sprintf("增量审查 %s: %s..%s", branch, shortSHA(plan.previous.Commit), shortSHA(commit)))
+				previous = plan.previous
+				opts.SourceRef, opts.TargetRef = plan.sourceRef, plan.targetRef
+			}
+		}
 
 		// 获取Git diff
 		progressTracker.Show("获取Git差异...")
@@ -74,19 +172,368 @@ func runReview(opts *ReviewOptions) func(*cobra.Command, []string) {
 		}
 		progressTracker.Success("Git差异获取完成")
 
-		// 发送给AI审查
-		progressTracker.Show("发送给AI进行代码审查...")
-		spinner := progress.NewSpinner("AI正在分析代码")
-		spinner.Start()
+		// 打开缓存
+		var resultCache *cache.Cache
+		if !opts.NoCache {
+			resultCache, err = review.OpenCache(cfg)
+			if err != nil {
+				progressTracker.Error(fmt.Sprintf("打开缓存失败: %v", err))
+				os.Exit(1)
+			}
+		}
 
-		result, err := openaiutil.Chart(cfg.Token, cfg.Prompt, diff, cfg.Model, cfg.Url)
-		spinner.Stop()
+		// 按文件生成审查计划，命中缓存的文件不再发送
+		files := gitutil.SplitDiff(diff)
 
-		if err != nil {
-			progressTracker.Error(fmt.Sprintf("代码审查失败: %v", err))
+		// 收集仓库上下文（所在函数、引用的定义、相关测试等）
+		var contexts map[string]string
+		if cfg.ContextMode != repoctx.ModeOff {
+			progressTracker.Show("收集仓库上下文...")
+			contexts, err = buildContexts(cfg, files, opts.SourceRef)
+			if err != nil {
+				progressTracker.Error(fmt.Sprintf("收集仓库上下文失败: %v", err))
+				os.Exit(1)
+			}
+		}
+
+		// 收集静态分析结果，只保留变更行上的诊断，随上下文一起发送
+		var diagnostics map[string][]lint.Diagnostic
+		runLinters := opts.Lint || cfg.Lint == "on"
+		if runLinters || len(opts.LintReports) > 0 {
+			progressTracker.Show("收集静态分析结果...")
+			if runLinters && !isWorkingTree(opts.SourceRef, opts.TargetRef) {
+				renderer.RenderWarning("静态分析工具在当前工作区执行，请确认已检出被审查的分支，否则行号可能对不上")
+			}
+			diagnostics, err = collectDiagnostics(cfg, files, runLinters, opts.LintReports, renderer)
+			if err != nil {
+				progressTracker.Error(fmt.Sprintf("收集静态分析结果失败: %v", err))
+				os.Exit(1)
+			}
+			if contexts == nil {
+				contexts = make(map[string]string)
+			}
+			count := 0
+			for path, list := range diagnostics {
+				count += len(list)
+				section := lint.Format(list)
+				if contexts[path] != "" {
+					section = contexts[path] + "\n\n" + section
+				}
+				contexts[path] = section
+			}
+			progressTracker.Info(fmt.Sprintf("变更行上共有 %d 条静态分析诊断", count))
+		}
+
+		// 发送前检测并替换敏感信息
+		files, redactions, refused := redact.Apply(cfg.RedactMode, files)
+		for path, ctx := range contexts {
+			var found []redact.Redaction
+			var err error
+			contexts[path], found, err = redact.ApplyText(cfg.RedactMode, path+" (上下文)", ctx)
+			redactions = append(redactions, found...)
+			if err != nil {
+				refused = err
+			}
+		}
+		if refused != nil {
+			progressTracker.Error(fmt.Sprintf("检测到 %d 处敏感信息，已拒绝发送（redact_mode=refuse）：\n%s", len(redactions), formatRedactions(redactions)))
+			os.Exit(1)
+		}
+		if len(redactions) > 0 {
+			renderer.RenderWarning(fmt.Sprintf("检测到 %d 处敏感信息，已替换为占位符：\n%s", len(redactions), formatRedactions(redactions)))
+		}
+
+		// 每个模型独立审查；指定多个模型时并发执行，再按位置和相似度合并发现
+		cfgs := []*config.Config{cfg}
+		if len(opts.Models) > 0 {
+			cfgs = cfgs[:0]
+			for _, name := range opts.Models {
+				cfgs = append(cfgs, cfg.ForModel(name))
+			}
+		}
+		if opts.Compare && len(cfgs) < 2 {
+			progressTracker.Error("请通过 --models 指定至少两个模型")
 			os.Exit(1)
 		}
+		verifyOn := (opts.Verify || cfg.Verify == "on") && !opts.Compare
+		lintOn := runLinters || len(opts.LintReports) > 0
+		if (len(cfgs) > 1 || verifyOn || lintOn) && len(passes) == 0 {
+			// 合并多个模型的结果、核实发现以及将诊断合并到模型的发现上都需要结构化的发现
+			passes, _ = focus.Resolve([]string{focus.General}, nil, cfg.Prompt)
+		}
+
+		var runs []*modelRun
+		for i, c := range cfgs {
+			label := c.Model
+			if len(opts.Models) > 0 {
+				label = opts.Models[i]
+			}
+			if opts.Compare {
+				// 对比时每个模型的结果必须来自该模型本身
+				c.Fallback = ""
+			}
+			prov, err := provider.New(c)
+			if err != nil {
+				progressTracker.Error(fmt.Sprintf("初始化模型服务失败（%s）：%v", label, err))
+				os.Exit(1)
+			}
+			reviewer := review.NewReviewer(c, prov, resultCache)
+			if opts.Tools || cfg.Tools == "on" {
+				reviewer.SetToolbox(agent.NewToolbox(opts.SourceRef, cfg.RedactMode), cfg.MaxToolIterations)
+			}
+			reviewer.SetPasses(passes)
+			reviewer.SetPrevious(previous.Findings)
+			reviewer.SetContexts(contexts)
+			runs = append(runs, &modelRun{
+				label:    label,
+				cfg:      c,
+				provider: prov,
+				reviewer: reviewer,
+				plan:     reviewer.Prepare(files),
+			})
+		}
+
+		// 发送前估算用量并检查预算
+		var est review.Estimate
+		est.Priced = true
+		for _, run := range runs {
+			e, err := review.EstimatePlan(run.plan, run.cfg)
+			if err != nil {
+				progressTracker.Error(fmt.Sprintf("估算 token 失败: %v", err))
+				os.Exit(1)
+			}
+			est.Requests += e.Requests
+			est.InputTokens += e.InputTokens
+			est.InputCost += e.InputCost
+			est.Priced = est.Priced && e.Priced
+		}
+		progressTracker.Info(est.String())
+		if verifyOn {
+			progressTracker.Info("核实请求数取决于发现的数量，未计入预估")
+		}
+
+		violations := review.CheckBudget(est, cfg)
+		if opts.DryRun {
+			// 预演时同样检查预算，只输出结果，不取消
+			if msg, exceeded := describeBudget(violations, cfg); exceeded {
+				renderer.RenderWarning(msg)
+			} else if msg != "" {
+				progressTracker.Info(msg)
+			}
+			if est.Requests == 0 {
+				progressTracker.Info("所有文件均命中缓存，无需发送请求")
+				return
+			}
+			for _, run := range runs {
+				if len(runs) > 1 {
+					renderer.RenderPlain(fmt.Sprintf("##### 模型: %s #####\n", run.label))
+				}
+				renderer.RenderPlain(review.FormatPayload(run.plan))
+			}
+			return
+		}
+
+		if len(violations) > 0 {
+			msg := strings.Join(violations, "；")
+			if cfg.BudgetAction == "warn" {
+				renderer.RenderWarning(msg)
+			} else {
+				progressTracker.Error(msg + "，已取消审查（可调整预算或设置 budget_action=warn）")
+				os.Exit(1)
+			}
+		}
+
+		switch {
+		case len(runs) > 1:
+			progressTracker.Show(fmt.Sprintf("发送给AI进行代码审查（共 %d 个文件，%d 个审查维度，%d 个模型）...", len(files), len(passes), len(runs)))
+		case len(passes) > 0:
+			progressTracker.Show(fmt.Sprintf("发送给AI进行代码审查（共 %d 个文件，%d 个审查维度）...", len(files), len(passes)))
+		default:
+			progressTracker.Show(fmt.Sprintf("发送给AI进行代码审查（共 %d 个文件）...", len(files)))
+		}
+
+		executeRuns(context.Background(), runs)
+		for _, run := range runs {
+			if summary := failoverSummary(run.provider); summary != "" {
+				if len(runs) > 1 {
+					summary = fmt.Sprintf("[%s] %s", run.label, summary)
+				}
+				renderer.RenderWarning(summary)
+			}
+		}
+
+		// 失败前已发出的请求同样计入用量
+		var summaries []string
+		for _, run := range runs {
+			used, requests := run.reviewer.Usage()
+			if requests == 0 {
+				continue
+			}
+			summary, recordErr := recordProviderUsage("review", run.provider, run.cfg, used, requests)
+			if recordErr != nil {
+				renderer.RenderWarning(fmt.Sprintf("记录用量失败: %v", recordErr))
+			}
+			if len(runs) > 1 {
+				summary = fmt.Sprintf("[%s] %s", run.label, summary)
+			}
+			summaries = append(summaries, summary)
+		}
+		usageSummary := strings.Join(summaries, "\n")
+
+		var succeeded []*modelRun
+		for _, run := range runs {
+			if run.err != nil {
+				if len(runs) > 1 {
+					renderer.RenderWarning(fmt.Sprintf("模型 %s 审查失败: %v", run.label, run.err))
+				} else {
+					progressTracker.Error(fmt.Sprintf("代码审查失败: %v", run.err))
+				}
+				continue
+			}
+			succeeded = append(succeeded, run)
+		}
+		if len(succeeded) == 0 {
+			if len(runs) > 1 {
+				progressTracker.Error("所有模型均审查失败")
+			}
+			if usageSummary != "" {
+				progressTracker.Info(usageSummary)
+			}
+			os.Exit(1)
+		}
+
+		var results []review.FileResult
+		cached, calls := 0, 0
+		for _, run := range succeeded {
+			cached += review.CountCached(run.results)
+			calls += run.reviewer.ToolCalls()
+		}
+		if len(runs) == 1 {
+			results = runs[0].results
+		} else {
+			labels := make([]string, len(succeeded))
+			runResults := make([][]review.FileResult, len(succeeded))
+			for i, run := range succeeded {
+				labels[i] = run.label
+				runResults[i] = run.results
+			}
+			if opts.Compare {
+				if err := renderer.RenderMarkdown(formatComparison(succeeded, review.Consensus(labels, runResults, 1))); err != nil {
+					progressTracker.Error(fmt.Sprintf("输出结果失败: %v", err))
+					os.Exit(1)
+				}
+				if usageSummary != "" {
+					progressTracker.Info(usageSummary)
+				}
+				return
+			}
+			results = review.Consensus(labels, runResults, opts.MinAgreement)
+		}
+		if cached > 0 {
+			progressTracker.Info(fmt.Sprintf("%d 个文件命中缓存", cached))
+		}
+		if calls > 0 {
+			progressTracker.Info(fmt.Sprintf("模型共调用工具 %d 次", calls))
+		}
 		progressTracker.Success("AI代码审查完成")
+		// 结构化审查时，将模型没有覆盖的诊断补充到结果中
+		if len(passes) > 0 && len(diagnostics) > 0 {
+			results = review.AttachDiagnostics(results, diagnostics)
+		}
+
+		// 过滤 acr:ignore 标注的发现和基线中的已知发现
+		var allFindings []finding.Finding
+		if len(passes) > 0 {
+			var baseline *suppress.Baseline
+			if !opts.NoBaseline {
+				baseline, err = suppress.LoadBaseline()
+				if err != nil {
+					renderer.RenderWarning(err.Error())
+				}
+			}
+			sup := suppress.New(opts.SourceRef, baseline)
+			ignored, baselined := 0, 0
+			review.FilterFindings(results, func(f *finding.Finding) bool {
+				if sup.Ignored(*f) {
+					ignored++
+					return false
+				}
+				f.Fingerprint = sup.Fingerprint(*f)
+				allFindings = append(allFindings, *f)
+				if sup.Baselined(f.Fingerprint) {
+					baselined++
+					return false
+				}
+				return true
+			})
+			if ignored > 0 || baselined > 0 {
+				progressTracker.Info(fmt.Sprintf("已忽略 %d 条 acr:ignore 标注的发现，%d 条基线中的发现", ignored, baselined))
+			}
+		}
+
+		// 逐条核实模型给出的发现，移除置信度低于 min_confidence 的发现
+		var dropped []finding.Finding
+		if verifyOn {
+			dropped, err = verifyFindings(cfg, resultCache, opts, results, files, progressTracker, renderer)
+			if err != nil {
+				// 审查请求已经完成，核实失败时仍然输出未核实的结果
+				renderer.RenderWarning(fmt.Sprintf("核实发现失败，输出未核实的审查结果：%v", err))
+			}
+		}
+		result := review.Render(results)
+
+		// 保存本次审查对话，供 acr chat 继续追问，并记录被审查的提交供 acr fix 核对
+		conversation := &state.Conversation{
+			Model:     cfg.Model,
+			CreatedAt: time.Now(),
+			Messages: []provider.Message{
+				{Role: provider.RoleSystem, Content: cfg.Prompt},
+				{Role: provider.RoleUser, Content: joinFileDiffs(files)},
+				{Role: provider.RoleAssistant, Content: result},
+			},
+			Findings: allFindings,
+			Ref:      opts.SourceRef,
+		}
+		conversation.Commit, _ = gitutil.RevParse(opts.SourceRef)
+		if isWorkingTree(opts.SourceRef, opts.TargetRef) {
+			conversation.WorkingTree = true
+			conversation.DiffDigest = diffDigest(diff)
+		}
+		if err := state.SaveConversation(conversation); err != nil {
+			renderer.RenderWarning(fmt.Sprintf("保存审查对话失败: %v", err))
+		}
+
+		if store != nil {
+			// 增量审查只覆盖本次涉及的文件，其余文件沿用上次的结论
+			findings := review.Findings(results)
+			for path, content := range previous.Findings {
+				if _, ok := findings[path]; !ok {
+					findings[path] = content
+				}
+			}
+			store.Set(branch, state.BranchState{Commit: commit, ReviewedAt: time.Now(), Findings: findings})
+			if err := store.Save(); err != nil {
+				renderer.RenderWarning(fmt.Sprintf("记录审查状态失败: %v", err))
+			}
+		}
+
+		if opts.Format == "json" {
+			report := review.NewReport(results)
+			report.Dropped = dropped
+			for _, run := range succeeded {
+				report.Models = append(report.Models, modelInfos(run.provider, run.cfg)...)
+			}
+			out, err := report.JSON()
+			if err != nil {
+				progressTracker.Error(fmt.Sprintf("输出结果失败: %v", err))
+				os.Exit(1)
+			}
+			fmt.Println(out)
+			if usageSummary != "" {
+				progressTracker.Info(usageSummary)
+			}
+			return
+		}
 
 		// 渲染结果
 		progressTracker.Show("渲染审查结果...")
@@ -95,5 +542,245 @@ func runReview(opts *ReviewOptions) func(*cobra.Command, []string) {
 			os.Exit(1)
 		}
 		progressTracker.Success("审查结果渲染完成")
+		if usageSummary != "" {
+			progressTracker.Info(usageSummary)
+		}
+	}
+}
+
+// verifyFindings 让模型核实结果中模型给出的发现，返回因置信度过低被移除的发现
+func verifyFindings(cfg *config.Config, resultCache *cache.Cache, opts *ReviewOptions, results []review.FileResult, files []gitutil.FileDiff, progressTracker *progress.SimpleProgress, renderer *renderer.Renderer) ([]finding.Finding, error) {
+	minConfidence := cfg.MinConfidence
+	if opts.MinConfidence > 0 {
+		minConfidence = opts.MinConfidence
+	}
+
+	targets := review.ModelFindings(results)
+	if len(targets) == 0 {
+		return nil, nil
+	}
+	prov, err := provider.New(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("初始化模型服务失败：%v", err)
+	}
+
+	progressTracker.Show(fmt.Sprintf("核实 %d 条发现...", len(targets)))
+	verifier := verify.New(cfg, prov, resultCache, opts.SourceRef)
+	var bar *progress.ProgressBar
+	failures := verifier.Verify(context.Background(), targets, files, func(done, total int) {
+		if bar == nil {
+			bar = progress.NewProgressBar(total, "AI正在核实发现")
+		}
+		bar.Update(done)
+	})
+	if bar != nil {
+		bar.Finish()
+	}
+	if used, requests := verifier.Usage(); requests > 0 {
+		summary, recordErr := recordProviderUsage("verify", prov, cfg, used, requests)
+		if recordErr != nil {
+			renderer.RenderWarning(fmt.Sprintf("记录用量失败: %v", recordErr))
+		}
+		progressTracker.Info(summary)
+	}
+	// 核实失败的文件保留原有发现，不影响其他文件
+	for _, f := range failures {
+		renderer.RenderWarning(fmt.Sprintf("核实 %s 失败，其中 %d 条发现保持未核实：%v", f.Path, f.Findings, f.Err))
+	}
+
+	var dropped []finding.Finding
+	review.FilterFindings(results, func(f *finding.Finding) bool {
+		if !verify.Keep(*f, minConfidence) {
+			dropped = append(dropped, *f)
+			return false
+		}
+		return true
+	})
+	if len(dropped) > 0 {
+		progressTracker.Info(fmt.Sprintf("核实后移除 %d 条误报或置信度低于 %g 的发现", len(dropped), minConfidence))
+	}
+	return dropped, nil
+}
+
+// resolvePasses 解析专项审查维度，未通过 --focus 指定时使用配置中的 focus，
+// 自定义维度来自用户配置和仓库根目录的 .acr.yaml
+func resolvePasses(cfg *config.Config, names []string) ([]focus.Pass, error) {
+	if len(names) == 0 && cfg.Focus != "" {
+		names = strings.Split(cfg.Focus, ",")
+	}
+	if len(names) == 0 {
+		return nil, nil
+	}
+	project, err := config.LoadProjectConfig()
+	if err != nil {
+		return nil, err
+	}
+	return focus.Resolve(names, config.MergePasses(cfg, project), cfg.Prompt)
+}
+
+// isWorkingTree 未指定分支时审查的是工作区的未提交变更
+func isWorkingTree(sourceRef, targetRef string) bool {
+	return (sourceRef == "" || sourceRef == ".") && (targetRef == "" || targetRef == ".")
+}
+
+// incrementalPlan 增量审查的范围
+type incrementalPlan struct {
+	previous  state.BranchState
+	found     bool // 分支有审查记录
+	upToDate  bool // 自上次审查以来没有新提交
+	sourceRef string
+	targetRef string
+}
+
+// planIncremental 根据分支上次的审查记录确定增量审查的范围。
+// 工作区的未提交变更不对应任何提交，无法判断哪些已经审查过，因此要求指定被审查的分支
+func planIncremental(store *state.Store, branch, commit, sourceRef, targetRef string) (incrementalPlan, error) {
+	if isWorkingTree(sourceRef, targetRef) {
+		return incrementalPlan{}, fmt.Errorf("--incremental 只适用于已提交的分支，请指定被审查的分支（如 review main --incremental）；审查工作区的未提交变更时请去掉 --incremental")
+	}
+	prev, ok := store.Get(branch)
+	switch {
+	case !ok:
+		return incrementalPlan{}, nil
+	case prev.Commit == commit:
+		return incrementalPlan{previous: prev, found: true, upToDate: true}, nil
+	default:
+		return incrementalPlan{previous: prev, found: true, sourceRef: commit, targetRef: prev.Commit}, nil
+	}
+}
+
+// loadReviewState 读取审查状态，并解析被审查的分支名及其当前提交
+func loadReviewState(sourceRef string) (*state.Store, string, string, error) {
+	store, err := state.Load()
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	branch := sourceRef
+	if branch == "" || branch == "." {
+		branch, err = gitutil.CurrentBranch()
+		if err != nil {
+			return nil, "", "", err
+		}
+	}
+
+	commit, err := gitutil.RevParse(sourceRef)
+	if err != nil {
+		return nil, "", "", err
+	}
+	return store, branch, commit, nil
+}
+
+// buildContexts 为每个变更文件收集仓库上下文，sourceRef 为空时读取工作区文件
+func buildContexts(cfg *config.Config, files []gitutil.FileDiff, sourceRef string) (map[string]string, error) {
+	builder := repoctx.NewBuilder(cfg.ContextMode, cfg.ContextLines, cfg.ContextMaxTokens, cfg.Model, sourceRef)
+	contexts := make(map[string]string, len(files))
+	for _, f := range files {
+		ctx, err := builder.Build(f)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.Path, err)
+		}
+		if ctx != "" {
+			contexts[f.Path] = ctx
+		}
+	}
+	return contexts, nil
+}
+
+// collectDiagnostics 执行配置的静态分析工具并导入报告文件，返回变更行上的诊断。
+// 单个工具执行失败只输出警告，不影响审查
+func collectDiagnostics(cfg *config.Config, files []gitutil.FileDiff, run bool, reports []string, r *renderer.Renderer) (map[string][]lint.Diagnostic, error) {
+	top, err := gitutil.TopLevel()
+	if err != nil {
+		return nil, err
+	}
+
+	var all []lint.Diagnostic
+	if run {
+		project, err := config.LoadProjectConfig()
+		if err != nil {
+			return nil, err
+		}
+		linters, missing := config.SelectLinters(cfg, project)
+		for _, name := range missing {
+			r.RenderWarning(fmt.Sprintf("%s 要求执行的 linter %s 未在 ~/%s 中配置，已跳过", config.ProjectConfigFile, name, config.DefaultConfigFile))
+		}
+		if len(linters) == 0 && len(missing) == 0 {
+			r.RenderWarning(fmt.Sprintf("未配置 linters，请在 ~/%s 中添加", config.DefaultConfigFile))
+		}
+		for _, l := range linters {
+			diags, err := lint.Run(l, top)
+			if err != nil {
+				r.RenderWarning(err.Error())
+				continue
+			}
+			all = append(all, diags...)
+		}
+	}
+
+	for _, report := range reports {
+		format, path, ok := strings.Cut(report, ":")
+		if !ok {
+			return nil, fmt.Errorf("无效的 --lint-report: %s，应为 格式:路径", report)
+		}
+		diags, err := lint.Load(format, path, format, top)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, diags...)
+	}
+
+	return lint.OnChangedLines(all, files), nil
+}
+
+// joinFileDiffs 将（脱敏后的）各文件 diff 重新拼接为完整 diff
+func joinFileDiffs(files []gitutil.FileDiff) string {
+	var b strings.Builder
+	for _, f := range files {
+		b.WriteString(f.Content)
+	}
+	return b.String()
+}
+
+// formatRedactions 每行一条输出脱敏记录
+func formatRedactions(redactions []redact.Redaction) string {
+	lines := make([]string, len(redactions))
+	for i, r := range redactions {
+		lines[i] = "  " + r.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// describeBudget 返回 --dry-run 时的预算检查结果，未设置预算时返回空字符串
+func describeBudget(violations []string, cfg *config.Config) (string, bool) {
+	if len(violations) > 0 {
+		msg := strings.Join(violations, "；")
+		if cfg.BudgetAction == "warn" {
+			return msg + "，实际审查时只给出警告（budget_action=warn）", true
+		}
+		return msg + "，实际审查时将被取消（可调整预算或设置 budget_action=warn）", true
+	}
+	var limits []string
+	if cfg.MaxInputTokens > 0 {
+		limits = append(limits, fmt.Sprintf("max_input_tokens=%d", cfg.MaxInputTokens))
+	}
+	if cfg.MaxCost > 0 {
+		limits = append(limits, fmt.Sprintf("max_cost=%g", cfg.MaxCost))
+	}
+	if len(limits) == 0 {
+		return "", false
+	}
+	return "预估未超出预算（" + strings.Join(limits, "，") + "）", false
+}
+
+// diffDigest 计算 diff 的摘要，用于判断工作区在审查之后是否有改动
+func diffDigest(diff string) string {
+	return cache.Key(diff)
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 8 {
+		return sha[:8]
 	}
+	return sha
 }
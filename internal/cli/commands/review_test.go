@@ -0,0 +1,143 @@
+package commands
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"ai_code_reviewer/internal/config"
+	"ai_code_reviewer/internal/gitutil"
+	"ai_code_reviewer/internal/state"
+)
+
+// gitRun 在当前目录执行 git 命令
+func gitRun(t *testing.T, args ...string) {
+	t.Helper()
+	args = append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)
+	if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+}
+
+// commitFile 写入文件并提交，返回新提交
+func commitFile(t *testing.T, name, content string) string {
+	t.Helper()
+	if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	gitRun(t, "add", name)
+	gitRun(t, "commit", "-q", "-m", name)
+	commit, err := gitutil.RevParse("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return commit
+}
+
+// useGitRepo 在临时目录中初始化 git 仓库，测试期间以其为工作目录
+func useGitRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+	gitRun(t, "init", "-q", "-b", "main")
+	return dir
+}
+
+func TestPlanIncremental(t *testing.T) {
+	dir := useGitRepo(t)
+	first := commitFile(t, "a.go", "package a\n")
+
+	// 审查工作区：不读取也不记录审查状态，--incremental 直接拒绝
+	if _, err := planIncremental(nil, "", "", "", ""); err == nil {
+		t.Fatal("未指定分支的增量审查应返回错误")
+	}
+
+	// 第一次审查分支：没有记录，执行完整审查并记录当前提交
+	store, branch, commit, err := loadReviewState("main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan, err := planIncremental(store, branch, commit, "main", "")
+	if err != nil || plan.found {
+		t.Fatalf("第一次审查 plan = %+v, err = %v", plan, err)
+	}
+	store.Set(branch, state.BranchState{Commit: commit, ReviewedAt: time.Now()})
+	if err := store.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 第二次审查：工作区有未提交的修改，但分支没有新提交
+	if err := os.WriteFile(filepath.Join(dir, "a.go"), []byte("package a\n\nvar x = 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := planIncremental(nil, "", "", ".", ""); err == nil {
+		t.Error("审查工作区的未提交修改时不应使用 --incremental")
+	}
+	store, branch, commit, err = loadReviewState("main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan, err = planIncremental(store, branch, commit, "main", "")
+	if err != nil || !plan.found || !plan.upToDate {
+		t.Fatalf("没有新提交时 plan = %+v, err = %v", plan, err)
+	}
+
+	// 提交之后只审查新提交
+	second := commitFile(t, "a.go", "package a\n\nvar x = 1\n")
+	store, branch, commit, err = loadReviewState("main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan, err = planIncremental(store, branch, commit, "main", "")
+	if err != nil || plan.upToDate || plan.sourceRef != second || plan.targetRef != first || plan.previous.Commit != first {
+		t.Errorf("有新提交时 plan = %+v, err = %v", plan, err)
+	}
+}
+
+func TestDescribeBudget(t *testing.T) {
+	tests := []struct {
+		name         string
+		cfg          config.Config
+		violations   []string
+		want         string
+		wantExceeded bool
+	}{
+		{name: "未设置预算", cfg: config.Config{BudgetAction: "abort"}},
+		{
+			name: "未超出预算",
+			cfg:  config.Config{MaxInputTokens: 50000, MaxCost: 0.5, BudgetAction: "abort"},
+			want: "预估未超出预算（max_input_tokens=50000，max_cost=0.5）",
+		},
+		{
+			name:         "超出预算时将取消",
+			cfg:          config.Config{MaxInputTokens: 100, BudgetAction: "abort"},
+			violations:   []string{"预估输入 120 tokens 超过 max_input_tokens=100"},
+			want:         "预估输入 120 tokens 超过 max_input_tokens=100，实际审查时将被取消（可调整预算或设置 budget_action=warn）",
+			wantExceeded: true,
+		},
+		{
+			name:         "超出预算时只警告",
+			cfg:          config.Config{MaxInputTokens: 100, MaxCost: 0.01, BudgetAction: "warn"},
+			violations:   []string{"a", "b"},
+			want:         "a；b，实际审查时只给出警告（budget_action=warn）",
+			wantExceeded: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, exceeded := describeBudget(tt.violations, &tt.cfg)
+			if got != tt.want || exceeded != tt.wantExceeded {
+				t.Errorf("describeBudget = %q, %v; want %q, %v", got, exceeded, tt.want, tt.wantExceeded)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"ai_code_reviewer/internal/cli/progress"
+	"ai_code_reviewer/internal/config"
+	"ai_code_reviewer/internal/usage"
+
+	"github.com/spf13/cobra"
+)
+
+type UsageOptions struct {
+	Since string
+	By    string
+}
+
+func CreateUsageCommand() *cobra.Command {
+	opts := &UsageOptions{}
+
+	cmd := &cobra.Command{
+		Use:     "usage",
+		Short:   "统计 token 用量和费用",
+		Args:    cobra.NoArgs,
+		Example: "  # 最近 30 天按模型统计\n  usage --since 30d --by model\n\n  # 按天统计\n  usage --since 2w --by day",
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := handleUsage(opts); err != nil {
+				os.Exit(1)
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Since, "since", "30d", "统计起始时间，如 30d、2w、12h、2024-01-01")
+	cmd.Flags().StringVar(&opts.By, "by", "model", "聚合维度: model，provider，command，day")
+
+	return cmd
+}
+
+func handleUsage(opts *UsageOptions) error {
+	progressTracker := progress.NewSimpleProgress("用量统计")
+
+	since, err := usage.ParseSince(opts.Since, time.Now())
+	if err != nil {
+		progressTracker.Error(err.Error())
+		return err
+	}
+
+	records, err := usage.ReadSince(config.HomePath(config.DefaultUsageFile), since)
+	if err != nil {
+		progressTracker.Error(err.Error())
+		return err
+	}
+	if len(records) == 0 {
+		progressTracker.Info("该时间范围内没有用量记录")
+		return nil
+	}
+
+	summaries, err := usage.Summarize(records, opts.By)
+	if err != nil {
+		progressTracker.Error(err.Error())
+		return err
+	}
+
+	var total usage.Summary
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(w, "%s\t请求数\t输入\t缓存\t输出\t费用(USD)\t\n", opts.By)
+	for _, s := range summaries {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%.4f\t\n", s.Key, s.Requests, s.PromptTokens, s.CachedTokens, s.CompletionTokens, s.Cost)
+		total.Requests += s.Requests
+		total.Usage.Add(s.Usage)
+		total.Cost += s.Cost
+	}
+	fmt.Fprintf(w, "合计\t%d\t%d\t%d\t%d\t%.4f\t\n", total.Requests, total.PromptTokens, total.CachedTokens, total.CompletionTokens, total.Cost)
+	return w.Flush()
+}
+
+// recordUsage 计算本次调用的费用并追加到用量账本，返回用于输出的汇总信息
+func recordUsage(command, providerName string, cfg *config.Config, u usage.Usage, requests int) (string, error) {
+	price, priced := usage.LookupPrice(cfg.Model, cfg.Prices)
+	cost := usage.Cost(u, price)
+
+	rec := usage.Record{
+		Time:     time.Now(),
+		Command:  command,
+		Provider: providerName,
+		Model:    cfg.Model,
+		Requests: requests,
+		Usage:    u,
+		Cost:     cost,
+	}
+	err := usage.Append(config.HomePath(config.DefaultUsageFile), rec)
+
+	summary := fmt.Sprintf("Token 用量：输入 %d（缓存 %d），输出 %d，共 %d 次请求", u.PromptTokens, u.CachedTokens, u.CompletionTokens, requests)
+	if priced {
+		summary += fmt.Sprintf("，费用约 $%.4f", cost)
+	} else {
+		summary += fmt.Sprintf("，未找到模型 %s 的价格，可在配置文件 prices 中设置", cfg.Model)
+	}
+	return summary, err
+}
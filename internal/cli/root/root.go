@@ -36,15 +36,39 @@ func (root *RootCommand) createRootCommand() *cobra.Command {
 
 主要功能：
   • review    - 发送diff给AI进行代码审查
+  • compare   - 使用多个模型审查并对比结果
+  • chat      - 就上次审查结果继续提问
+  • fix       - 根据上次审查结果生成并应用修复补丁
+  • commit    - 根据暂存区变更生成提交信息并提交
+  • describe  - 生成 PR 标题和描述
+  • changelog - 生成按提交类型分组的发布说明
   • diff      - 仅输出本地 git diff 内容
   • config    - 查看或设置配置文件
+  • baseline  - 记录已知发现的基线
+  • eval      - 使用标注好的用例评测审查质量
+  • cache     - 管理审查结果缓存
+  • usage     - 统计 token 用量和费用
+  • doctor    - 检查运行环境和配置
+  • models    - 列出服务端可用的模型
   • version   - 查看版本信息
 
 使用示例：
   acr review master dev          # 审查从master到dev的变更
+  acr compare main --models gpt-4o,claude-sonnet  # 对比两个模型的审查结果
+  acr chat                       # 就上次审查结果继续提问
+  acr fix --verify "go test ./..."  # 应用修复补丁，验证失败时回滚
+  acr commit                     # 生成提交信息并提交
+  acr describe feature main      # 生成 feature 相对 main 的 PR 描述
+  acr changelog --from v1.2 --to v1.3  # 生成 v1.3 的发布说明
   acr diff --source main         # 查看与main分支的差异
   acr config --print             # 查看当前配置
-  acr config --init              # 初始化配置文件`,
+  acr config --init              # 初始化配置文件
+  acr baseline create            # 将上次审查的发现记录为基线
+  acr eval testdata/eval --baseline eval-main.json  # 评测并与之前的结果对比
+  acr cache clear                # 清空审查结果缓存
+  acr usage --since 30d --by model  # 统计最近30天各模型的费用
+  acr doctor                     # 检查 Git、配置、Token 和服务连通性
+  acr models list                # 列出可用模型及上下文窗口和价格`,
 		Run: func(cmd *cobra.Command, args []string) {
 			if len(os.Args) == 1 {
 				_ = cmd.Help()
@@ -62,6 +86,18 @@ func (root *RootCommand) addSubCommands() {
 		commands.CreateDiffCommand(),
 		commands.CreateConfigCommand(),
 		commands.CreateReviewCommand(),
+		commands.CreateCompareCommand(),
+		commands.CreateChatCommand(),
+		commands.CreateFixCommand(),
+		commands.CreateCommitCommand(),
+		commands.CreateDescribeCommand(),
+		commands.CreateChangelogCommand(),
+		commands.CreateBaselineCommand(),
+		commands.CreateEvalCommand(),
+		commands.CreateCacheCommand(),
+		commands.CreateUsageCommand(),
+		commands.CreateDoctorCommand(),
+		commands.CreateModelsCommand(),
 		commands.CreateVersionCommand(NAME, VERSION),
 	)
 }
@@ -0,0 +1,105 @@
+package commitmsg
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"ai_code_reviewer/internal/gitutil"
+	"ai_code_reviewer/internal/provider"
+)
+
+// DefaultTemplate 内置的 Conventional Commits 风格要求，可通过 commit_template 配置覆盖
+const DefaultTemplate = `请根据下面的暂存区 diff 生成一条符合 Conventional Commits 规范的提交信息：
+- 首行格式为 <type>(<scope>): <subject>，type 取 feat、fix、docs、style、refactor、perf、test、build、ci、chore、revert 之一，scope 可省略；
+- 首行不超过 72 个字符，使用祈使语气，结尾不加句号；
+- 首行之后空一行，用要点简述改动内容和原因；改动很小时可以省略正文；
+- 存在不兼容变更时在 type 后加 "!"，并在正文末尾添加 "BREAKING CHANGE: " 说明。`
+
+// outputRule 无论使用何种模板都附加的输出要求，便于直接作为提交信息使用
+const outputRule = "只输出提交信息本身，不要使用代码块，不要添加任何解释。"
+
+// hookMarker 用于识别由 acr 安装的 hook，避免覆盖用户自己的 hook
+const hookMarker = "# installed by acr"
+
+// hookScript prepare-commit-msg hook 内容：仅在未通过 -m、-F、merge、squash 等方式提供提交信息时生成
+const hookScript = `#!/bin/sh
+` + hookMarker + `
+[ -n "$2" ] && exit 0
+command -v acr >/dev/null 2>&1 || exit 0
+acr commit --hook-file "$1" || true
+`
+
+// Messages 构造生成提交信息的请求消息，template 为空时使用 DefaultTemplate
+func Messages(template, diff string) []provider.Message {
+	if strings.TrimSpace(template) == "" {
+		template = DefaultTemplate
+	}
+	return []provider.Message{
+		{Role: provider.RoleSystem, Content: template + "\n\n" + outputRule},
+		{Role: provider.RoleUser, Content: diff},
+	}
+}
+
+// Clean 去掉模型回复外层可能包裹的代码块和首尾空白
+func Clean(text string) string {
+	text = strings.TrimSpace(text)
+	if strings.HasPrefix(text, "```") && strings.HasSuffix(text, "```") {
+		text = strings.TrimSuffix(text, "```")
+		if i := strings.Index(text, "\n"); i >= 0 {
+			text = text[i+1:]
+		} else {
+			text = ""
+		}
+	}
+	return strings.TrimSpace(text) + "\n"
+}
+
+// StripComments 去掉以 # 开头的注释行，与 git commit 默认的 cleanup 行为一致
+func StripComments(text string) string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// InstallHook 安装 prepare-commit-msg hook，已存在非 acr 安装的 hook 时需要 force 才会覆盖
+func InstallHook(force bool) (string, error) {
+	path, err := gitutil.GitPath("hooks/prepare-commit-msg")
+	if err != nil {
+		return "", err
+	}
+	if data, err := os.ReadFile(path); err == nil && !strings.Contains(string(data), hookMarker) && !force {
+		return path, fmt.Errorf("%s 已存在，如需覆盖请加上 --force", path)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return path, fmt.Errorf("创建 hooks 目录失败: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(hookScript), 0755); err != nil {
+		return path, fmt.Errorf("写入 hook 失败: %w", err)
+	}
+	return path, nil
+}
+
+// UninstallHook 删除由 acr 安装的 prepare-commit-msg hook，不会删除用户自己的 hook
+func UninstallHook() (string, error) {
+	path, err := gitutil.GitPath("hooks/prepare-commit-msg")
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return path, fmt.Errorf("未安装 prepare-commit-msg hook")
+	}
+	if err != nil {
+		return path, err
+	}
+	if !strings.Contains(string(data), hookMarker) {
+		return path, fmt.Errorf("%s 不是由 acr 安装的，未删除", path)
+	}
+	return path, os.Remove(path)
+}
@@ -1,22 +1,110 @@
 package config
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+
+	"ai_code_reviewer/internal/focus"
+	"ai_code_reviewer/internal/lint"
+	"ai_code_reviewer/internal/usage"
 
 	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
 )
 
 // 默认配置文件路径
 const DefaultConfigFile = ".acr/config.yaml"
 
+// 默认审查结果缓存目录
+const DefaultCacheDir = ".acr/cache"
+
+// 默认 token 用量账本路径
+const DefaultUsageFile = ".acr/usage.jsonl"
+
 // Config 结构体，保存所有配置信息
 type Config struct {
-	Token  string
-	Prompt string
-	Model  string
-	Url    string
+	Provider          string // 模型服务提供方：openai、azure 或 fake
+	Token             string
+	TokenEnv          string // token 取自的服务商环境变量，如 OPENAI_API_KEY；不是配置项
+	Prompt            string
+	Model             string
+	Url               string
+	Deployment        string                 // Azure OpenAI 部署名，为空时使用 Model
+	APIVersion        string                 // Azure OpenAI 的 api-version
+	AuthMode          string                 // Azure OpenAI 认证方式：api-key 或 aad
+	CacheTTL          string                 // 缓存有效期，如 168h
+	CacheMaxSize      int                    // 缓存目录最大容量（MB）
+	Prices            map[string]usage.Price // 模型单价（美元/百万 token），覆盖内置价格表
+	MaxInputTokens    int                    // 单次审查预估输入 token 上限，0 表示不限制
+	MaxCost           float64                // 单次审查预估输入费用上限（美元），0 表示不限制
+	BudgetAction      string                 // 超出预算时的处理方式：abort 或 warn
+	RedactMode        string                 // 敏感信息处理方式：redact 替换后发送，refuse 拒绝发送，off 不检测
+	ContextMode       string                 // 仓库上下文模式：off、lines、smart
+	ContextLines      int                    // lines 模式下变更前后附加的行数
+	ContextMaxTokens  int                    // 每个文件附加上下文的 token 上限
+	Tools             string                 // 审查时是否允许模型调用工具读取仓库：on 或 off
+	MaxToolIterations int                    // 每个文件最多的工具调用轮数
+	CommitTemplate    string                 // acr commit 生成提交信息时的风格要求，为空时使用内置的 Conventional Commits 模板
+	Focus             string                 // 默认的专项审查维度，逗号分隔，为空时使用单一提示词审查
+	Passes            map[string]focus.Pass  // 自定义审查维度，覆盖同名的内置维度
+	Lint              string                 // 审查时是否执行配置的静态分析工具：on 或 off
+	Linters           []lint.Linter          // 静态分析工具列表
+	Verify            string                 // 审查后是否让模型逐条核实结构化发现：on 或 off
+	MinConfidence     float64                // 核实后保留发现的最低置信度（0-1）
+	Profiles          map[string]Profile     // 模型档案，可在 --models 中按名称引用
+	Profile           string                 // 当前使用的档案名，为空时使用顶层配置
+	Params            ModelParams            // 模型生成参数
+	Fallback          string                 // 主模型失败时依次尝试的模型或档案名，逗号分隔
+	FallbackOn        string                 // 触发回退的错误类型，逗号分隔：rate_limit、server_error、context_length、timeout
+	FallbackTimeout   string                 // 回退链中单个请求的超时时间，如 60s，为空时不限制
+}
+
+// Profile 模型档案，为空的字段沿用顶层配置
+type Profile struct {
+	Provider   string `mapstructure:"provider"`
+	Model      string `mapstructure:"model"`
+	Url        string `mapstructure:"url"`
+	Token      string `mapstructure:"token"`
+	Deployment string `mapstructure:"deployment"`
+	APIVersion string `mapstructure:"api_version"`
+	AuthMode   string `mapstructure:"auth_mode"`
+}
+
+// ForModel 返回使用指定模型的配置副本：name 为 profiles 中的档案名时使用该档案的设置，否则视为模型 ID
+func (c *Config) ForModel(name string) *Config {
+	cp := *c
+	p, ok := c.Profiles[name]
+	if !ok {
+		cp.Model = name
+		return &cp
+	}
+	cp.Model = name
+	if p.Model != "" {
+		cp.Model = p.Model
+	}
+	if p.Provider != "" {
+		cp.Provider = p.Provider
+	}
+	if p.Url != "" {
+		cp.Url = p.Url
+	}
+	if p.Token != "" {
+		cp.Token = p.Token
+	}
+	if p.Deployment != "" {
+		cp.Deployment = p.Deployment
+	}
+	if p.APIVersion != "" {
+		cp.APIVersion = p.APIVersion
+	}
+	if p.AuthMode != "" {
+		cp.AuthMode = p.AuthMode
+	}
+	return &cp
 }
 
 // InitConfigFile 初始化配置文件（若已存在则返回提示，若不存在则创建并写入默认内容）
@@ -24,7 +112,7 @@ func InitConfigFile(configFile string) error {
 	if configFile == "" {
 		configFile = DefaultConfigFile
 	}
-	configFile = getConfigPath(configFile)
+	configFile = HomePath(configFile)
 
 	dir := filepath.Dir(configFile)
 	if _, err := os.Stat(dir); os.IsNotExist(err) {
@@ -40,40 +128,185 @@ func InitConfigFile(configFile string) error {
 	v.Set("prompt", "请帮我审查以下代码变更，指出潜在问题并给出建议")
 	v.Set("model", "")
 	v.Set("url", "")
+	v.Set("cache_ttl", "168h")
+	v.Set("cache_max_size", 100)
 	if err := v.SafeWriteConfigAs(configFile); err != nil {
 		return fmt.Errorf("初始化配置文件失败: %v", err)
 	}
 	return nil
 }
 
-// UpdateConfigFile 批量更新配置项，若文件不存在则新建
-func UpdateConfigFile(configFile string, updates Config) error {
+// UpdateConfigFile 批量更新配置项，values 中的值应已经过 Key.Parse 校验，若文件不存在则新建
+func UpdateConfigFile(configFile string, values map[string]any) error {
+	v, configFile, err := openConfigFile(configFile)
+	if err != nil {
+		return err
+	}
+	for name, val := range values {
+		v.Set(name, val)
+	}
+	return writeConfigFile(v, configFile)
+}
+
+// UnsetConfigKeys 从配置文件中删除配置项，返回实际删除的配置项，删除后使用默认值。
+// 直接在 YAML 节点树上删除，保留文件中的注释以及其余配置项的顺序和键名大小写
+func UnsetConfigKeys(configFile string, names []string) ([]string, error) {
 	if configFile == "" {
 		configFile = DefaultConfigFile
 	}
-	configFile = getConfigPath(configFile)
+	configFile = HomePath(configFile)
+	data, err := os.ReadFile(configFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("读取配置文件失败: %v", err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("解析配置文件失败: %v", err)
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, nil
+	}
+
+	// 映射节点的 Content 依次为键、值，viper 读取时键名不区分大小写
+	root := doc.Content[0]
+	var removed []string
+	for _, name := range names {
+		found := false
+		for i := 0; i+1 < len(root.Content); {
+			if strings.EqualFold(root.Content[i].Value, name) {
+				root.Content = append(root.Content[:i], root.Content[i+2:]...)
+				found = true
+				continue
+			}
+			i += 2
+		}
+		if found {
+			removed = append(removed, name)
+		}
+	}
+	if len(removed) == 0 {
+		return nil, nil
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(yamlIndent(data))
+	if err := enc.Encode(&doc); err != nil {
+		return nil, fmt.Errorf("写入配置失败: %v", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("写入配置失败: %v", err)
+	}
+	if err := os.WriteFile(configFile, buf.Bytes(), 0644); err != nil {
+		return nil, fmt.Errorf("写入配置失败: %v", err)
+	}
+	return removed, nil
+}
+
+// yamlIndent 返回文件中使用的缩进宽度（取最小的非零缩进），没有缩进时使用与 viper 写入时相同的 4 个空格
+func yamlIndent(data []byte) int {
+	indent := 0
+	for _, line := range strings.Split(string(data), "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		n := len(line) - len(trimmed)
+		if n == 0 || trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if indent == 0 || n < indent {
+			indent = n
+		}
+	}
+	if indent == 0 {
+		return 4
+	}
+	return indent
+}
+
+// ValidateConfigData 按 Schema 校验 YAML 格式的配置内容，返回发现的所有问题
+func ValidateConfigData(data []byte) []error {
+	v := viper.New()
+	v.SetConfigType("yaml")
+	if err := v.ReadConfig(bytes.NewReader(data)); err != nil {
+		return []error{fmt.Errorf("解析 YAML 失败: %w", err)}
+	}
+
+	settings := v.AllSettings()
+	names := make([]string, 0, len(settings))
+	for name := range settings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []error
+	for _, name := range names {
+		k, ok := LookupKey(name)
+		if !ok {
+			errs = append(errs, fmt.Errorf("不支持的配置项: %s", name))
+			continue
+		}
+		if k.Name == "focus" {
+			// 自定义维度可能在同一份配置中新增，解析后按其中的 passes 校验
+			plain := *k
+			plain.Validate = nil
+			k = &plain
+		}
+		if err := k.Check(settings[name]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	// 结构化配置在解析时校验，focus 的 general 维度需要默认的 prompt
+	setDefaults(v)
+	cfg, err := decode(v)
+	if err != nil {
+		return append(errs, err)
+	}
+	if cfg.Focus != "" {
+		if err := checkFocus(cfg, splitList(cfg.Focus)); err != nil {
+			errs = append(errs, fmt.Errorf("无效的 focus: %s，%v", cfg.Focus, err))
+		}
+	}
+	return errs
+}
+
+// SaveConfigFile 将配置内容原样写入配置文件，保留注释和格式
+func SaveConfigFile(configFile string, data []byte) error {
+	if configFile == "" {
+		configFile = DefaultConfigFile
+	}
+	configFile = HomePath(configFile)
+	if err := os.MkdirAll(filepath.Dir(configFile), 0755); err != nil {
+		return fmt.Errorf("创建配置目录失败: %v", err)
+	}
+	if err := os.WriteFile(configFile, data, 0644); err != nil {
+		return fmt.Errorf("写入配置失败: %v", err)
+	}
+	return nil
+}
+
+// openConfigFile 读取配置文件（不存在时为空配置），返回配置文件的绝对路径
+func openConfigFile(configFile string) (*viper.Viper, string, error) {
+	if configFile == "" {
+		configFile = DefaultConfigFile
+	}
+	configFile = HomePath(configFile)
 	dir := filepath.Dir(configFile)
 	if _, err := os.Stat(dir); os.IsNotExist(err) {
 		if err := os.MkdirAll(dir, 0755); err != nil {
-			return fmt.Errorf("创建配置目录失败: %v", err)
+			return nil, "", fmt.Errorf("创建配置目录失败: %v", err)
 		}
 	}
 	v := viper.New()
 	v.SetConfigFile(configFile)
 	_ = v.ReadInConfig() // 不存在也不报错
-	if updates.Token != "" {
-		v.Set("token", updates.Token)
-	}
-	if updates.Prompt != "" {
-		v.Set("prompt", updates.Prompt)
-	}
-	if updates.Model != "" {
-		v.Set("model", updates.Model)
-	}
-	if updates.Url != "" {
-		v.Set("url", updates.Url)
-	}
+	return v, configFile, nil
+}
 
+// writeConfigFile 写入配置文件，若文件不存在则新建
+func writeConfigFile(v *viper.Viper, configFile string) error {
 	if err := v.WriteConfigAs(configFile); err != nil {
 		// 文件不存在则创建
 		if os.IsNotExist(err) {
@@ -87,8 +320,8 @@ func UpdateConfigFile(configFile string, updates Config) error {
 	return nil
 }
 
-// getConfigPath 处理 ~ 路径，返回绝对路径
-func getConfigPath(filePath string) string {
+// HomePath 将相对于用户主目录的路径转换为绝对路径
+func HomePath(filePath string) string {
 	// 获取用户主目录
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
@@ -106,16 +339,14 @@ func LoadConfig(configFile string) (*Config, error) {
 	if configFile == "" {
 		configFile = DefaultConfigFile
 	}
-	configFile = getConfigPath(configFile)
+	configFile = HomePath(configFile)
 	v.SetConfigFile(configFile)
 
-	// 环境变量前缀
-	v.SetEnvPrefix("AI_CODE_REVIEWER")
-	v.AutomaticEnv()
-
-	// 默认值
-	v.SetDefault("model", "gpt-3.5-turbo")
-	v.SetDefault("prompt", "请帮我审查以下代码变更，指出潜在问题并给出建议")
+	// 环境变量：ACR_<配置项>，旧前缀 AI_CODE_REVIEWER_ 作为别名
+	if err := bindEnv(v); err != nil {
+		return nil, err
+	}
+	setDefaults(v)
 
 	// 读取配置文件（可选）
 	if _, err := os.Stat(configFile); err == nil {
@@ -124,12 +355,15 @@ func LoadConfig(configFile string) (*Config, error) {
 		}
 	}
 
-	cfg := &Config{
-		Token:  v.GetString("token"),
-		Prompt: v.GetString("prompt"),
-		Model:  v.GetString("model"),
-		Url:    v.GetString("url"),
+	cfg, err := decode(v)
+	if err != nil {
+		return nil, err
+	}
+	applyProfileEnv(cfg)
+	if cfg, err = selectProfile(cfg); err != nil {
+		return nil, err
 	}
+	applyTokenEnv(cfg)
 
 	// if cfg.Token == "" {
 	// 	return nil, fmt.Errorf("API token 未配置，请在配置文件、环境变量或命令行参数中设置 token")
@@ -0,0 +1,132 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidateConfigData(t *testing.T) {
+	// 避免读取真实的 ~/.acr/config.yaml
+	t.Setenv("HOME", t.TempDir())
+
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{name: "空配置", data: ""},
+		{
+			name: "使用同一份配置中新增的维度",
+			data: "focus: general,logging\npasses:\n  logging:\n    prompt: 只检查日志\n",
+		},
+		{name: "未定义的维度", data: "focus: logging\n", wantErr: "未知的审查维度: logging"},
+		{name: "不支持的配置项", data: "modle: gpt-4o\n", wantErr: "不支持的配置项: modle"},
+		{name: "取值不在可选范围", data: "provider: claude\n", wantErr: "无效的 provider"},
+		{name: "YAML 格式错误", data: "model: [\n", wantErr: "解析 YAML 失败"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := ValidateConfigData([]byte(tt.data))
+			if tt.wantErr == "" {
+				if len(errs) > 0 {
+					t.Fatalf("errs = %v", errs)
+				}
+				return
+			}
+			if len(errs) == 0 || !strings.Contains(errs[0].Error(), tt.wantErr) {
+				t.Errorf("errs = %v，应包含 %q", errs, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUnsetConfigKeys(t *testing.T) {
+	const original = `# acr 配置
+
+model: gpt-4o # 默认模型
+# 缓存设置
+cache_ttl: 72h
+Cache_Max_Size: 200
+prices:
+  GPT-4o-Custom:
+    input: 2.5
+    output: 10
+linters:
+  - name: vet
+    command: go vet ./...
+    format: text
+`
+	tests := []struct {
+		name        string
+		unset       []string
+		wantRemoved []string
+		want        string
+	}{
+		{
+			name:        "保留注释、顺序和映射键的大小写",
+			unset:       []string{"cache_ttl", "model"},
+			wantRemoved: []string{"cache_ttl", "model"},
+			want: `# acr 配置
+
+Cache_Max_Size: 200
+prices:
+  GPT-4o-Custom:
+    input: 2.5
+    output: 10
+linters:
+  - name: vet
+    command: go vet ./...
+    format: text
+`,
+		},
+		{
+			name:        "键名不区分大小写",
+			unset:       []string{"cache_max_size", "prices", "linters"},
+			wantRemoved: []string{"cache_max_size", "prices", "linters"},
+			want: `# acr 配置
+
+model: gpt-4o # 默认模型
+# 缓存设置
+cache_ttl: 72h
+`,
+		},
+		{name: "不存在的配置项", unset: []string{"url"}, want: original},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := t.TempDir()
+			t.Setenv("HOME", home)
+			path := filepath.Join(home, DefaultConfigFile)
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, []byte(original), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			removed, err := UnsetConfigKeys(DefaultConfigFile, tt.unset)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(removed, tt.wantRemoved) {
+				t.Errorf("removed = %v, want %v", removed, tt.wantRemoved)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", data, tt.want)
+			}
+		})
+	}
+
+	// 配置文件不存在时不报错
+	t.Setenv("HOME", t.TempDir())
+	if removed, err := UnsetConfigKeys(DefaultConfigFile, []string{"model"}); err != nil || removed != nil {
+		t.Errorf("removed = %v, err = %v", removed, err)
+	}
+}
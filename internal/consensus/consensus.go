@@ -0,0 +1,138 @@
+package consensus
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"ai_code_reviewer/internal/finding"
+)
+
+// 判定两个发现指向同一问题的阈值
+const (
+	lineTolerance   = 3   // 行号相差不超过该值视为同一位置
+	titleSimilarity = 0.3 // 标题字符二元组的 Jaccard 相似度下限
+)
+
+// Run 单个模型的审查发现
+type Run struct {
+	Model    string
+	Findings []finding.Finding
+}
+
+// Cluster 按位置和内容相似度将多个模型的发现聚类，每个聚类返回一个代表发现，
+// 并标注给出该发现的模型及数量。代表发现取聚类中严重程度最高的一条
+func Cluster(runs []Run) []finding.Finding {
+	var all []member
+	for _, r := range runs {
+		for _, f := range r.Findings {
+			all = append(all, member{model: r.Model, f: f})
+		}
+	}
+	sort.SliceStable(all, func(i, j int) bool {
+		if all[i].f.Path != all[j].f.Path {
+			return all[i].f.Path < all[j].f.Path
+		}
+		return all[i].f.Line < all[j].f.Line
+	})
+
+	var clusters [][]member
+	for _, m := range all {
+		placed := false
+		for i := range clusters {
+			if similar(clusters[i][0].f, m.f) && !hasModel(clusters[i], m.model) {
+				clusters[i] = append(clusters[i], m)
+				placed = true
+				break
+			}
+		}
+		if !placed {
+			clusters = append(clusters, []member{m})
+		}
+	}
+
+	var merged []finding.Finding
+	for _, c := range clusters {
+		rep := c[0].f
+		var models []string
+		for _, m := range c {
+			if finding.SeverityRank(m.f.Severity) < finding.SeverityRank(rep.Severity) {
+				rep = m.f
+			}
+			models = append(models, m.model)
+		}
+		rep.Models = models
+		rep.Agreement = len(models)
+		merged = append(merged, rep)
+	}
+	finding.Sort(merged)
+	return merged
+}
+
+// Filter 只保留至少 minAgreement 个模型给出的发现
+func Filter(findings []finding.Finding, minAgreement int) []finding.Finding {
+	var kept []finding.Finding
+	for _, f := range findings {
+		if f.Agreement >= minAgreement {
+			kept = append(kept, f)
+		}
+	}
+	return kept
+}
+
+// member 聚类中的一条发现及给出它的模型
+type member struct {
+	model string
+	f     finding.Finding
+}
+
+func hasModel(members []member, model string) bool {
+	for _, m := range members {
+		if m.model == model {
+			return true
+		}
+	}
+	return false
+}
+
+// similar 判断两个发现是否指向同一问题：同一文件、位置相近，且分类相同或标题相似
+func similar(a, b finding.Finding) bool {
+	if a.Path != b.Path || !near(a, b) {
+		return false
+	}
+	return strings.EqualFold(a.Category, b.Category) || jaccard(bigrams(a.Title), bigrams(b.Title)) >= titleSimilarity
+}
+
+// near 判断两个发现的行范围是否重叠或相距不超过 lineTolerance
+func near(a, b finding.Finding) bool {
+	aEnd, bEnd := max(a.EndLine, a.Line), max(b.EndLine, b.Line)
+	return a.Line <= bEnd+lineTolerance && b.Line <= aEnd+lineTolerance
+}
+
+// bigrams 返回忽略空白和标点后的字符二元组，同时适用于中文和英文标题
+func bigrams(s string) map[string]bool {
+	var runes []rune
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			runes = append(runes, r)
+		}
+	}
+	set := make(map[string]bool)
+	for i := 0; i+1 < len(runes); i++ {
+		set[string(runes[i:i+2])] = true
+	}
+	return set
+}
+
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	inter := 0
+	for k := range a {
+		if b[k] {
+			inter++
+		}
+	}
+	return float64(inter) / float64(len(a)+len(b)-inter)
+}
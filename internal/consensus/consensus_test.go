@@ -0,0 +1,143 @@
+package consensus
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"ai_code_reviewer/internal/finding"
+)
+
+// summary 将聚类结果表示为 "路径:行 严重程度 标题 [模型]" 便于比较
+func summary(findings []finding.Finding) []string {
+	var out []string
+	for _, f := range findings {
+		out = append(out, fmt.Sprintf("%s:%d %s %s [%s]", f.Path, f.Line, f.Severity, f.Title, strings.Join(f.Models, ",")))
+		if f.Agreement != len(f.Models) {
+			out = append(out, fmt.Sprintf("agreement %d != %d", f.Agreement, len(f.Models)))
+		}
+	}
+	return out
+}
+
+func TestCluster(t *testing.T) {
+	tests := []struct {
+		name string
+		runs []Run
+		want []string
+	}{
+		{
+			name: "多个模型在相近位置报告同类问题，代表发现取最严重的一条",
+			runs: []Run{
+				{Model: "gpt-4o", Findings: []finding.Finding{{Path: "a.go", Line: 12, Severity: "medium", Category: "security", Title: "SQL 注入"}}},
+				{Model: "claude", Findings: []finding.Finding{{Path: "a.go", Line: 10, Severity: "critical", Category: "Security", Title: "拼接 SQL 语句"}}},
+				{Model: "qwen", Findings: []finding.Finding{{Path: "a.go", Line: 13, Severity: "high", Category: "security", Title: "未使用参数化查询"}}},
+			},
+			want: []string{"a.go:10 critical 拼接 SQL 语句 [claude,gpt-4o,qwen]"},
+		},
+		{
+			name: "分类不同但标题相似",
+			runs: []Run{
+				{Model: "a", Findings: []finding.Finding{{Path: "a.go", Line: 5, Severity: "high", Category: "bug", Title: "可能的空指针解引用"}}},
+				{Model: "b", Findings: []finding.Finding{{Path: "a.go", Line: 6, Severity: "high", Category: "robustness", Title: "空指针解引用"}}},
+			},
+			want: []string{"a.go:5 high 可能的空指针解引用 [a,b]"},
+		},
+		{
+			name: "分类不同且标题不相似",
+			runs: []Run{
+				{Model: "a", Findings: []finding.Finding{{Path: "a.go", Line: 5, Severity: "high", Category: "bug", Title: "空指针解引用"}}},
+				{Model: "b", Findings: []finding.Finding{{Path: "a.go", Line: 5, Severity: "low", Category: "style", Title: "变量命名不规范"}}},
+			},
+			want: []string{"a.go:5 high 空指针解引用 [a]", "a.go:5 low 变量命名不规范 [b]"},
+		},
+		{
+			name: "不同文件不合并",
+			runs: []Run{
+				{Model: "a", Findings: []finding.Finding{{Path: "a.go", Line: 5, Severity: "high", Category: "bug", Title: "空指针"}}},
+				{Model: "b", Findings: []finding.Finding{{Path: "b.go", Line: 5, Severity: "high", Category: "bug", Title: "空指针"}}},
+			},
+			want: []string{"a.go:5 high 空指针 [a]", "b.go:5 high 空指针 [b]"},
+		},
+		{
+			name: "行号相差超过容差不合并",
+			runs: []Run{
+				{Model: "a", Findings: []finding.Finding{{Path: "a.go", Line: 5, Severity: "high", Category: "bug", Title: "空指针"}}},
+				{Model: "b", Findings: []finding.Finding{{Path: "a.go", Line: 9, Severity: "high", Category: "bug", Title: "空指针"}}},
+			},
+			want: []string{"a.go:5 high 空指针 [a]", "a.go:9 high 空指针 [b]"},
+		},
+		{
+			name: "行范围相近时合并",
+			runs: []Run{
+				{Model: "a", Findings: []finding.Finding{{Path: "a.go", Line: 10, EndLine: 20, Severity: "medium", Category: "perf", Title: "循环内查询数据库"}}},
+				{Model: "b", Findings: []finding.Finding{{Path: "a.go", Line: 23, Severity: "medium", Category: "perf", Title: "N+1 查询"}}},
+			},
+			want: []string{"a.go:10 medium 循环内查询数据库 [a,b]"},
+		},
+		{
+			name: "同一模型的多条发现不合并",
+			runs: []Run{
+				{Model: "a", Findings: []finding.Finding{
+					{Path: "a.go", Line: 5, Severity: "high", Category: "bug", Title: "空指针"},
+					{Path: "a.go", Line: 6, Severity: "medium", Category: "bug", Title: "错误被忽略"},
+				}},
+				{Model: "b", Findings: []finding.Finding{{Path: "a.go", Line: 6, Severity: "medium", Category: "bug", Title: "错误被忽略"}}},
+			},
+			// b 的发现与第一个聚类相似，并入其中；a 的第二条单独成类
+			want: []string{"a.go:5 high 空指针 [a,b]", "a.go:6 medium 错误被忽略 [a]"},
+		},
+		{name: "没有发现", runs: []Run{{Model: "a"}, {Model: "b"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := summary(Cluster(tt.runs)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got  %q\nwant %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilter(t *testing.T) {
+	findings := []finding.Finding{
+		{Title: "一致", Agreement: 3},
+		{Title: "多数", Agreement: 2},
+		{Title: "单个", Agreement: 1},
+	}
+	tests := []struct {
+		minAgreement int
+		want         []string
+	}{
+		{minAgreement: 1, want: []string{"一致", "多数", "单个"}},
+		{minAgreement: 2, want: []string{"一致", "多数"}},
+		{minAgreement: 3, want: []string{"一致"}},
+		{minAgreement: 4},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, f := range Filter(findings, tt.minAgreement) {
+			got = append(got, f.Title)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Filter(%d) = %v, want %v", tt.minAgreement, got, tt.want)
+		}
+	}
+}
+
+func TestTitleSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{a: "SQL injection", b: "Possible SQL injection!", want: true},
+		{a: "SQL 注入", b: "sql注入风险", want: true},
+		{a: "空指针", b: "变量命名", want: false},
+		{a: "", b: "空指针", want: false},
+	}
+	for _, tt := range tests {
+		if got := jaccard(bigrams(tt.a), bigrams(tt.b)) >= titleSimilarity; got != tt.want {
+			t.Errorf("%q ~ %q = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package describe
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"ai_code_reviewer/internal/provider"
+)
+
+// DefaultChangelogTemplate 内置的发布说明要求，可在 .acr.yaml 的 changelog_template 中覆盖
+const DefaultChangelogTemplate = `请根据下面按类型分组的提交记录撰写面向用户的发布说明（Markdown）：
+- 保留分组和分组顺序，每组使用二级标题；
+- 将同一功能的多个提交合并为一条，用简洁的语言描述对用户的影响，不要照搬提交标题；
+- 每条末尾保留相关提交的短哈希，如 (abc1234)；
+- 存在不兼容变更时放在最前面的"不兼容变更"分组中，并说明迁移方式；
+- 只输出发布说明本身，不要添加其他解释。`
+
+// typeTitles Conventional Commits 类型及其在发布说明中的分组标题，按输出顺序排列
+var typeTitles = []struct {
+	Type  string
+	Title string
+}{
+	{"feat", "新功能"},
+	{"fix", "问题修复"},
+	{"perf", "性能优化"},
+	{"refactor", "重构"},
+	{"docs", "文档"},
+	{"test", "测试"},
+	{"build", "构建"},
+	{"ci", "持续集成"},
+	{"chore", "杂项"},
+	{"revert", "回滚"},
+}
+
+// 不兼容变更和无法识别类型的提交所在分组
+const (
+	breakingTitle = "不兼容变更"
+	otherTitle    = "其他"
+)
+
+var conventionalRe = regexp.MustCompile(`^(\w+)(?:\(([^)]*)\))?(!)?:\s*(.+)$`)
+
+// Group 发布说明中的一个分组
+type Group struct {
+	Title   string
+	Entries []string
+}
+
+// GroupCommits 按 Conventional Commits 类型对提交分组，不兼容变更单独成组放在最前
+func GroupCommits(commits []Commit) []Group {
+	entries := make(map[string][]string)
+	for _, c := range commits {
+		title, entry := otherTitle, c.Subject
+		if m := conventionalRe.FindStringSubmatch(c.Subject); m != nil {
+			if t := typeTitle(strings.ToLower(m[1])); t != "" {
+				title = t
+				entry = m[4]
+				if m[2] != "" {
+					entry = fmt.Sprintf("**%s**: %s", m[2], m[4])
+				}
+			}
+			if m[3] == "!" {
+				title = breakingTitle
+			}
+		}
+		if strings.Contains(c.Body, "BREAKING CHANGE") {
+			title = breakingTitle
+		}
+		entries[title] = append(entries[title], fmt.Sprintf("%s (%s)", entry, c.ShortHash()))
+	}
+
+	order := []string{breakingTitle}
+	for _, t := range typeTitles {
+		order = append(order, t.Title)
+	}
+	order = append(order, otherTitle)
+
+	var groups []Group
+	for _, title := range order {
+		if len(entries[title]) > 0 {
+			groups = append(groups, Group{Title: title, Entries: entries[title]})
+		}
+	}
+	return groups
+}
+
+func typeTitle(t string) string {
+	for _, tt := range typeTitles {
+		if tt.Type == t {
+			return tt.Title
+		}
+	}
+	return ""
+}
+
+// FormatGroups 将分组输出为 Markdown，不调用模型时直接作为发布说明
+func FormatGroups(groups []Group) string {
+	var b strings.Builder
+	for _, g := range groups {
+		fmt.Fprintf(&b, "## %s\n\n", g.Title)
+		for _, e := range g.Entries {
+			fmt.Fprintf(&b, "- %s\n", e)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// ChangelogMessages 构造生成发布说明的请求消息，template 为空时使用 DefaultChangelogTemplate
+func ChangelogMessages(template, from, to string, commits []Commit) []provider.Message {
+	if strings.TrimSpace(template) == "" {
+		template = DefaultChangelogTemplate
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "版本范围：%s..%s\n\n", from, to)
+	b.WriteString(FormatGroups(GroupCommits(commits)))
+
+	// 提交正文可能包含改动动机等信息，附在分组之后供模型参考
+	var details strings.Builder
+	for _, c := range commits {
+		if c.Body != "" {
+			fmt.Fprintf(&details, "### %s %s\n\n%s\n\n", c.ShortHash(), c.Subject, c.Body)
+		}
+	}
+	if details.Len() > 0 {
+		b.WriteString("提交详情：\n\n")
+		b.WriteString(details.String())
+	}
+
+	return []provider.Message{
+		{Role: provider.RoleSystem, Content: template},
+		{Role: provider.RoleUser, Content: b.String()},
+	}
+}
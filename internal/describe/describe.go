@@ -0,0 +1,105 @@
+package describe
+
+import (
+	"fmt"
+	"strings"
+
+	"ai_code_reviewer/internal/gitutil"
+	"ai_code_reviewer/internal/provider"
+)
+
+// DefaultTemplate 内置的 PR 描述模板，可在 .acr.yaml 的 describe_template 中覆盖
+const DefaultTemplate = `## 概述
+
+用两三句话说明这个 PR 解决了什么问题、为什么需要这样修改。
+
+## 变更内容
+
+按模块或目录分组列出主要改动，每组使用三级标题，组内使用列表。
+
+## 风险与注意事项
+
+列出可能影响现有行为的改动、兼容性问题、需要重点审查的地方；没有则写"无"。
+
+## 测试清单
+
+使用 "- [ ]" 列出审查者或作者需要验证的测试项。`
+
+// describePrompt 要求模型按模板输出 PR 标题和正文
+const describePrompt = `你将根据一个分支的代码 diff 和提交记录撰写 Pull Request 的标题和描述。
+输出格式要求：
+- 第一行是 PR 标题，不超过 72 个字符，不要加 "标题:"、"#" 等前缀；
+- 第二行留空；
+- 之后是 Markdown 格式的 PR 正文，严格按照下面的模板组织，不要输出模板中的说明文字：
+
+`
+
+// Commit 一条提交记录
+type Commit struct {
+	Hash    string
+	Subject string
+	Body    string
+}
+
+// ParseCommits 解析 gitutil.CommitLog 的输出
+func ParseCommits(log string) []Commit {
+	var commits []Commit
+	for _, record := range strings.Split(log, "\x1e") {
+		fields := strings.SplitN(strings.TrimLeft(record, "\n"), "\x1f", 3)
+		if len(fields) < 2 || fields[0] == "" {
+			continue
+		}
+		c := Commit{Hash: fields[0], Subject: strings.TrimSpace(fields[1])}
+		if len(fields) == 3 {
+			c.Body = strings.TrimSpace(fields[2])
+		}
+		commits = append(commits, c)
+	}
+	return commits
+}
+
+// ShortHash 返回提交的短哈希
+func (c Commit) ShortHash() string {
+	if len(c.Hash) > 7 {
+		return c.Hash[:7]
+	}
+	return c.Hash
+}
+
+// Messages 构造生成 PR 描述的请求消息，template 为空时使用 DefaultTemplate
+func Messages(template string, commits []Commit, files []gitutil.FileDiff) []provider.Message {
+	if strings.TrimSpace(template) == "" {
+		template = DefaultTemplate
+	}
+
+	var b strings.Builder
+	if len(commits) > 0 {
+		b.WriteString("提交记录：\n\n")
+		for _, c := range commits {
+			fmt.Fprintf(&b, "- %s %s\n", c.ShortHash(), c.Subject)
+			if c.Body != "" {
+				for _, line := range strings.Split(c.Body, "\n") {
+					fmt.Fprintf(&b, "  %s\n", line)
+				}
+			}
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("代码 diff：\n\n")
+	for _, f := range files {
+		b.WriteString(f.Content)
+	}
+
+	return []provider.Message{
+		{Role: provider.RoleSystem, Content: describePrompt + template},
+		{Role: provider.RoleUser, Content: b.String()},
+	}
+}
+
+// SplitTitle 将模型回复拆分为标题和正文
+func SplitTitle(text string) (string, string) {
+	text = strings.TrimSpace(text)
+	title, body, _ := strings.Cut(text, "\n")
+	title = strings.TrimSpace(strings.TrimLeft(title, "# "))
+	return title, strings.TrimSpace(body)
+}
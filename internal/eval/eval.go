@@ -0,0 +1,191 @@
+package eval
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"ai_code_reviewer/internal/finding"
+
+	"github.com/spf13/viper"
+)
+
+// 用例目录中的文件名
+const (
+	PatchFile    = "patch.diff"
+	ExpectedFile = "expected.yaml"
+)
+
+// Fixture 一个评测用例：一份 diff 及其中应当被发现的问题
+type Fixture struct {
+	Name     string
+	Diff     string
+	Focus    []string   // 用例指定的审查维度，为空时使用命令行或配置中的维度
+	Expected []Expected // 期望的发现
+}
+
+// Expected 一条期望的发现，Category 为空时匹配任意分类
+type Expected struct {
+	File     string `mapstructure:"file" json:"file"`
+	Line     int    `mapstructure:"line" json:"line"`
+	Category string `mapstructure:"category" json:"category,omitempty"`
+}
+
+// LoadFixtures 读取目录下的所有用例，每个子目录为一个用例，包含 patch.diff 和 expected.yaml
+func LoadFixtures(dir string) ([]Fixture, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("读取用例目录失败: %w", err)
+	}
+
+	var fixtures []Fixture
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		f, err := loadFixture(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		fixtures = append(fixtures, *f)
+	}
+	if len(fixtures) == 0 {
+		return nil, fmt.Errorf("%s 中没有用例，每个用例应为包含 %s 和 %s 的子目录", dir, PatchFile, ExpectedFile)
+	}
+	return fixtures, nil
+}
+
+func loadFixture(dir string) (*Fixture, error) {
+	name := filepath.Base(dir)
+	diff, err := os.ReadFile(filepath.Join(dir, PatchFile))
+	if err != nil {
+		return nil, fmt.Errorf("读取用例 %s 的 %s 失败: %w", name, PatchFile, err)
+	}
+
+	v := viper.New()
+	v.SetConfigFile(filepath.Join(dir, ExpectedFile))
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("读取用例 %s 的 %s 失败: %w", name, ExpectedFile, err)
+	}
+	f := &Fixture{Name: name, Diff: string(diff), Focus: v.GetStringSlice("focus")}
+	if err := v.UnmarshalKey("findings", &f.Expected); err != nil {
+		return nil, fmt.Errorf("解析用例 %s 的 findings 失败: %w", name, err)
+	}
+	for _, e := range f.Expected {
+		if e.File == "" || e.Line <= 0 {
+			return nil, fmt.Errorf("用例 %s 的期望发现缺少 file 或 line", name)
+		}
+	}
+	return f, nil
+}
+
+// Score 匹配结果的计数
+type Score struct {
+	TP int `json:"tp"` // 与期望匹配的发现
+	FP int `json:"fp"` // 没有对应期望的发现
+	FN int `json:"fn"` // 未被发现的期望
+}
+
+// Add 累加计数
+func (s *Score) Add(other Score) {
+	s.TP += other.TP
+	s.FP += other.FP
+	s.FN += other.FN
+}
+
+// Precision 精确率，没有任何发现时为 1
+func (s Score) Precision() float64 {
+	if s.TP+s.FP == 0 {
+		return 1
+	}
+	return float64(s.TP) / float64(s.TP+s.FP)
+}
+
+// Recall 召回率，没有任何期望时为 1
+func (s Score) Recall() float64 {
+	if s.TP+s.FN == 0 {
+		return 1
+	}
+	return float64(s.TP) / float64(s.TP+s.FN)
+}
+
+// F1 精确率与召回率的调和平均
+func (s Score) F1() float64 {
+	p, r := s.Precision(), s.Recall()
+	if p+r == 0 {
+		return 0
+	}
+	return 2 * p * r / (p + r)
+}
+
+// Result 单个用例的评测结果
+type Result struct {
+	Name   string            `json:"name"`
+	Score  Score             `json:"score"`
+	Missed []Expected        `json:"missed,omitempty"` // 未被发现的期望
+	Extra  []finding.Finding `json:"extra,omitempty"`  // 没有对应期望的发现
+	Error  string            `json:"error,omitempty"`  // 审查失败时的错误信息
+}
+
+// Match 将模型的发现与期望一一匹配：文件相同、行号相差不超过 tolerance 且分类相同（不区分大小写）。
+// 每条期望优先匹配行号最接近的发现
+func Match(name string, expected []Expected, found []finding.Finding, tolerance int) Result {
+	res := Result{Name: name}
+	used := make([]bool, len(found))
+	for _, e := range expected {
+		best, bestDist := -1, tolerance+1
+		for i, f := range found {
+			if used[i] || f.Path != e.File {
+				continue
+			}
+			if e.Category != "" && !strings.EqualFold(e.Category, f.Category) {
+				continue
+			}
+			if d := distance(f, e.Line); d < bestDist {
+				best, bestDist = i, d
+			}
+		}
+		if best < 0 {
+			res.Missed = append(res.Missed, e)
+			continue
+		}
+		used[best] = true
+		res.Score.TP++
+	}
+	for i, f := range found {
+		if !used[i] {
+			res.Extra = append(res.Extra, f)
+		}
+	}
+	res.Score.FN = len(res.Missed)
+	res.Score.FP = len(res.Extra)
+	return res
+}
+
+// distance 行号与发现行范围的距离，落在范围内时为 0
+func distance(f finding.Finding, line int) int {
+	end := max(f.EndLine, f.Line)
+	switch {
+	case line < f.Line:
+		return f.Line - line
+	case line > end:
+		return line - end
+	}
+	return 0
+}
+
+// Total 汇总所有用例的计数
+func Total(results []Result) Score {
+	var total Score
+	for _, r := range results {
+		total.Add(r.Score)
+	}
+	return total
+}
+
+// sortResults 按用例名排序，便于比较不同运行的结果
+func sortResults(results []Result) {
+	sort.Slice(results, func(i, j int) bool { return results[i].Name < results[j].Name })
+}
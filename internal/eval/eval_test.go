@@ -0,0 +1,224 @@
+package eval
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"ai_code_reviewer/internal/finding"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		name      string
+		expected  []Expected
+		found     []finding.Finding
+		tolerance int
+		want      Score
+		wantExtra []int // 未匹配的发现在 found 中的下标
+	}{
+		{
+			name:      "行号在容差内",
+			expected:  []Expected{{File: "a.go", Line: 10}},
+			found:     []finding.Finding{{Path: "a.go", Line: 13}},
+			tolerance: 3,
+			want:      Score{TP: 1},
+		},
+		{
+			name:      "行号刚超出容差",
+			expected:  []Expected{{File: "a.go", Line: 10}},
+			found:     []finding.Finding{{Path: "a.go", Line: 14}},
+			tolerance: 3,
+			want:      Score{FP: 1, FN: 1},
+			wantExtra: []int{0},
+		},
+		{
+			name:     "容差为 0 时只匹配同一行",
+			expected: []Expected{{File: "a.go", Line: 10}},
+			found:    []finding.Finding{{Path: "a.go", Line: 10}},
+			want:     Score{TP: 1},
+		},
+		{
+			name:      "落在发现的行范围内",
+			expected:  []Expected{{File: "a.go", Line: 20}},
+			found:     []finding.Finding{{Path: "a.go", Line: 12, EndLine: 25}},
+			tolerance: 0,
+			want:      Score{TP: 1},
+		},
+		{
+			name:      "文件不同",
+			expected:  []Expected{{File: "a.go", Line: 10}},
+			found:     []finding.Finding{{Path: "b.go", Line: 10}},
+			tolerance: 3,
+			want:      Score{FP: 1, FN: 1},
+			wantExtra: []int{0},
+		},
+		{
+			name:      "分类不区分大小写",
+			expected:  []Expected{{File: "a.go", Line: 10, Category: "SQL-Injection"}},
+			found:     []finding.Finding{{Path: "a.go", Line: 10, Category: "sql-injection"}},
+			tolerance: 3,
+			want:      Score{TP: 1},
+		},
+		{
+			name:      "分类不同",
+			expected:  []Expected{{File: "a.go", Line: 10, Category: "sql-injection"}},
+			found:     []finding.Finding{{Path: "a.go", Line: 10, Category: "xss"}},
+			tolerance: 3,
+			want:      Score{FP: 1, FN: 1},
+			wantExtra: []int{0},
+		},
+		{
+			name:      "期望未指定分类时匹配任意分类",
+			expected:  []Expected{{File: "a.go", Line: 10}},
+			found:     []finding.Finding{{Path: "a.go", Line: 10, Category: "xss"}},
+			tolerance: 3,
+			want:      Score{TP: 1},
+		},
+		{
+			name:      "优先匹配行号最接近的发现",
+			expected:  []Expected{{File: "a.go", Line: 10}},
+			found:     []finding.Finding{{Path: "a.go", Line: 12}, {Path: "a.go", Line: 9}},
+			tolerance: 3,
+			want:      Score{TP: 1, FP: 1},
+			wantExtra: []int{0},
+		},
+		{
+			name:      "一条发现只匹配一条期望",
+			expected:  []Expected{{File: "a.go", Line: 10}, {File: "a.go", Line: 11}},
+			found:     []finding.Finding{{Path: "a.go", Line: 10}},
+			tolerance: 3,
+			want:      Score{TP: 1, FN: 1},
+		},
+		{
+			name:     "没有发现",
+			expected: []Expected{{File: "a.go", Line: 10}},
+			want:     Score{FN: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := Match("case", tt.expected, tt.found, tt.tolerance)
+			if res.Score != tt.want {
+				t.Errorf("score = %+v, want %+v", res.Score, tt.want)
+			}
+			if len(res.Missed) != tt.want.FN {
+				t.Errorf("missed = %v，应有 %d 条", res.Missed, tt.want.FN)
+			}
+			var extra []finding.Finding
+			for _, i := range tt.wantExtra {
+				extra = append(extra, tt.found[i])
+			}
+			if !reflect.DeepEqual(res.Extra, extra) {
+				t.Errorf("extra = %v, want %v", res.Extra, extra)
+			}
+		})
+	}
+}
+
+func TestScore(t *testing.T) {
+	tests := []struct {
+		name              string
+		score             Score
+		precision, recall float64
+		f1                float64
+	}{
+		{name: "全部命中", score: Score{TP: 4}, precision: 1, recall: 1, f1: 1},
+		{name: "有误报和漏报", score: Score{TP: 3, FP: 1, FN: 2}, precision: 0.75, recall: 0.6, f1: 2 * 0.75 * 0.6 / 1.35},
+		{name: "没有发现也没有期望", score: Score{}, precision: 1, recall: 1, f1: 1},
+		{name: "只有误报", score: Score{FP: 2}, precision: 0, recall: 1, f1: 0},
+		{name: "只有漏报", score: Score{FN: 2}, precision: 1, recall: 0, f1: 0},
+		{name: "没有命中", score: Score{FP: 1, FN: 1}, precision: 0, recall: 0, f1: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, c := range []struct {
+				metric    string
+				got, want float64
+			}{
+				{"precision", tt.score.Precision(), tt.precision},
+				{"recall", tt.score.Recall(), tt.recall},
+				{"f1", tt.score.F1(), tt.f1},
+			} {
+				if math.Abs(c.got-c.want) > 1e-9 {
+					t.Errorf("%s = %v, want %v", c.metric, c.got, c.want)
+				}
+			}
+		})
+	}
+}
+
+func TestTotal(t *testing.T) {
+	results := []Result{
+		{Score: Score{TP: 1, FP: 2}},
+		{Score: Score{TP: 2, FN: 1}},
+		{Score: Score{FN: 3}},
+	}
+	if got, want := Total(results), (Score{TP: 3, FP: 2, FN: 4}); got != want {
+		t.Errorf("Total = %+v, want %+v", got, want)
+	}
+}
+
+func TestLoadFixtures(t *testing.T) {
+	fixtures, err := LoadFixtures(filepath.Join("testdata", "fixtures"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fixtures) != 2 {
+		t.Fatalf("读取到 %d 个用例，应为 2", len(fixtures))
+	}
+
+	// 按目录名排列
+	nilMap, sql := fixtures[0], fixtures[1]
+	if nilMap.Name != "nil-map" || sql.Name != "sql-injection" {
+		t.Fatalf("用例 = %s, %s", nilMap.Name, sql.Name)
+	}
+	if !strings.HasPrefix(sql.Diff, "diff --git a/store/store.go") {
+		t.Errorf("diff = %q", sql.Diff)
+	}
+	if !reflect.DeepEqual(sql.Focus, []string{"security"}) || nilMap.Focus != nil {
+		t.Errorf("focus = %v, %v", sql.Focus, nilMap.Focus)
+	}
+	want := []Expected{{File: "cache/cache.go", Line: 6}, {File: "cache/cache.go", Line: 3, Category: "style"}}
+	if !reflect.DeepEqual(nilMap.Expected, want) {
+		t.Errorf("expected = %+v, want %+v", nilMap.Expected, want)
+	}
+}
+
+func TestLoadFixturesErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string // 相对用例目录的路径 -> 内容
+		wantErr string
+	}{
+		{name: "没有用例", files: map[string]string{"README.md": "说明"}, wantErr: "没有用例"},
+		{name: "缺少 patch.diff", files: map[string]string{"case/expected.yaml": "findings: []"}, wantErr: PatchFile},
+		{name: "缺少 expected.yaml", files: map[string]string{"case/patch.diff": "diff"}, wantErr: ExpectedFile},
+		{
+			name:    "期望缺少行号",
+			files:   map[string]string{"case/patch.diff": "diff", "case/expected.yaml": "findings:\n  - file: a.go\n"},
+			wantErr: "缺少 file 或 line",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				path := filepath.Join(dir, name)
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			_, err := LoadFixtures(dir)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v，应包含 %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"ai_code_reviewer/internal/config"
+)
+
+// Run 一次评测运行的结果，可保存为 JSON 供之后的运行比较
+type Run struct {
+	Time      time.Time           `json:"time"`
+	Provider  string              `json:"provider"`
+	Model     string              `json:"model"`
+	Params    *config.ModelParams `json:"params,omitempty"` // 生成参数，未设置时省略
+	Prompt    string              `json:"prompt"`           // 提示词的指纹，用于区分不同的提示词版本
+	Focus     []string            `json:"focus"`
+	Tolerance int                 `json:"tolerance"`
+	Results   []Result            `json:"results"`
+	Total     Score               `json:"total"`
+}
+
+// Finish 排序用例结果并计算汇总
+func (r *Run) Finish() {
+	sortResults(r.Results)
+	r.Total = Total(r.Results)
+}
+
+// Save 将运行结果写入 JSON 文件
+func (r *Run) Save(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("写入评测结果失败: %w", err)
+	}
+	return nil
+}
+
+// LoadRun 读取之前保存的运行结果
+func LoadRun(path string) (*Run, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取评测结果失败: %w", err)
+	}
+	var r Run
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("解析评测结果 %s 失败: %w", path, err)
+	}
+	return &r, nil
+}
+
+// WriteTable 输出各用例及汇总的得分
+func (r *Run) WriteTable(out io.Writer) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(w, "用例\tTP\tFP\tFN\t精确率\t召回率\tF1\t\n")
+	for _, res := range r.Results {
+		if res.Error != "" {
+			fmt.Fprintf(w, "%s\t-\t-\t-\t-\t-\t失败\t\n", res.Name)
+			continue
+		}
+		writeScore(w, res.Name, res.Score)
+	}
+	writeScore(w, "合计", r.Total)
+	w.Flush()
+}
+
+func writeScore(w io.Writer, name string, s Score) {
+	fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%.3f\t%.3f\t%.3f\t\n", name, s.TP, s.FP, s.FN, s.Precision(), s.Recall(), s.F1())
+}
+
+// WriteComparison 输出与基准运行的对比：汇总指标及各用例 F1 的变化
+func WriteComparison(out io.Writer, base, cur *Run) {
+	fmt.Fprintf(out, "基准: %s %s（%s）  当前: %s %s\n\n",
+		base.Provider, base.Model, base.Time.Local().Format("2006-01-02 15:04"), cur.Provider, cur.Model)
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(w, "指标\t基准\t当前\t变化\t\n")
+	metrics := []struct {
+		name      string
+		base, cur float64
+	}{
+		{"精确率", base.Total.Precision(), cur.Total.Precision()},
+		{"召回率", base.Total.Recall(), cur.Total.Recall()},
+		{"F1", base.Total.F1(), cur.Total.F1()},
+	}
+	for _, m := range metrics {
+		fmt.Fprintf(w, "%s\t%.3f\t%.3f\t%+.3f\t\n", m.name, m.base, m.cur, m.cur-m.base)
+	}
+	w.Flush()
+
+	baseResults := make(map[string]Result, len(base.Results))
+	for _, res := range base.Results {
+		baseResults[res.Name] = res
+	}
+	fmt.Fprintln(out)
+	w = tabwriter.NewWriter(out, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(w, "用例\t基准 F1\t当前 F1\t变化\t\n")
+	for _, res := range cur.Results {
+		prev, ok := baseResults[res.Name]
+		switch {
+		case !ok || prev.Error != "":
+			fmt.Fprintf(w, "%s\t-\t%s\t\t\n", res.Name, f1Text(res))
+		case res.Error != "":
+			fmt.Fprintf(w, "%s\t%s\t失败\t\t\n", res.Name, f1Text(prev))
+		default:
+			fmt.Fprintf(w, "%s\t%.3f\t%.3f\t%+.3f\t\n", res.Name, prev.Score.F1(), res.Score.F1(), res.Score.F1()-prev.Score.F1())
+		}
+	}
+	w.Flush()
+}
+
+func f1Text(res Result) string {
+	if res.Error != "" {
+		return "失败"
+	}
+	return fmt.Sprintf("%.3f", res.Score.F1())
+}
@@ -0,0 +1,178 @@
+package finding
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// 严重程度，按从高到低排列
+const (
+	SeverityCritical = "critical"
+	SeverityHigh     = "high"
+	SeverityMedium   = "medium"
+	SeverityLow      = "low"
+	SeverityInfo     = "info"
+)
+
+var severityRank = map[string]int{
+	SeverityCritical: 0,
+	SeverityHigh:     1,
+	SeverityMedium:   2,
+	SeverityLow:      3,
+	SeverityInfo:     4,
+}
+
+// Finding 一条结构化的审查发现
+type Finding struct {
+	Path         string        `json:"path"`
+	Line         int           `json:"line"`
+	EndLine      int           `json:"end_line,omitempty"`
+	Severity     string        `json:"severity"`
+	Category     string        `json:"category"`
+	Title        string        `json:"title"`
+	Detail       string        `json:"detail,omitempty"`
+	Suggestion   string        `json:"suggestion,omitempty"`
+	Pass         string        `json:"pass,omitempty"`         // 产生该发现的审查维度
+	Source       string        `json:"source,omitempty"`       // 来自静态分析工具时为工具名，模型给出的发现为空
+	Related      []string      `json:"related,omitempty"`      // 与该发现重合的静态分析诊断
+	Fingerprint  string        `json:"fingerprint,omitempty"`  // 用于基线比对的指纹，不随行号变化
+	Models       []string      `json:"models,omitempty"`       // 多模型审查时给出该发现的模型
+	Agreement    int           `json:"agreement,omitempty"`    // 多模型审查时给出该发现的模型数
+	Verification *Verification `json:"verification,omitempty"` // 核实结论，未核实时为空
+}
+
+// Verification 核实阶段对一条发现的结论
+type Verification struct {
+	Verdict    string  `json:"verdict"`    // confirmed 或 refuted
+	Confidence float64 `json:"confidence"` // 问题真实存在的把握，0-1
+	Reason     string  `json:"reason,omitempty"`
+}
+
+// OutputSchema 要求模型输出的 JSON 格式说明，附加在各审查维度的提示词之后
+const OutputSchema = `只输出一个 JSON 对象，不要使用代码块，不要添加其他文字，格式如下：
+{"findings": [{"line": 12, "end_line": 14, "severity": "high", "category": "分类", "title": "一句话概括问题", "detail": "问题原因和影响", "suggestion": "修改建议，可选"}]}
+- line/end_line 为新文件中的行号，diff 中每行开头的数字即为行号；删除的代码使用删除位置的行号；
+- severity 取 critical、high、medium、low、info 之一；
+- 没有发现问题时输出 {"findings": []}。`
+
+// Parse 解析模型输出的 JSON，容忍外层代码块和前后多余文字。
+// 返回的发现会补齐 path 和 pass，并将严重程度规范化
+func Parse(text, path, pass string) ([]Finding, error) {
+	text = strings.TrimSpace(text)
+	start := strings.Index(text, "{")
+	end := strings.LastIndex(text, "}")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("输出中没有 JSON 对象")
+	}
+
+	var out struct {
+		Findings []Finding `json:"findings"`
+	}
+	if err := json.Unmarshal([]byte(text[start:end+1]), &out); err != nil {
+		return nil, fmt.Errorf("解析 JSON 失败: %w", err)
+	}
+
+	findings := out.Findings[:0]
+	for _, f := range out.Findings {
+		if strings.TrimSpace(f.Title) == "" {
+			continue
+		}
+		f.Path = path
+		f.Pass = pass
+		f.Severity = NormalizeSeverity(f.Severity)
+		if f.EndLine < f.Line {
+			f.EndLine = 0
+		}
+		findings = append(findings, f)
+	}
+	return findings, nil
+}
+
+// NormalizeSeverity 将严重程度转为小写，无法识别时视为 medium
+func NormalizeSeverity(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if _, ok := severityRank[s]; ok {
+		return s
+	}
+	return SeverityMedium
+}
+
+// SeverityRank 严重程度的排序值，越小越严重
+func SeverityRank(s string) int {
+	if r, ok := severityRank[s]; ok {
+		return r
+	}
+	return len(severityRank)
+}
+
+// Sort 按文件、行号、严重程度排序
+func Sort(findings []Finding) {
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return SeverityRank(a.Severity) < SeverityRank(b.Severity)
+	})
+}
+
+// Location 返回 "L12" 或 "L12-14" 形式的位置
+func (f Finding) Location() string {
+	if f.EndLine > f.Line {
+		return fmt.Sprintf("L%d-%d", f.Line, f.EndLine)
+	}
+	return fmt.Sprintf("L%d", f.Line)
+}
+
+// Markdown 将单个文件的发现渲染为 Markdown 列表
+func Markdown(findings []Finding) string {
+	var b strings.Builder
+	for _, f := range findings {
+		fmt.Fprintf(&b, "- **[%s]** %s `%s` %s", f.Severity, f.Location(), f.Category, f.Title)
+		if f.Pass != "" {
+			fmt.Fprintf(&b, " _(%s)_", f.Pass)
+		}
+		if f.Source != "" {
+			fmt.Fprintf(&b, " _(%s)_", f.Source)
+		}
+		if f.Agreement > 0 {
+			fmt.Fprintf(&b, " _(%d 个模型: %s)_", f.Agreement, strings.Join(f.Models, "、"))
+		}
+		if len(f.Related) > 0 {
+			fmt.Fprintf(&b, " _(同 %s)_", strings.Join(f.Related, "、"))
+		}
+		if f.Verification != nil {
+			fmt.Fprintf(&b, " _(置信度 %.0f%%)_", f.Verification.Confidence*100)
+		}
+		b.WriteString("\n")
+		if f.Detail != "" {
+			fmt.Fprintf(&b, "  %s\n", indent(f.Detail))
+		}
+		if f.Suggestion != "" {
+			fmt.Fprintf(&b, "  建议：%s\n", indent(f.Suggestion))
+		}
+	}
+	return b.String()
+}
+
+// indent 使多行文本在列表项中保持缩进
+func indent(s string) string {
+	return strings.ReplaceAll(strings.TrimSpace(s), "\n", "\n  ")
+}
+
+// Covers 判断发现的行范围是否覆盖（或紧邻）指定行
+func (f Finding) Covers(path string, line int) bool {
+	if f.Path != path {
+		return false
+	}
+	end := f.EndLine
+	if end < f.Line {
+		end = f.Line
+	}
+	return line >= f.Line-1 && line <= end+1
+}
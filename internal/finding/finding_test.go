@@ -0,0 +1,99 @@
+package finding
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		want    []Finding
+		wantErr bool
+	}{
+		{
+			name: "标准输出",
+			text: `{"findings": [{"line": 12, "end_line": 14, "severity": "high", "category": "bug", "title": "空指针", "detail": "d", "suggestion": "s"}]}`,
+			want: []Finding{{Path: "a.go", Line: 12, EndLine: 14, Severity: "high", Category: "bug", Title: "空指针", Detail: "d", Suggestion: "s", Pass: "general"}},
+		},
+		{
+			name: "代码块和前后文字",
+			text: "审查结果如下：\n```json\n{\"findings\": [{\"line\": 3, \"severity\": \"low\", \"category\": \"style\", \"title\": \"命名\"}]}\n```\n以上。",
+			want: []Finding{{Path: "a.go", Line: 3, Severity: "low", Category: "style", Title: "命名", Pass: "general"}},
+		},
+		{
+			name: "严重程度规范化",
+			text: `{"findings": [{"line": 1, "severity": " HIGH ", "title": "a"}, {"line": 2, "severity": "blocker", "title": "b"}, {"line": 3, "title": "c"}]}`,
+			want: []Finding{
+				{Path: "a.go", Line: 1, Severity: "high", Title: "a", Pass: "general"},
+				{Path: "a.go", Line: 2, Severity: "medium", Title: "b", Pass: "general"},
+				{Path: "a.go", Line: 3, Severity: "medium", Title: "c", Pass: "general"},
+			},
+		},
+		{
+			name: "结束行小于起始行时忽略",
+			text: `{"findings": [{"line": 9, "end_line": 4, "severity": "low", "title": "a"}]}`,
+			want: []Finding{{Path: "a.go", Line: 9, Severity: "low", Title: "a", Pass: "general"}},
+		},
+		{
+			name: "跳过没有标题的发现，模型给出的路径被覆盖",
+			text: `{"findings": [{"line": 1, "title": "  "}, {"path": "other.go", "line": 2, "severity": "info", "title": "b"}]}`,
+			want: []Finding{{Path: "a.go", Line: 2, Severity: "info", Title: "b", Pass: "general"}},
+		},
+		{name: "没有发现", text: `{"findings": []}`, want: []Finding{}},
+		{name: "没有 JSON", text: "看起来没有问题。", wantErr: true},
+		{name: "JSON 不完整", text: `{"findings": [{"line": 1,}`, wantErr: true},
+		{name: "字段类型错误", text: `{"findings": [{"line": "12", "title": "a"}]}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.text, "a.go", "general")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSort(t *testing.T) {
+	findings := []Finding{
+		{Path: "b.go", Line: 1, Severity: SeverityCritical},
+		{Path: "a.go", Line: 5, Severity: SeverityLow},
+		{Path: "a.go", Line: 5, Severity: SeverityHigh},
+		{Path: "a.go", Line: 2, Severity: SeverityInfo},
+	}
+	Sort(findings)
+	var got []string
+	for _, f := range findings {
+		got = append(got, f.Path+":"+f.Location()+":"+f.Severity)
+	}
+	want := []string{"a.go:L2:info", "a.go:L5:high", "a.go:L5:low", "b.go:L1:critical"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestCovers(t *testing.T) {
+	f := Finding{Path: "a.go", Line: 10, EndLine: 12}
+	tests := []struct {
+		path string
+		line int
+		want bool
+	}{
+		{"a.go", 8, false},
+		{"a.go", 9, true},
+		{"a.go", 11, true},
+		{"a.go", 13, true},
+		{"a.go", 14, false},
+		{"b.go", 11, false},
+	}
+	for _, tt := range tests {
+		if got := f.Covers(tt.path, tt.line); got != tt.want {
+			t.Errorf("Covers(%s, %d) = %v, want %v", tt.path, tt.line, got, tt.want)
+		}
+	}
+}
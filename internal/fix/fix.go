@@ -0,0 +1,130 @@
+package fix
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"ai_code_reviewer/internal/gitutil"
+	"ai_code_reviewer/internal/provider"
+	"ai_code_reviewer/internal/redact"
+)
+
+// 附加完整内容的文件行数上限，超过时模型只能依据 diff 生成补丁
+const maxFileLines = 2000
+
+// patchPrompt 要求模型针对审查结论输出可直接 git apply 的补丁
+const patchPrompt = `请针对上面审查中指出的问题给出修复补丁，要求：
+1. 每个补丁以一行 "### 标题" 开头，标题简要说明修复的问题；
+2. 标题后紧跟一个 ` + "```diff" + ` 代码块，内容为相对仓库根目录、可以被 git apply 直接应用的 unified diff，包含 "--- a/路径" 和 "+++ b/路径" 头部；
+3. hunk 中的上下文行和删除行必须与上面给出的当前文件内容逐字一致；
+4. 每个补丁只修复一个问题，互相独立，不要依赖其他补丁；
+5. 无法通过修改代码解决或不确定如何修复的问题直接略过，不要输出补丁。`
+
+// Patch 模型给出的一个修复补丁
+type Patch struct {
+	Title string
+	Diff  string
+}
+
+// Files 返回补丁修改的文件路径
+func (p Patch) Files() []string {
+	var files []string
+	for _, f := range gitutil.SplitDiff(p.Diff) {
+		files = append(files, f.Path)
+	}
+	return files
+}
+
+var (
+	headingRe = regexp.MustCompile(`^#{1,6}\s+(.+?)\s*$`)
+	fenceRe   = regexp.MustCompile("^\\s*```\\s*(diff|patch)?\\s*$")
+)
+
+// Parse 从模型回复中解析补丁：每个 diff 代码块为一个补丁，取其前最近的标题作为补丁标题
+func Parse(text string) []Patch {
+	var patches []Patch
+	var title string
+	var body strings.Builder
+	inBlock := false
+
+	for _, line := range strings.Split(text, "\n") {
+		if inBlock {
+			if strings.TrimSpace(line) == "```" {
+				inBlock = false
+				if diff := body.String(); strings.Contains(diff, "+++ ") {
+					if title == "" {
+						title = fmt.Sprintf("补丁 %d", len(patches)+1)
+					}
+					patches = append(patches, Patch{Title: title, Diff: diff})
+				}
+				title = ""
+				continue
+			}
+			body.WriteString(line)
+			body.WriteString("\n")
+			continue
+		}
+
+		if m := fenceRe.FindStringSubmatch(line); m != nil && m[1] != "" {
+			inBlock = true
+			body.Reset()
+			continue
+		}
+		if m := headingRe.FindStringSubmatch(line); m != nil {
+			title = m[1]
+		}
+	}
+	return patches
+}
+
+// Messages 在上次审查对话之后附加各文件当前内容和生成补丁的要求。
+// redactMode 为 refuse 且文件包含敏感信息时返回错误
+func Messages(history []provider.Message, diff, redactMode string) ([]provider.Message, []redact.Redaction, error) {
+	var b strings.Builder
+	b.WriteString("以下是审查涉及文件的当前内容：\n\n")
+
+	var found []redact.Redaction
+	refused := false
+	for _, f := range gitutil.SplitDiff(diff) {
+		data, err := gitutil.ShowFile("", f.Path)
+		if err != nil {
+			// 文件已被删除，模型只能依据 diff 判断
+			continue
+		}
+		content := string(data)
+		if n := strings.Count(content, "\n"); n > maxFileLines {
+			fmt.Fprintf(&b, "`%s` 共 %d 行，内容过长已省略，请依据 diff 生成补丁。\n\n", f.Path, n)
+			continue
+		}
+		content, redactions, err := redact.ApplyText(redactMode, f.Path, content)
+		found = append(found, redactions...)
+		if err != nil {
+			refused = true
+		}
+		fmt.Fprintf(&b, "`%s`：\n\n```\n%s\n```\n\n", f.Path, strings.TrimRight(content, "\n"))
+	}
+	if refused {
+		return nil, found, fmt.Errorf("文件中检测到 %d 处敏感信息，已拒绝发送（redact_mode=refuse）", len(found))
+	}
+	b.WriteString(patchPrompt)
+
+	messages := append([]provider.Message(nil), history...)
+	messages = append(messages, provider.Message{Role: provider.RoleUser, Content: b.String()})
+	return messages, found, nil
+}
+
+// Check 检查补丁能否应用到当前工作区
+func Check(p Patch) error {
+	return gitutil.ApplyPatch(p.Diff, "--check")
+}
+
+// Apply 将补丁应用到工作区
+func Apply(p Patch) error {
+	return gitutil.ApplyPatch(p.Diff)
+}
+
+// Revert 撤销已应用的补丁
+func Revert(p Patch) error {
+	return gitutil.ApplyPatch(p.Diff, "--reverse")
+}
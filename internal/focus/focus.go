@@ -0,0 +1,124 @@
+package focus
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"ai_code_reviewer/internal/finding"
+)
+
+// Pass 一个专项审查维度，每个维度使用独立的提示词、分类体系和严重程度标准
+type Pass struct {
+	Name        string   `mapstructure:"-"`
+	Description string   `mapstructure:"description"`
+	Prompt      string   `mapstructure:"prompt"`     // 审查关注点
+	Categories  []string `mapstructure:"categories"` // 可用的问题分类
+	Severity    string   `mapstructure:"severity"`   // 严重程度判定标准
+}
+
+// General 通用维度的名称，提示词取自配置中的 prompt
+const General = "general"
+
+// Builtin 内置的审查维度
+var Builtin = map[string]Pass{
+	General: {
+		Description: "通用代码审查",
+		Categories:  []string{"bug", "error-handling", "design", "readability", "maintainability"},
+		Severity:    "critical: 必然导致错误结果、崩溃或数据损坏；high: 特定条件下出错；medium: 潜在问题或明显的设计缺陷；low: 可读性和风格问题；info: 建议。",
+	},
+	"security": {
+		Description: "安全审查",
+		Prompt: "你是一名应用安全专家，只关注安全问题：注入（SQL、命令、路径、模板）、认证与授权缺陷、敏感信息泄露、" +
+			"不安全的加密与随机数、反序列化、SSRF、XSS、竞争条件导致的越权、依赖与配置中的安全隐患。不要报告与安全无关的问题。",
+		Categories: []string{"CWE-20", "CWE-22", "CWE-78", "CWE-79", "CWE-89", "CWE-200", "CWE-287", "CWE-295",
+			"CWE-327", "CWE-330", "CWE-352", "CWE-362", "CWE-502", "CWE-798", "CWE-862", "CWE-918"},
+		Severity: "critical: 无需认证即可远程利用，可导致代码执行或大规模数据泄露；high: 需要一定条件但可被利用；" +
+			"medium: 纵深防御缺失或利用难度较高；low: 最佳实践问题；info: 加固建议。category 使用最贴切的 CWE 编号，列表中没有时可使用其他 CWE 编号。",
+	},
+	"perf": {
+		Description: "性能审查",
+		Prompt: "你是一名性能优化专家，只关注性能问题：算法复杂度、循环中的重复计算和 I/O、N+1 查询、不必要的内存分配与拷贝、" +
+			"缺少缓存或批量处理、锁竞争、资源未释放。不要报告与性能无关的问题。",
+		Categories: []string{"complexity", "allocation", "io", "n+1", "caching", "resource-leak", "contention"},
+		Severity: "critical: 在生产规模下会导致不可用或资源耗尽；high: 热路径上的明显退化；medium: 非热路径上的低效实现；" +
+			"low: 微小的优化空间；info: 建议。没有证据表明位于热路径时不要高于 medium。",
+	},
+	"concurrency": {
+		Description: "并发审查",
+		Prompt: "你是一名并发编程专家，只关注并发问题：数据竞争、死锁、goroutine/线程泄漏、channel 误用、" +
+			"未同步的共享状态、context 取消与超时处理、原子性假设错误。不要报告与并发无关的问题。",
+		Categories: []string{"data-race", "deadlock", "leak", "channel", "atomicity", "cancellation"},
+		Severity:   "critical: 必然出现的死锁或数据损坏；high: 可能出现的数据竞争或泄漏；medium: 依赖时序的潜在问题；low: 风格问题；info: 建议。",
+	},
+	"tests": {
+		Description: "测试审查",
+		Prompt: "你是一名测试专家，只关注测试问题：新增或修改的逻辑缺少测试、边界条件和错误路径未覆盖、" +
+			"断言不充分、测试之间相互依赖、不稳定的测试（依赖时间、随机数、网络）。不要报告与测试无关的问题。",
+		Categories: []string{"missing-test", "edge-case", "assertion", "flaky", "isolation"},
+		Severity:   "high: 关键逻辑完全没有测试；medium: 重要分支或错误路径未覆盖；low: 断言或结构可以改进；info: 建议。",
+	},
+}
+
+// Resolve 按名称解析审查维度，custom 中的定义会覆盖同名的内置维度。
+// basePrompt 作为通用维度的审查要求
+func Resolve(names []string, custom map[string]Pass, basePrompt string) ([]Pass, error) {
+	available := Available(custom)
+
+	var passes []Pass
+	seen := make(map[string]bool)
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		p, ok := custom[name]
+		if !ok {
+			p, ok = Builtin[name]
+		}
+		if !ok {
+			return nil, fmt.Errorf("未知的审查维度: %s，可选: %s", name, strings.Join(available, "，"))
+		}
+		p.Name = name
+		if name == General && p.Prompt == "" {
+			p.Prompt = basePrompt
+		}
+		if strings.TrimSpace(p.Prompt) == "" {
+			return nil, fmt.Errorf("审查维度 %s 缺少 prompt", name)
+		}
+		passes = append(passes, p)
+	}
+	return passes, nil
+}
+
+// Available 返回所有可用维度的名称（内置和自定义），按名称排序
+func Available(custom map[string]Pass) []string {
+	var names []string
+	for name := range Builtin {
+		names = append(names, name)
+	}
+	for name := range custom {
+		if _, ok := Builtin[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SystemPrompt 生成该维度的系统提示词
+func (p Pass) SystemPrompt() string {
+	var b strings.Builder
+	b.WriteString(strings.TrimSpace(p.Prompt))
+	if len(p.Categories) > 0 {
+		fmt.Fprintf(&b, "\n\ncategory 从以下分类中选择：%s。", strings.Join(p.Categories, "、"))
+	}
+	if p.Severity != "" {
+		fmt.Fprintf(&b, "\n\n严重程度判定标准：%s", strings.TrimSpace(p.Severity))
+	}
+	b.WriteString("\n\n")
+	b.WriteString(finding.OutputSchema)
+	return b.String()
+}
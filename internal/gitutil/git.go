@@ -1,9 +1,11 @@
 package gitutil
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 )
 
@@ -91,8 +93,13 @@ func isBenignDiffError(err error, output []byte) bool {
 }
 
 func runGitCommand(args ...string) (string, error) {
+	dir, _ := os.Getwd()
+	return runGitCommandIn(dir, args...)
+}
+
+func runGitCommandIn(dir string, args ...string) (string, error) {
 	cmd := exec.Command("git", args...)
-	cmd.Dir, _ = os.Getwd()
+	cmd.Dir = dir
 	cmd.Env = append(os.Environ(),
 		"GIT_CONFIG_NOSYSTEM=1",
 		"GIT_TERMINAL_PROMPT=0",
@@ -109,3 +116,209 @@ func runGitCommand(args ...string) (string, error) {
 func isEmptyRef(ref string) bool {
 	return ref == "" || ref == "."
 }
+
+// RevParse 解析引用对应的提交 SHA
+func RevParse(ref string) (string, error) {
+	if isEmptyRef(ref) {
+		ref = "HEAD"
+	}
+	out, err := runGitCommand("rev-parse", "--verify", ref+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("解析引用 %s 失败: %w", ref, err)
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// CurrentBranch 获取当前分支名，分离头指针时返回 HEAD
+func CurrentBranch() (string, error) {
+	out, err := runGitCommand("rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("获取当前分支失败: %w", err)
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// GitDir 获取当前仓库 .git 目录的绝对路径
+func GitDir() (string, error) {
+	out, err := runGitCommand("rev-parse", "--absolute-git-dir")
+	if err != nil {
+		return "", fmt.Errorf("获取 .git 目录失败: %w", err)
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// TopLevel 获取仓库根目录的绝对路径
+func TopLevel() (string, error) {
+	out, err := runGitCommand("rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", fmt.Errorf("获取仓库根目录失败: %w", err)
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// ShowFile 读取相对仓库根目录的文件，ref 为空时读取工作区文件，否则读取该引用下的版本
+func ShowFile(ref, path string) ([]byte, error) {
+	if isEmptyRef(ref) {
+		top, err := TopLevel()
+		if err != nil {
+			return nil, err
+		}
+		return os.ReadFile(filepath.Join(top, path))
+	}
+	cmd := exec.Command("git", "show", ref+":"+path)
+	cmd.Dir, _ = os.Getwd()
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("读取 %s:%s 失败: %w", ref, path, err)
+	}
+	return output, nil
+}
+
+// ListFiles 列出仓库中的文件（路径相对仓库根目录），ref 为空时列出工作区中已跟踪和未忽略的文件。
+// patterns 可以是目录前缀（如 internal/config）或文件名通配符（如 *.py），为空时返回全部文件
+func ListFiles(ref string, patterns ...string) ([]string, error) {
+	top, err := TopLevel()
+	if err != nil {
+		return nil, err
+	}
+
+	var out string
+	if isEmptyRef(ref) {
+		out, err = runGitCommandIn(top, "ls-files", "--cached", "--others", "--exclude-standard")
+	} else {
+		out, err = runGitCommandIn(top, "ls-tree", "-r", "--name-only", "--full-tree", ref)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, f := range strings.Split(strings.TrimSpace(out), "\n") {
+		if f != "" && matchesAny(f, patterns) {
+			files = append(files, f)
+		}
+	}
+	return files, nil
+}
+
+func matchesAny(file string, patterns []string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, p := range patterns {
+		if strings.ContainsAny(p, "*?[") {
+			if ok, _ := filepath.Match(p, filepath.Base(file)); ok {
+				return true
+			}
+			continue
+		}
+		p = strings.TrimSuffix(p, "/")
+		if p == "." || p == "" || file == p || strings.HasPrefix(file, p+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// Grep 在仓库中按正则搜索，返回 "路径:行号:内容" 格式的结果，无匹配时返回空字符串
+func Grep(ref, pattern, pathspec string) (string, error) {
+	top, err := TopLevel()
+	if err != nil {
+		return "", err
+	}
+
+	args := []string{"grep", "-n", "-I", "-E", "-e", pattern}
+	if !isEmptyRef(ref) {
+		args = append(args, ref)
+	}
+	if pathspec != "" {
+		args = append(args, "--", pathspec)
+	}
+
+	out, err := runGitCommandIn(top, args...)
+	if err != nil {
+		// git grep 没有匹配时退出码为 1
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 && out == "" {
+			return "", nil
+		}
+		return "", err
+	}
+	if !isEmptyRef(ref) {
+		out = strings.ReplaceAll("\n"+out, "\n"+ref+":", "\n")[1:]
+	}
+	return out, nil
+}
+
+// Log 获取与路径相关的最近 n 条提交（单行格式），ref 为空时从 HEAD 开始
+func Log(ref, path string, n int) (string, error) {
+	top, err := TopLevel()
+	if err != nil {
+		return "", err
+	}
+
+	args := []string{"log", "--oneline", "--no-decorate", fmt.Sprintf("-n%d", n)}
+	if !isEmptyRef(ref) {
+		args = append(args, ref)
+	}
+	if path != "" {
+		args = append(args, "--", path)
+	}
+	return runGitCommandIn(top, args...)
+}
+
+// ApplyPatch 在仓库根目录执行 git apply，补丁内容从标准输入传入。
+// 始终附加 --recount，容忍补丁中 hunk 头部行数不准确的情况
+func ApplyPatch(patch string, args ...string) error {
+	top, err := TopLevel()
+	if err != nil {
+		return err
+	}
+	if !strings.HasSuffix(patch, "\n") {
+		patch += "\n"
+	}
+
+	cmd := exec.Command("git", append(append([]string{"apply", "--recount"}, args...), "-")...)
+	cmd.Dir = top
+	cmd.Stdin = strings.NewReader(patch)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// StagedDiff 获取暂存区的 diff
+func StagedDiff() (string, error) {
+	out, err := runGitCommand("diff", "--cached")
+	if err != nil {
+		return "", fmt.Errorf("获取暂存区 diff 失败: %w", err)
+	}
+	return out, nil
+}
+
+// GitPath 获取 .git 目录下指定路径的绝对路径，会遵循 core.hooksPath 等配置
+func GitPath(name string) (string, error) {
+	out, err := runGitCommand("rev-parse", "--path-format=absolute", "--git-path", name)
+	if err != nil {
+		return "", fmt.Errorf("解析 %s 路径失败: %w", name, err)
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// CommitLog 获取 from..to 之间（不含合并提交）的提交记录，按时间从旧到新排列。
+// 每条记录的字段以 \x1f 分隔（完整哈希、标题、正文），记录之间以 \x1e 分隔
+func CommitLog(from, to string) (string, error) {
+	if isEmptyRef(to) {
+		to = "HEAD"
+	}
+	rng := to
+	if !isEmptyRef(from) {
+		rng = from + ".." + to
+	}
+	out, err := runGitCommand("log", "--no-merges", "--reverse", "--format=%H%x1f%s%x1f%b%x1e", rng)
+	if err != nil {
+		return "", fmt.Errorf("获取提交记录失败: %w", err)
+	}
+	return out, nil
+}
@@ -0,0 +1,185 @@
+package gitutil
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// FileDiff 单个文件的 diff 片段
+type FileDiff struct {
+	Path    string
+	Content string
+}
+
+// SplitDiff 按文件拆分 diff 内容，每个 "diff --git" 开头的块为一个文件
+func SplitDiff(diff string) []FileDiff {
+	var files []FileDiff
+	var current strings.Builder
+
+	flush := func() {
+		if current.Len() == 0 {
+			return
+		}
+		content := current.String()
+		files = append(files, FileDiff{Path: parseDiffPath(content), Content: content})
+		current.Reset()
+	}
+
+	for _, line := range strings.SplitAfter(diff, "\n") {
+		if strings.HasPrefix(line, "diff --git ") {
+			flush()
+		}
+		current.WriteString(line)
+	}
+	flush()
+
+	return files
+}
+
+// Normalized 返回归一化后的 diff 内容（去掉 index 行和行尾空白），
+// 用于在 blob 哈希变化但实际改动不变时生成稳定的缓存键
+func (f FileDiff) Normalized() string {
+	var b strings.Builder
+	for _, line := range strings.Split(f.Content, "\n") {
+		if strings.HasPrefix(line, "index ") {
+			continue
+		}
+		b.WriteString(strings.TrimRight(line, " \t\r"))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// parseDiffPath 从单个文件 diff 中解析文件路径，优先使用 +++ 行，删除文件时使用 --- 行
+func parseDiffPath(content string) string {
+	var header, oldPath string
+	for _, line := range strings.Split(content, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			header = line
+		case strings.HasPrefix(line, "--- "):
+			oldPath = trimDiffPrefix(strings.TrimPrefix(line, "--- "))
+		case strings.HasPrefix(line, "+++ "):
+			if path := trimDiffPrefix(strings.TrimPrefix(line, "+++ ")); path != "/dev/null" {
+				return path
+			}
+			if oldPath != "" && oldPath != "/dev/null" {
+				return oldPath
+			}
+		}
+	}
+
+	// 二进制文件或纯模式变更没有 ---/+++ 行，退回解析首行 "diff --git a/x b/x"
+	if idx := strings.LastIndex(header, " b/"); idx >= 0 {
+		return header[idx+3:]
+	}
+	return strings.TrimPrefix(header, "diff --git ")
+}
+
+func trimDiffPrefix(path string) string {
+	path = strings.TrimSpace(path)
+	if i := strings.IndexByte(path, '\t'); i >= 0 {
+		path = path[:i]
+	}
+	for _, prefix := range []string{"a/", "b/"} {
+		if strings.HasPrefix(path, prefix) {
+			return strings.TrimPrefix(path, prefix)
+		}
+	}
+	return path
+}
+
+// LineRange 新文件中的行号区间（闭区间）
+type LineRange struct {
+	Start int
+	End   int
+}
+
+// ChangedRanges 解析 hunk，返回新文件中发生变更的行号区间；纯删除的位置按删除点所在行计算
+func (f FileDiff) ChangedRanges() []LineRange {
+	var ranges []LineRange
+	add := func(line int) {
+		if line < 1 {
+			line = 1
+		}
+		if n := len(ranges); n > 0 && line <= ranges[n-1].End+1 {
+			if line > ranges[n-1].End {
+				ranges[n-1].End = line
+			}
+			return
+		}
+		ranges = append(ranges, LineRange{Start: line, End: line})
+	}
+
+	newLine, inHunk := 0, false
+	for _, line := range strings.Split(f.Content, "\n") {
+		if strings.HasPrefix(line, "@@") {
+			if start, err := strconv.Atoi(hunkNewStart(line)); err == nil {
+				newLine = start
+				inHunk = true
+			}
+			continue
+		}
+		if !inHunk || line == "" {
+			continue
+		}
+		switch line[0] {
+		case '+':
+			add(newLine)
+			newLine++
+		case '-':
+			add(newLine)
+		case ' ':
+			newLine++
+		}
+	}
+	return ranges
+}
+
+// hunkNewStart 从 "@@ -a,b +c,d @@" 中取出 c
+func hunkNewStart(header string) string {
+	idx := strings.Index(header, " +")
+	if idx < 0 {
+		return ""
+	}
+	rest := header[idx+2:]
+	if end := strings.IndexAny(rest, ", "); end >= 0 {
+		rest = rest[:end]
+	}
+	return rest
+}
+
+// Numbered 在 hunk 中的新增行和上下文行前标注新文件中的行号，删除行留空，
+// 便于模型在结构化输出中给出准确的行号
+func (f FileDiff) Numbered() string {
+	var b strings.Builder
+	newLine, inHunk := 0, false
+	for _, line := range strings.Split(strings.TrimSuffix(f.Content, "\n"), "\n") {
+		if strings.HasPrefix(line, "@@") {
+			if start, err := strconv.Atoi(hunkNewStart(line)); err == nil {
+				newLine = start
+				inHunk = true
+			}
+			b.WriteString(line)
+			b.WriteString("\n")
+			continue
+		}
+		if !inHunk || line == "" {
+			b.WriteString(line)
+			b.WriteString("\n")
+			continue
+		}
+		switch line[0] {
+		case '+', ' ':
+			fmt.Fprintf(&b, "%5d %s\n", newLine, line)
+			newLine++
+		case '-':
+			fmt.Fprintf(&b, "%5s %s\n", "", line)
+		default:
+			b.WriteString(line)
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,157 @@
+package gitutil
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitDiff(t *testing.T) {
+	tests := []struct {
+		name  string
+		diff  string
+		paths []string
+	}{
+		{name: "空 diff", diff: ""},
+		{
+			name: "修改和新增文件",
+			diff: "diff --git a/a.go b/a.go\n--- a/a.go\n+++ b/a.go\n@@ -1 +1 @@\n-x\n+y\n" +
+				"diff --git a/new.go b/new.go\nnew file mode 100644\n--- /dev/null\n+++ b/new.go\n@@ -0,0 +1 @@\n+package a\n",
+			paths: []string{"a.go", "new.go"},
+		},
+		{
+			name:  "删除文件使用 --- 行的路径",
+			diff:  "diff --git a/old.go b/old.go\ndeleted file mode 100644\n--- a/old.go\n+++ /dev/null\n@@ -1 +0,0 @@\n-package a\n",
+			paths: []string{"old.go"},
+		},
+		{
+			name:  "重命名使用新路径",
+			diff:  "diff --git a/x/old.go b/x/new.go\nsimilarity index 90%\nrename from x/old.go\nrename to x/new.go\n--- a/x/old.go\n+++ b/x/new.go\n@@ -1 +1 @@\n-a\n+b\n",
+			paths: []string{"x/new.go"},
+		},
+		{
+			name:  "二进制文件没有 ---/+++ 行",
+			diff:  "diff --git a/img/logo.png b/img/logo.png\nBinary files a/img/logo.png and b/img/logo.png differ\n",
+			paths: []string{"img/logo.png"},
+		},
+		{
+			name:  "路径中带制表符后缀",
+			diff:  "diff --git a/a b.txt b/a b.txt\n--- a/a b.txt\t\n+++ b/a b.txt\t\n@@ -1 +1 @@\n-x\n+y\n",
+			paths: []string{"a b.txt"},
+		},
+		{
+			name:  "内容中的 diff --git 不在行首",
+			diff:  "diff --git a/README.md b/README.md\n--- a/README.md\n+++ b/README.md\n@@ -1 +1 @@\n-x\n+ diff --git a/x b/x\n",
+			paths: []string{"README.md"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := SplitDiff(tt.diff)
+			var paths []string
+			var joined strings.Builder
+			for _, f := range files {
+				paths = append(paths, f.Path)
+				joined.WriteString(f.Content)
+			}
+			if !reflect.DeepEqual(paths, tt.paths) {
+				t.Errorf("paths = %q, want %q", paths, tt.paths)
+			}
+			if joined.String() != tt.diff {
+				t.Error("拆分后的内容拼接起来应与原 diff 相同")
+			}
+		})
+	}
+}
+
+func TestChangedRanges(t *testing.T) {
+	tests := []struct {
+		name string
+		diff string
+		want []LineRange
+	}{
+		{
+			name: "连续的新增行合并为一个区间",
+			diff: "@@ -10,3 +10,5 @@\n a\n+b\n+c\n d\n+e\n",
+			want: []LineRange{{11, 12}, {14, 14}},
+		},
+		{
+			name: "修改行",
+			diff: "@@ -3,3 +3,3 @@\n a\n-b\n+B\n c\n",
+			want: []LineRange{{4, 4}},
+		},
+		{
+			name: "纯删除按删除点所在行计算",
+			diff: "@@ -5,3 +5,2 @@\n a\n-b\n c\n",
+			want: []LineRange{{6, 6}},
+		},
+		{
+			name: "删除文件开头的行号为 0 时取 1",
+			diff: "@@ -1,2 +0,0 @@\n-a\n-b\n",
+			want: []LineRange{{1, 1}},
+		},
+		{
+			name: "省略行数的 hunk 头",
+			diff: "@@ -7 +7 @@ func f() {\n-a\n+b\n",
+			want: []LineRange{{7, 7}},
+		},
+		{
+			name: "多个 hunk",
+			diff: "@@ -1,2 +1,3 @@\n a\n+b\n c\n@@ -20,2 +21,2 @@\n x\n-y\n+Y\n",
+			want: []LineRange{{2, 2}, {22, 22}},
+		},
+		{
+			name: "忽略 hunk 之前的文件头和无换行标记",
+			diff: "diff --git a/a b/a\n--- a/a\n+++ b/a\n@@ -1 +1 @@\n-a\n+b\n\\ No newline at end of file\n",
+			want: []LineRange{{1, 1}},
+		},
+		{name: "无效的 hunk 头", diff: "@@ -1 +x @@\n+a\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FileDiff{Content: tt.diff}.ChangedRanges()
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNumbered(t *testing.T) {
+	tests := []struct {
+		name string
+		diff string
+		want string
+	}{
+		{
+			name: "新增和上下文行标注新文件行号，删除行留空",
+			diff: "--- a/a.go\n+++ b/a.go\n@@ -8,3 +8,3 @@ func f() {\n a\n-b\n+B\n c\n",
+			want: "--- a/a.go\n+++ b/a.go\n@@ -8,3 +8,3 @@ func f() {\n    8  a\n      -b\n    9 +B\n   10  c\n",
+		},
+		{
+			name: "新 hunk 重新计数",
+			diff: "@@ -1 +1 @@\n+a\n@@ -50 +99,2 @@\n+b\n x\n",
+			want: "@@ -1 +1 @@\n    1 +a\n@@ -50 +99,2 @@\n   99 +b\n  100  x\n",
+		},
+		{
+			name: "无换行标记原样保留",
+			diff: "@@ -1 +1 @@\n-a\n+b\n\\ No newline at end of file\n",
+			want: "@@ -1 +1 @@\n      -a\n    1 +b\n\\ No newline at end of file\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (FileDiff{Content: tt.diff}).Numbered(); got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalized(t *testing.T) {
+	a := FileDiff{Content: "diff --git a/a b/a\nindex 1111111..2222222 100644\n+x  \n"}
+	b := FileDiff{Content: "diff --git a/a b/a\nindex 3333333..4444444 100644\n+x\n"}
+	if a.Normalized() != b.Normalized() {
+		t.Errorf("只有 index 行和行尾空白不同时应相同:\n%q\n%q", a.Normalized(), b.Normalized())
+	}
+}
@@ -0,0 +1,191 @@
+package lint
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// parsers 各输出格式的解析函数
+var parsers = map[string]func([]byte) ([]Diagnostic, error){
+	"golangci":    parseGolangci,
+	"staticcheck": parseStaticcheck,
+	"eslint":      parseESLint,
+	"checkstyle":  parseCheckstyle,
+	"text":        parseText,
+}
+
+// Formats 返回支持的输出格式
+func Formats() []string {
+	var names []string
+	for name := range parsers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// parseGolangci 解析 golangci-lint run --out-format json 的输出
+func parseGolangci(data []byte) ([]Diagnostic, error) {
+	var out struct {
+		Issues []struct {
+			FromLinter string
+			Text       string
+			Severity   string
+			Pos        struct {
+				Filename string
+				Line     int
+				Column   int
+			}
+		}
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+	var diags []Diagnostic
+	for _, is := range out.Issues {
+		diags = append(diags, Diagnostic{
+			Source:   "golangci-lint",
+			Path:     is.Pos.Filename,
+			Line:     is.Pos.Line,
+			Column:   is.Pos.Column,
+			Severity: is.Severity,
+			Rule:     is.FromLinter,
+			Message:  is.Text,
+		})
+	}
+	return diags, nil
+}
+
+// parseStaticcheck 解析 staticcheck -f json 的输出，每行一个 JSON 对象
+func parseStaticcheck(data []byte) ([]Diagnostic, error) {
+	var diags []Diagnostic
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var d struct {
+			Code     string `json:"code"`
+			Severity string `json:"severity"`
+			Message  string `json:"message"`
+			Location struct {
+				File   string `json:"file"`
+				Line   int    `json:"line"`
+				Column int    `json:"column"`
+			} `json:"location"`
+		}
+		if err := json.Unmarshal(line, &d); err != nil {
+			return nil, err
+		}
+		diags = append(diags, Diagnostic{
+			Source:   "staticcheck",
+			Path:     d.Location.File,
+			Line:     d.Location.Line,
+			Column:   d.Location.Column,
+			Severity: d.Severity,
+			Rule:     d.Code,
+			Message:  d.Message,
+		})
+	}
+	return diags, scanner.Err()
+}
+
+// parseESLint 解析 eslint -f json 的输出
+func parseESLint(data []byte) ([]Diagnostic, error) {
+	var out []struct {
+		FilePath string `json:"filePath"`
+		Messages []struct {
+			RuleID   string `json:"ruleId"`
+			Severity int    `json:"severity"`
+			Message  string `json:"message"`
+			Line     int    `json:"line"`
+			Column   int    `json:"column"`
+		} `json:"messages"`
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+	var diags []Diagnostic
+	for _, f := range out {
+		for _, m := range f.Messages {
+			severity := "warning"
+			if m.Severity == 2 {
+				severity = "error"
+			}
+			diags = append(diags, Diagnostic{
+				Source:   "eslint",
+				Path:     f.FilePath,
+				Line:     m.Line,
+				Column:   m.Column,
+				Severity: severity,
+				Rule:     m.RuleID,
+				Message:  m.Message,
+			})
+		}
+	}
+	return diags, nil
+}
+
+// parseCheckstyle 解析 checkstyle XML 格式，多数 linter 都支持输出该格式
+func parseCheckstyle(data []byte) ([]Diagnostic, error) {
+	var out struct {
+		Files []struct {
+			Name   string `xml:"name,attr"`
+			Errors []struct {
+				Line     int    `xml:"line,attr"`
+				Column   int    `xml:"column,attr"`
+				Severity string `xml:"severity,attr"`
+				Message  string `xml:"message,attr"`
+				Source   string `xml:"source,attr"`
+			} `xml:"error"`
+		} `xml:"file"`
+	}
+	if err := xml.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+	var diags []Diagnostic
+	for _, f := range out.Files {
+		for _, e := range f.Errors {
+			diags = append(diags, Diagnostic{
+				Path:     f.Name,
+				Line:     e.Line,
+				Column:   e.Column,
+				Severity: e.Severity,
+				Rule:     e.Source,
+				Message:  e.Message,
+			})
+		}
+	}
+	return diags, nil
+}
+
+// textLineRe 匹配 "path:line:col: message" 或 "path:line: message"
+var textLineRe = regexp.MustCompile(`^(.+?):(\d+)(?::(\d+))?:\s*(.+)$`)
+
+// parseText 解析 go vet 等工具的文本输出，无法识别的行会被忽略
+func parseText(data []byte) ([]Diagnostic, error) {
+	var diags []Diagnostic
+	for _, line := range strings.Split(string(data), "\n") {
+		m := textLineRe.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil {
+			continue
+		}
+		n, _ := strconv.Atoi(m[2])
+		col, _ := strconv.Atoi(m[3])
+		diags = append(diags, Diagnostic{
+			Path:    strings.TrimPrefix(m[1], "./"),
+			Line:    n,
+			Column:  col,
+			Message: m[4],
+		})
+	}
+	return diags, nil
+}
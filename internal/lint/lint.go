@@ -0,0 +1,187 @@
+package lint
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"ai_code_reviewer/internal/finding"
+	"ai_code_reviewer/internal/gitutil"
+)
+
+// Linter 一个可由 acr 执行的静态分析工具
+type Linter struct {
+	Name    string `mapstructure:"name"`
+	Command string `mapstructure:"command"` // 在仓库根目录通过 sh -c 执行
+	Format  string `mapstructure:"format"`  // 输出格式，见 Formats
+}
+
+// Diagnostic 一条静态分析诊断，Path 为相对仓库根目录的路径
+type Diagnostic struct {
+	Source   string // 产生诊断的工具
+	Path     string
+	Line     int
+	Column   int
+	Severity string // error、warning、info
+	Rule     string
+	Message  string
+}
+
+// Run 在仓库根目录执行 linter 并解析输出。大多数 linter 发现问题时以非零状态退出，
+// 因此只有在没有任何可解析输出时才把退出状态视为错误
+func Run(l Linter, top string) ([]Diagnostic, error) {
+	parse, ok := parsers[l.Format]
+	if !ok {
+		return nil, fmt.Errorf("linter %s 的 format 无效: %s，可选: %s", l.Name, l.Format, strings.Join(Formats(), "，"))
+	}
+
+	cmd := exec.Command("sh", "-c", l.Command)
+	cmd.Dir = top
+	var output []byte
+	var err error
+	if l.Format == "text" {
+		// go vet 等工具将诊断输出到标准错误
+		output, err = cmd.CombinedOutput()
+	} else {
+		output, err = cmd.Output()
+	}
+	if len(bytes.TrimSpace(output)) == 0 {
+		if err != nil {
+			return nil, fmt.Errorf("执行 %s 失败: %w", l.Name, err)
+		}
+		return nil, nil
+	}
+	diags, parseErr := parse(output)
+	if parseErr != nil {
+		if err != nil {
+			return nil, fmt.Errorf("执行 %s 失败: %w", l.Name, err)
+		}
+		return nil, fmt.Errorf("解析 %s 输出失败: %w", l.Name, parseErr)
+	}
+	return normalize(diags, l.Name, top), nil
+}
+
+// Load 读取 linter 输出文件并解析，source 为报告来源的名称
+func Load(format, path, source, top string) ([]Diagnostic, error) {
+	parse, ok := parsers[format]
+	if !ok {
+		return nil, fmt.Errorf("无效的报告格式: %s，可选: %s", format, strings.Join(Formats(), "，"))
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取报告 %s 失败: %w", path, err)
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, nil
+	}
+	diags, err := parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("解析报告 %s 失败: %w", path, err)
+	}
+	return normalize(diags, source, top), nil
+}
+
+// normalize 将路径转换为相对仓库根目录的形式并补齐来源
+func normalize(diags []Diagnostic, source, top string) []Diagnostic {
+	for i := range diags {
+		p := diags[i].Path
+		if filepath.IsAbs(p) {
+			if rel, err := filepath.Rel(top, p); err == nil {
+				p = rel
+			}
+		}
+		diags[i].Path = filepath.ToSlash(filepath.Clean(p))
+		if diags[i].Source == "" {
+			diags[i].Source = source
+		}
+		if diags[i].Severity == "" {
+			diags[i].Severity = "warning"
+		}
+	}
+	return diags
+}
+
+// OnChangedLines 只保留位于 diff 变更行上的诊断，按文件分组
+func OnChangedLines(diags []Diagnostic, files []gitutil.FileDiff) map[string][]Diagnostic {
+	ranges := make(map[string][]gitutil.LineRange, len(files))
+	for _, f := range files {
+		ranges[f.Path] = f.ChangedRanges()
+	}
+
+	kept := make(map[string][]Diagnostic)
+	seen := make(map[string]bool)
+	for _, d := range diags {
+		if !inRanges(d.Line, ranges[d.Path]) {
+			continue
+		}
+		// 多个工具（如 go vet 与 golangci-lint）可能报告同一问题
+		key := fmt.Sprintf("%s:%d:%s", d.Path, d.Line, d.Message)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		kept[d.Path] = append(kept[d.Path], d)
+	}
+	for _, list := range kept {
+		sort.SliceStable(list, func(i, j int) bool { return list[i].Line < list[j].Line })
+	}
+	return kept
+}
+
+func inRanges(line int, ranges []gitutil.LineRange) bool {
+	for _, r := range ranges {
+		if line >= r.Start && line <= r.End {
+			return true
+		}
+	}
+	return false
+}
+
+// Label 返回 "工具 规则" 形式的诊断标识
+func (d Diagnostic) Label() string {
+	if d.Rule == "" {
+		return d.Source
+	}
+	return d.Source + " " + d.Rule
+}
+
+// Format 将单个文件的诊断格式化为附加在 diff 之后的提示内容
+func Format(diags []Diagnostic) string {
+	if len(diags) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("静态分析工具在本文件的变更行上报告了以下问题。请判断每条是否为真实问题：" +
+		"确认的问题请结合代码解释原因和修复方式，误报请简要说明理由：\n\n")
+	for _, d := range diags {
+		fmt.Fprintf(&b, "- L%d [%s] %s: %s\n", d.Line, d.Label(), d.Severity, d.Message)
+	}
+	return b.String()
+}
+
+// Finding 将诊断转换为审查发现，用于补充模型没有覆盖的诊断
+func (d Diagnostic) Finding() finding.Finding {
+	severity := finding.SeverityLow
+	switch d.Severity {
+	case "error":
+		severity = finding.SeverityMedium
+	case "info":
+		severity = finding.SeverityInfo
+	}
+	category := d.Rule
+	if category == "" {
+		category = d.Source
+	}
+	return finding.Finding{
+		Path:     d.Path,
+		Line:     d.Line,
+		Severity: severity,
+		Category: category,
+		Title:    d.Message,
+		Source:   d.Source,
+	}
+}
@@ -0,0 +1,152 @@
+package lint
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"ai_code_reviewer/internal/gitutil"
+)
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		format string
+		file   string
+		want   []Diagnostic
+	}{
+		{
+			format: "golangci",
+			file:   "golangci.json",
+			want: []Diagnostic{
+				{Source: "golangci-lint", Path: "internal/store/store.go", Line: 42, Column: 9, Severity: "warning", Rule: "errcheck", Message: "Error return value of `f.Close` is not checked"},
+				{Source: "golangci-lint", Path: "cmd/main.go", Line: 7, Column: 2, Severity: "error", Rule: "govet", Message: "printf: fmt.Sprintf format %d has arg name of wrong type string"},
+			},
+		},
+		{
+			format: "staticcheck",
+			file:   "staticcheck.json",
+			want: []Diagnostic{
+				{Source: "staticcheck", Path: "internal/store/store.go", Line: 30, Column: 2, Severity: "error", Rule: "SA4006", Message: "this value of err is never used"},
+				{Source: "staticcheck", Path: "cmd/main.go", Line: 12, Column: 5, Severity: "warning", Rule: "S1002", Message: "should omit comparison to bool constant"},
+			},
+		},
+		{
+			format: "eslint",
+			file:   "eslint.json",
+			want: []Diagnostic{
+				{Source: "eslint", Path: "web/src/app.js", Line: 3, Column: 7, Severity: "error", Rule: "no-unused-vars", Message: "'tmp' is assigned a value but never used."},
+				{Source: "eslint", Path: "web/src/app.js", Line: 10, Column: 12, Severity: "warning", Rule: "eqeqeq", Message: "Expected '===' and instead saw '=='."},
+			},
+		},
+		{
+			format: "checkstyle",
+			file:   "checkstyle.xml",
+			want: []Diagnostic{
+				{Source: "report", Path: "src/main/java/shop/Cart.java", Line: 15, Column: 9, Severity: "warning", Rule: "com.puppycrawl.tools.checkstyle.checks.coding.HiddenFieldCheck", Message: "'total' hides a field."},
+				{Source: "report", Path: "src/main/java/shop/Cart.java", Line: 20, Severity: "error", Message: "Missing a Javadoc comment."},
+			},
+		},
+		{
+			format: "text",
+			file:   "govet.txt",
+			want: []Diagnostic{
+				{Source: "report", Path: "internal/store/store.go", Line: 42, Column: 9, Severity: "warning", Message: "unreachable code"},
+				{Source: "report", Path: "internal/store/store.go", Line: 50, Severity: "warning", Message: "result of fmt.Sprintf call not used"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			// 报告中的绝对路径按仓库根目录 /repo 转换为相对路径
+			got, err := Load(tt.format, filepath.Join("testdata", tt.file), "report", "/repo")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got  %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	if _, err := Load("sarif", filepath.Join("testdata", "eslint.json"), "report", "/repo"); err == nil {
+		t.Error("不支持的格式应返回错误")
+	}
+	if _, err := Load("eslint", filepath.Join(dir, "missing.json"), "report", "/repo"); err == nil {
+		t.Error("报告不存在时应返回错误")
+	}
+	if _, err := Load("golangci", write("bad.json", `{"Issues": [`), "report", "/repo"); err == nil {
+		t.Error("无法解析的报告应返回错误")
+	}
+	if _, err := Load("staticcheck", write("bad.ndjson", "{\"code\":\"SA1\"}\nnot json\n"), "report", "/repo"); err == nil {
+		t.Error("staticcheck 输出中有无法解析的行时应返回错误")
+	}
+	if diags, err := Load("checkstyle", write("empty.xml", "\n  \n"), "report", "/repo"); err != nil || diags != nil {
+		t.Errorf("空报告应没有诊断: %v, %v", diags, err)
+	}
+}
+
+func TestOnChangedLines(t *testing.T) {
+	files := []gitutil.FileDiff{
+		{Path: "a.go", Content: "@@ -10,2 +10,4 @@\n a\n+b\n+c\n d\n@@ -30 +32 @@\n-x\n+y\n"},
+		{Path: "b.go", Content: "@@ -1 +1 @@\n-x\n+y\n"},
+	}
+	diags := []Diagnostic{
+		{Source: "golangci-lint", Path: "a.go", Line: 32, Message: "unused value"},
+		{Source: "go vet", Path: "a.go", Line: 11, Message: "unreachable code"},
+		{Source: "golangci-lint", Path: "a.go", Line: 11, Rule: "govet", Message: "unreachable code"}, // 不同工具报告的同一问题
+		{Source: "staticcheck", Path: "a.go", Line: 11, Message: "ineffective assignment"},
+		{Source: "staticcheck", Path: "a.go", Line: 10, Message: "context line"},    // 上下文行
+		{Source: "staticcheck", Path: "a.go", Line: 13, Message: "context line"},    // 上下文行
+		{Source: "staticcheck", Path: "c.go", Line: 1, Message: "file not in diff"}, // 不在 diff 中的文件
+		{Source: "eslint", Path: "b.go", Line: 1, Message: "changed"},
+	}
+
+	got := OnChangedLines(diags, files)
+	want := map[string][]Diagnostic{
+		"a.go": {
+			{Source: "go vet", Path: "a.go", Line: 11, Message: "unreachable code"},
+			{Source: "staticcheck", Path: "a.go", Line: 11, Message: "ineffective assignment"},
+			{Source: "golangci-lint", Path: "a.go", Line: 32, Message: "unused value"},
+		},
+		"b.go": {
+			{Source: "eslint", Path: "b.go", Line: 1, Message: "changed"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got  %+v\nwant %+v", got, want)
+	}
+
+	if got := OnChangedLines(nil, files); len(got) != 0 {
+		t.Errorf("没有诊断时应为空: %+v", got)
+	}
+}
+
+func TestDiagnosticFinding(t *testing.T) {
+	tests := []struct {
+		d            Diagnostic
+		wantSeverity string
+		wantCategory string
+	}{
+		{d: Diagnostic{Source: "eslint", Severity: "error", Rule: "eqeqeq"}, wantSeverity: "medium", wantCategory: "eqeqeq"},
+		{d: Diagnostic{Source: "staticcheck", Severity: "warning"}, wantSeverity: "low", wantCategory: "staticcheck"},
+		{d: Diagnostic{Source: "go vet", Severity: "info"}, wantSeverity: "info", wantCategory: "go vet"},
+	}
+	for _, tt := range tests {
+		f := tt.d.Finding()
+		if f.Severity != tt.wantSeverity || f.Category != tt.wantCategory || f.Source != tt.d.Source {
+			t.Errorf("Finding(%+v) = %+v", tt.d, f)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"ai_code_reviewer/internal/config"
+
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+)
+
+// AzureName Azure OpenAI 服务提供方名称
+const AzureName = "azure"
+
+// Azure 认证方式
+const (
+	AzureAuthAPIKey = "api-key" // 在 api-key 请求头中发送 token
+	AzureAuthAAD    = "aad"     // 通过 Azure AD 客户端凭据流程获取 Bearer token
+)
+
+// AAD 客户端凭据使用的环境变量，与 Azure SDK 的约定一致
+const (
+	azureTenantEnv = "AZURE_TENANT_ID"
+	azureClientEnv = "AZURE_CLIENT_ID"
+	azureSecretEnv = "AZURE_CLIENT_SECRET"
+)
+
+// azureScope Azure OpenAI 的 AAD 权限范围
+const azureScope = "https://cognitiveservices.azure.com/.default"
+
+// Azure 基于部署的 Azure OpenAI 服务，请求格式与 OpenAI Chat Completions 相同
+type Azure struct {
+	OpenAI
+	resource string // 资源地址，不含末尾的 /
+}
+
+// NewAzure 创建 Azure OpenAI 服务提供方：url 为资源地址（如 https://xxx.openai.azure.com），
+// deployment 为空时使用 model 作为部署名
+func NewAzure(cfg *config.Config, client *http.Client) (*Azure, error) {
+	if cfg.Url == "" {
+		return nil, fmt.Errorf("azure 需要设置 url 为资源地址，如 https://<resource>.openai.azure.com")
+	}
+	deployment := cfg.Deployment
+	if deployment == "" {
+		deployment = cfg.Model
+	}
+	if deployment == "" {
+		return nil, fmt.Errorf("azure 需要设置 deployment 或 model")
+	}
+	if cfg.APIVersion == "" {
+		return nil, fmt.Errorf("azure 需要设置 api_version")
+	}
+
+	base := strings.TrimRight(cfg.Url, "/") + "/openai/deployments/" + url.PathEscape(deployment) + "/"
+	opts := []option.RequestOption{
+		option.WithBaseURL(base),
+		option.WithQueryAdd("api-version", cfg.APIVersion),
+		// 忽略 OPENAI_API_KEY 等环境变量带来的 OpenAI 认证头
+		option.WithHeaderDel("authorization"),
+	}
+	switch cfg.AuthMode {
+	case "", AzureAuthAPIKey:
+		if cfg.Token == "" {
+			return nil, fmt.Errorf("azure api-key 认证需要设置 token")
+		}
+		opts = append(opts, option.WithHeader("api-key", cfg.Token))
+	case AzureAuthAAD:
+		src, err := newAADTokenSource(client)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, option.WithMiddleware(src.middleware))
+	default:
+		return nil, fmt.Errorf("无效的 auth_mode: %s，可选: %s，%s", cfg.AuthMode, AzureAuthAPIKey, AzureAuthAAD)
+	}
+	if client != nil {
+		opts = append(opts, option.WithHTTPClient(client))
+	}
+	return &Azure{OpenAI: OpenAI{client: openai.NewClient(opts...)}, resource: strings.TrimRight(cfg.Url, "/")}, nil
+}
+
+func (p *Azure) Name() string {
+	return AzureName
+}
+
+// aadTokenSource 通过客户端凭据流程获取并缓存 AAD access token，过期前自动刷新
+type aadTokenSource struct {
+	tenant, clientID, secret string
+	client                   *http.Client // 与模型请求使用同一客户端，录制和回放时同样覆盖获取 token 的请求
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// newAADTokenSource 从环境变量读取客户端凭据，client 为 nil 时使用 http.DefaultClient
+func newAADTokenSource(client *http.Client) (*aadTokenSource, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	src := &aadTokenSource{
+		tenant:   os.Getenv(azureTenantEnv),
+		clientID: os.Getenv(azureClientEnv),
+		secret:   os.Getenv(azureSecretEnv),
+		client:   client,
+	}
+	if src.tenant == "" || src.clientID == "" || src.secret == "" {
+		return nil, fmt.Errorf("aad 认证需要设置环境变量 %s、%s 和 %s", azureTenantEnv, azureClientEnv, azureSecretEnv)
+	}
+	return src, nil
+}
+
+func (s *aadTokenSource) middleware(req *http.Request, next option.MiddlewareNext) (*http.Response, error) {
+	token, err := s.get(req)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return next(req)
+}
+
+// get 返回有效的 access token，距过期不足一分钟时重新获取
+func (s *aadTokenSource) get(req *http.Request) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token != "" && time.Until(s.expires) > time.Minute {
+		return s.token, nil
+	}
+
+	form := url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {s.clientID},
+		"client_secret": {s.secret},
+		"scope":         {azureScope},
+	}
+	endpoint := "https://login.microsoftonline.com/" + url.PathEscape(s.tenant) + "/oauth2/v2.0/token"
+	tokenReq, err := http.NewRequestWithContext(req.Context(), http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	tokenReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := s.client.Do(tokenReq)
+	if err != nil {
+		return "", fmt.Errorf("获取 AAD token 失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var out struct {
+		AccessToken      string `json:"access_token"`
+		ExpiresIn        int    `json:"expires_in"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return "", fmt.Errorf("解析 AAD token 响应失败: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK || out.AccessToken == "" {
+		return "", fmt.Errorf("获取 AAD token 失败（%d）: %s", resp.StatusCode, out.ErrorDescription)
+	}
+	s.token = out.AccessToken
+	s.expires = time.Now().Add(time.Duration(out.ExpiresIn) * time.Second)
+	return s.token, nil
+}
@@ -0,0 +1,79 @@
+package provider
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"ai_code_reviewer/internal/config"
+)
+
+// roundTripper 以函数实现 http.RoundTripper
+type roundTripper func(*http.Request) (*http.Response, error)
+
+func (f roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// jsonResponse 构造一个 200 的 JSON 响应
+func jsonResponse(body string) *http.Response {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+}
+
+func TestAzureAADUsesInjectedClient(t *testing.T) {
+	t.Setenv(azureTenantEnv, "tenant")
+	t.Setenv(azureClientEnv, "client")
+	t.Setenv(azureSecretEnv, "secret")
+
+	var tokenRequests, completions int
+	client := &http.Client{Transport: roundTripper(func(req *http.Request) (*http.Response, error) {
+		switch {
+		case req.URL.Host == "login.microsoftonline.com":
+			if req.URL.Path != "/tenant/oauth2/v2.0/token" {
+				t.Errorf("token 请求路径 = %s", req.URL.Path)
+			}
+			tokenRequests++
+			return jsonResponse(`{"access_token": "tok", "expires_in": 3600}`), nil
+		case strings.HasSuffix(req.URL.Path, "/openai/deployments/gpt-4o/chat/completions"):
+			if got := req.Header.Get("Authorization"); got != "Bearer tok" {
+				t.Errorf("Authorization = %q", got)
+			}
+			completions++
+			return jsonResponse(`{"id": "1", "object": "chat.completion", "model": "gpt-4o",
+				"choices": [{"index": 0, "finish_reason": "stop", "message": {"role": "assistant", "content": "ok"}}]}`), nil
+		}
+		t.Errorf("未预期的请求: %s", req.URL)
+		return nil, io.ErrUnexpectedEOF
+	})}
+
+	p, err := NewAzure(&config.Config{
+		Url:        "https://example.openai.azure.com",
+		Model:      "gpt-4o",
+		APIVersion: "2024-06-01",
+		AuthMode:   AzureAuthAAD,
+	}, client)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := Request{Model: "gpt-4o", Messages: []Message{{Role: RoleUser, Content: "hi"}}}
+	for i := 0; i < 2; i++ {
+		resp, err := p.Complete(context.Background(), req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.Content != "ok" {
+			t.Errorf("Content = %q", resp.Content)
+		}
+	}
+	// token 通过注入的客户端获取，且在有效期内复用
+	if tokenRequests != 1 || completions != 2 {
+		t.Errorf("token 请求 %d 次、对话请求 %d 次，want 1、2", tokenRequests, completions)
+	}
+}
@@ -0,0 +1,81 @@
+package provider
+
+import (
+	"context"
+	"os"
+	"sync"
+)
+
+// FakeName 离线假服务提供方的名称
+const FakeName = "fake"
+
+// FakeReplyEnv 指定假服务提供方回复内容的环境变量，值为文件路径
+const FakeReplyEnv = "ACR_FAKE_REPLY"
+
+// FakeDefaultReply 未指定回复时的默认内容，可被结构化审查解析为没有发现
+const FakeDefaultReply = `{"findings": []}`
+
+// Fake 不发出任何网络请求的服务提供方，按顺序返回预设的回复并记录收到的请求，
+// 用于离线验证审查、分块和输出流程
+type Fake struct {
+	mu       sync.Mutex
+	replies  []string
+	next     int
+	requests []Request
+}
+
+// NewFake 创建假服务提供方，回复用完后重复最后一条；没有回复时返回 FakeDefaultReply
+func NewFake(replies ...string) *Fake {
+	return &Fake{replies: replies}
+}
+
+// newFakeFromEnv 从 ACR_FAKE_REPLY 指定的文件读取回复
+func newFakeFromEnv() (*Fake, error) {
+	path := os.Getenv(FakeReplyEnv)
+	if path == "" {
+		return NewFake(), nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewFake(string(data)), nil
+}
+
+func (p *Fake) Name() string {
+	return FakeName
+}
+
+func (p *Fake) Complete(ctx context.Context, req Request) (*Response, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.requests = append(p.requests, req)
+
+	reply := FakeDefaultReply
+	if len(p.replies) > 0 {
+		reply = p.replies[min(p.next, len(p.replies)-1)]
+		p.next++
+	}
+	return &Response{Content: reply}, nil
+}
+
+func (p *Fake) Stream(ctx context.Context, req Request, onDelta func(string)) (*Response, error) {
+	resp, err := p.Complete(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if onDelta != nil {
+		onDelta(resp.Content)
+	}
+	return resp, nil
+}
+
+// Requests 返回已收到的请求
+func (p *Fake) Requests() []Request {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]Request(nil), p.requests...)
+}
@@ -0,0 +1,208 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"ai_code_reviewer/internal/usage"
+
+	"github.com/openai/openai-go"
+)
+
+// 触发切换到下一个模型的错误类型
+const (
+	FailRateLimit     = "rate_limit"     // 429 请求过多
+	FailServerError   = "server_error"   // 5xx 服务端错误
+	FailContextLength = "context_length" // 请求超出模型的上下文长度
+	FailTimeout       = "timeout"        // 请求超时
+)
+
+// FailoverReasons 支持的错误类型，也是 fallback_on 的默认值
+var FailoverReasons = []string{FailRateLimit, FailServerError, FailContextLength, FailTimeout}
+
+// Candidate 回退链中的一个模型
+type Candidate struct {
+	Model    string
+	Provider Provider
+}
+
+// Failover 一次模型切换
+type Failover struct {
+	From   string
+	To     string
+	Reason string
+}
+
+// Served 某个模型处理的请求数和用量
+type Served struct {
+	Model    string
+	Provider string
+	Requests int // 发出的请求数，包括失败的请求
+	Replies  int // 成功给出回复的请求数
+	Usage    usage.Usage
+}
+
+// Fallback 按顺序尝试回退链中的模型，前一个模型因 on 中列出的错误失败时改用下一个。
+// 回退模型给出的回复在 Response.Model 中标明实际使用的模型
+type Fallback struct {
+	chain   []Candidate
+	on      []string
+	timeout time.Duration // 单个请求的超时时间，为 0 时不限制
+
+	mu        sync.Mutex
+	served    []Served
+	failovers []Failover
+}
+
+// NewFallback 创建回退链，chain 的第一个为主模型，on 为空时对所有支持的错误类型切换
+func NewFallback(chain []Candidate, on []string, timeout time.Duration) *Fallback {
+	if len(on) == 0 {
+		on = FailoverReasons
+	}
+	served := make([]Served, len(chain))
+	for i, c := range chain {
+		served[i] = Served{Model: c.Model, Provider: c.Provider.Name()}
+	}
+	return &Fallback{chain: chain, on: on, timeout: timeout, served: served}
+}
+
+// Name 返回主模型的服务提供方名称，缓存键与未配置回退时保持一致
+func (p *Fallback) Name() string {
+	return p.chain[0].Provider.Name()
+}
+
+func (p *Fallback) Complete(ctx context.Context, req Request) (*Response, error) {
+	return p.try(ctx, req, func(ctx context.Context, c Candidate, req Request) (*Response, bool, error) {
+		resp, err := c.Provider.Complete(ctx, req)
+		return resp, false, err
+	})
+}
+
+// Stream 只在尚未收到任何输出时切换模型，已输出部分内容后失败则直接返回错误
+func (p *Fallback) Stream(ctx context.Context, req Request, onDelta func(string)) (*Response, error) {
+	return p.try(ctx, req, func(ctx context.Context, c Candidate, req Request) (*Response, bool, error) {
+		started := false
+		resp, err := c.Provider.Stream(ctx, req, func(delta string) {
+			started = true
+			if onDelta != nil {
+				onDelta(delta)
+			}
+		})
+		return resp, started, err
+	})
+}
+
+// try 依次使用回退链中的模型发送请求，attempt 返回的 started 为 true 时不再切换
+func (p *Fallback) try(ctx context.Context, req Request, attempt func(context.Context, Candidate, Request) (*Response, bool, error)) (*Response, error) {
+	for i, c := range p.chain {
+		call := req
+		if i > 0 {
+			call.Model = c.Model
+		}
+
+		attemptCtx, cancel := ctx, context.CancelFunc(func() {})
+		if p.timeout > 0 {
+			attemptCtx, cancel = context.WithTimeout(ctx, p.timeout)
+		}
+		resp, started, err := attempt(attemptCtx, c, call)
+		cancel()
+		p.record(i, resp, err == nil)
+
+		if err == nil {
+			if i > 0 && resp != nil {
+				resp.Model = c.Model
+			}
+			return resp, nil
+		}
+		// 调用方取消或已经输出部分内容时不再切换
+		if ctx.Err() != nil || started || i == len(p.chain)-1 {
+			return resp, err
+		}
+		reason := Classify(err)
+		if !slices.Contains(p.on, reason) {
+			return resp, err
+		}
+		p.mu.Lock()
+		p.failovers = append(p.failovers, Failover{From: call.Model, To: p.chain[i+1].Model, Reason: reason})
+		p.mu.Unlock()
+	}
+	return nil, fmt.Errorf("回退链为空")
+}
+
+// record 累计模型处理的请求数和用量，失败的请求同样计入
+func (p *Fallback) record(i int, resp *Response, ok bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.served[i].Requests++
+	if ok {
+		p.served[i].Replies++
+	}
+	if resp != nil {
+		p.served[i].Usage.Add(resp.Usage)
+	}
+}
+
+// Served 按回退链顺序返回发出过请求的模型
+func (p *Fallback) Served() []Served {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var list []Served
+	for _, s := range p.served {
+		if s.Requests > 0 {
+			list = append(list, s)
+		}
+	}
+	return list
+}
+
+// Failovers 返回发生过的模型切换
+func (p *Fallback) Failovers() []Failover {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]Failover(nil), p.failovers...)
+}
+
+// Classify 判断错误类型，不属于任何支持的类型时返回空字符串
+func Classify(err error) string {
+	var apiErr *openai.Error
+	if errors.As(err, &apiErr) {
+		switch {
+		case apiErr.Code == "context_length_exceeded" || isContextLengthMessage(apiErr.Message):
+			return FailContextLength
+		case apiErr.StatusCode == http.StatusTooManyRequests:
+			return FailRateLimit
+		case apiErr.StatusCode == http.StatusRequestTimeout:
+			return FailTimeout
+		case apiErr.StatusCode >= 500:
+			return FailServerError
+		}
+		return ""
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return FailTimeout
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return FailTimeout
+	}
+	if isContextLengthMessage(err.Error()) {
+		return FailContextLength
+	}
+	return ""
+}
+
+// isContextLengthMessage 兼容接口的网关不一定返回错误码，按错误信息判断是否超出上下文长度
+func isContextLengthMessage(msg string) bool {
+	msg = strings.ToLower(msg)
+	return strings.Contains(msg, "context_length_exceeded") ||
+		strings.Contains(msg, "maximum context length") ||
+		strings.Contains(msg, "context window")
+}
@@ -0,0 +1,220 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/openai/openai-go"
+)
+
+// apiError 构造接口返回的错误，Request 和 Response 供 Error() 使用
+func apiError(status int, code, message string) error {
+	return &openai.Error{
+		Code:       code,
+		Message:    message,
+		StatusCode: status,
+		Request:    &http.Request{Method: http.MethodPost, URL: &url.URL{Path: "/chat/completions"}},
+		Response:   &http.Response{StatusCode: status},
+	}
+}
+
+// failing 总是失败的服务提供方，block 为 true 时等到请求超时或被取消才返回
+type failing struct {
+	err   error
+	block bool
+	delta string // Stream 失败前已输出的内容
+	calls int
+}
+
+func (p *failing) Name() string { return "failing" }
+
+func (p *failing) Complete(ctx context.Context, req Request) (*Response, error) {
+	p.calls++
+	if p.block {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return nil, p.err
+}
+
+func (p *failing) Stream(ctx context.Context, req Request, onDelta func(string)) (*Response, error) {
+	if p.delta != "" && onDelta != nil {
+		onDelta(p.delta)
+	}
+	return p.Complete(ctx, req)
+}
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{name: "429", err: apiError(429, "rate_limit_exceeded", "Rate limit reached"), want: FailRateLimit},
+		{name: "500", err: apiError(500, "", "internal error"), want: FailServerError},
+		{name: "503", err: apiError(503, "", "overloaded"), want: FailServerError},
+		{name: "408", err: apiError(408, "", "timeout"), want: FailTimeout},
+		{name: "上下文超长错误码", err: apiError(400, "context_length_exceeded", "too long"), want: FailContextLength},
+		{name: "上下文超长错误信息", err: apiError(400, "", "This model's maximum context length is 8192 tokens"), want: FailContextLength},
+		{name: "包装后的接口错误", err: fmt.Errorf("审查失败: %w", apiError(502, "", "bad gateway")), want: FailServerError},
+		{name: "401 认证失败", err: apiError(401, "invalid_api_key", "Incorrect API key provided")},
+		{name: "403 无权限", err: apiError(403, "", "forbidden")},
+		{name: "404 模型不存在", err: apiError(404, "model_not_found", "The model does not exist")},
+		{name: "400 参数错误", err: apiError(400, "invalid_request_error", "bad temperature")},
+		{name: "请求超时", err: fmt.Errorf("请求失败: %w", context.DeadlineExceeded), want: FailTimeout},
+		{name: "网络超时", err: &net.DNSError{Err: "i/o timeout", Name: "api.example.com", IsTimeout: true}, want: FailTimeout},
+		{name: "网关返回的超长信息", err: errors.New("prompt exceeds the context window"), want: FailContextLength},
+		{name: "调用方取消", err: context.Canceled},
+		{name: "连接被拒绝", err: errors.New("dial tcp: connection refused")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Classify(tt.err); got != tt.want {
+				t.Errorf("Classify = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFallbackFailover(t *testing.T) {
+	tests := []struct {
+		name         string
+		err          error
+		on           []string
+		wantFailover string // 期望的切换原因，为空表示不切换，直接返回错误
+	}{
+		{name: "429 切换", err: apiError(429, "", "slow down"), wantFailover: FailRateLimit},
+		{name: "5xx 切换", err: apiError(503, "", "unavailable"), wantFailover: FailServerError},
+		{name: "上下文超长切换", err: apiError(400, "context_length_exceeded", "too long"), wantFailover: FailContextLength},
+		{name: "超时切换", err: context.DeadlineExceeded, wantFailover: FailTimeout},
+		{name: "认证失败直接返回", err: apiError(401, "invalid_api_key", "bad key")},
+		{name: "无权限直接返回", err: apiError(403, "", "forbidden")},
+		{name: "未知错误直接返回", err: errors.New("boom")},
+		{name: "不在 fallback_on 中的错误直接返回", err: apiError(429, "", "slow down"), on: []string{FailTimeout}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			primary := &failing{err: tt.err}
+			backup := NewFake("from backup")
+			fb := NewFallback([]Candidate{{Model: "primary", Provider: primary}, {Model: "backup", Provider: backup}}, tt.on, 0)
+
+			resp, err := fb.Complete(context.Background(), Request{Model: "primary"})
+			if tt.wantFailover == "" {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("应直接返回原错误，got %v", err)
+				}
+				if len(backup.Requests()) != 0 || len(fb.Failovers()) != 0 {
+					t.Errorf("不应切换到回退模型")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.Content != "from backup" || resp.Model != "backup" {
+				t.Errorf("resp = %+v", resp)
+			}
+			if reqs := backup.Requests(); len(reqs) != 1 || reqs[0].Model != "backup" {
+				t.Errorf("回退模型收到的请求 = %+v", reqs)
+			}
+			want := []Failover{{From: "primary", To: "backup", Reason: tt.wantFailover}}
+			if got := fb.Failovers(); !reflect.DeepEqual(got, want) {
+				t.Errorf("failovers = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestFallbackOrder(t *testing.T) {
+	first := &failing{err: apiError(429, "", "slow down")}
+	second := &failing{err: apiError(500, "", "oops")}
+	third := NewFake("ok")
+	fourth := NewFake("unused")
+	fb := NewFallback([]Candidate{
+		{Model: "a", Provider: first},
+		{Model: "b", Provider: second},
+		{Model: "c", Provider: third},
+		{Model: "d", Provider: fourth},
+	}, nil, 0)
+
+	resp, err := fb.Complete(context.Background(), Request{Model: "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Model != "c" || first.calls != 1 || second.calls != 1 || len(fourth.Requests()) != 0 {
+		t.Errorf("应按顺序尝试到 c 为止: resp = %+v, calls = %d %d %d", resp, first.calls, second.calls, len(fourth.Requests()))
+	}
+
+	wantFailovers := []Failover{{From: "a", To: "b", Reason: FailRateLimit}, {From: "b", To: "c", Reason: FailServerError}}
+	if got := fb.Failovers(); !reflect.DeepEqual(got, wantFailovers) {
+		t.Errorf("failovers = %+v, want %+v", got, wantFailovers)
+	}
+	var served []string
+	for _, s := range fb.Served() {
+		served = append(served, fmt.Sprintf("%s:%d/%d", s.Model, s.Replies, s.Requests))
+	}
+	if want := []string{"a:0/1", "b:0/1", "c:1/1"}; !reflect.DeepEqual(served, want) {
+		t.Errorf("served = %v, want %v", served, want)
+	}
+
+	// 主模型给出回复时不标注实际模型
+	fb = NewFallback([]Candidate{{Model: "a", Provider: NewFake("ok")}, {Model: "b", Provider: fourth}}, nil, 0)
+	if resp, err := fb.Complete(context.Background(), Request{Model: "a"}); err != nil || resp.Model != "" {
+		t.Errorf("resp = %+v, err = %v", resp, err)
+	}
+}
+
+func TestFallbackLastError(t *testing.T) {
+	last := apiError(503, "", "still down")
+	fb := NewFallback([]Candidate{
+		{Model: "a", Provider: &failing{err: apiError(429, "", "slow down")}},
+		{Model: "b", Provider: &failing{err: last}},
+	}, nil, 0)
+	if _, err := fb.Complete(context.Background(), Request{Model: "a"}); !errors.Is(err, last) {
+		t.Errorf("全部失败时应返回最后一个模型的错误，got %v", err)
+	}
+}
+
+func TestFallbackTimeout(t *testing.T) {
+	backup := NewFake("ok")
+	fb := NewFallback([]Candidate{{Model: "a", Provider: &failing{block: true}}, {Model: "b", Provider: backup}}, nil, 10*time.Millisecond)
+	resp, err := fb.Complete(context.Background(), Request{Model: "a"})
+	if err != nil || resp.Model != "b" {
+		t.Fatalf("单个请求超时后应切换: resp = %+v, err = %v", resp, err)
+	}
+
+	// 调用方取消时不再切换
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	fb = NewFallback([]Candidate{{Model: "a", Provider: &failing{block: true}}, {Model: "b", Provider: backup}}, nil, 0)
+	if _, err := fb.Complete(ctx, Request{Model: "a"}); !errors.Is(err, context.Canceled) || len(fb.Failovers()) != 0 {
+		t.Errorf("调用方取消时应直接返回: err = %v", err)
+	}
+}
+
+func TestFallbackStream(t *testing.T) {
+	// 尚未输出内容时切换
+	backup := NewFake("streamed")
+	fb := NewFallback([]Candidate{{Model: "a", Provider: &failing{err: apiError(500, "", "oops")}}, {Model: "b", Provider: backup}}, nil, 0)
+	var out string
+	resp, err := fb.Stream(context.Background(), Request{Model: "a"}, func(d string) { out += d })
+	if err != nil || resp.Model != "b" || out != "streamed" {
+		t.Errorf("resp = %+v, out = %q, err = %v", resp, out, err)
+	}
+
+	// 已输出部分内容后失败，不再切换
+	backup = NewFake("streamed")
+	fb = NewFallback([]Candidate{{Model: "a", Provider: &failing{err: apiError(500, "", "oops"), delta: "partial"}}, {Model: "b", Provider: backup}}, nil, 0)
+	out = ""
+	if _, err := fb.Stream(context.Background(), Request{Model: "a"}, func(d string) { out += d }); err == nil || out != "partial" || len(backup.Requests()) != 0 {
+		t.Errorf("已输出内容后不应切换: out = %q, err = %v", out, err)
+	}
+}
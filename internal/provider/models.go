@@ -0,0 +1,115 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+)
+
+// ollamaPort Ollama 的默认端口，该端口上的服务直接使用 /api/tags 列出模型
+const ollamaPort = "11434"
+
+// RemoteModel 服务端返回的模型信息
+type RemoteModel struct {
+	ID      string
+	OwnedBy string // OpenAI 接口返回的所属组织
+	Size    int64  // Ollama 本地模型文件的大小（字节）
+	Details string // Ollama 模型的参数量和量化方式
+}
+
+// ModelLister 能够列出可用模型的服务提供方
+type ModelLister interface {
+	// ListModels 返回服务端可用的模型，按 ID 排序
+	ListModels(ctx context.Context) ([]RemoteModel, error)
+}
+
+// ListModels 调用 /models 接口；地址指向 Ollama 或 /models 不存在时改用 Ollama 的 /api/tags
+func (p *OpenAI) ListModels(ctx context.Context) ([]RemoteModel, error) {
+	if isOllama(p.baseURL) {
+		return p.ollamaTags(ctx)
+	}
+	models, err := listModels(ctx, p)
+	var apiErr *openai.Error
+	if err != nil && p.baseURL != "" && errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		if tags, tagErr := p.ollamaTags(ctx); tagErr == nil {
+			return tags, nil
+		}
+	}
+	return models, err
+}
+
+// ListModels 调用资源的 /openai/models 接口，返回资源可用的基础模型而不是部署名
+func (p *Azure) ListModels(ctx context.Context) ([]RemoteModel, error) {
+	return listModels(ctx, &p.OpenAI, option.WithBaseURL(p.resource+"/openai/"))
+}
+
+func listModels(ctx context.Context, p *OpenAI, opts ...option.RequestOption) ([]RemoteModel, error) {
+	iter := p.client.Models.ListAutoPaging(ctx, opts...)
+	var models []RemoteModel
+	for iter.Next() {
+		m := iter.Current()
+		models = append(models, RemoteModel{ID: m.ID, OwnedBy: m.OwnedBy})
+	}
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+	sortModels(models)
+	return models, nil
+}
+
+// ollamaTags 调用 Ollama 的 /api/tags 接口，地址为 url 去掉末尾的 /v1
+func (p *OpenAI) ollamaTags(ctx context.Context) ([]RemoteModel, error) {
+	var out struct {
+		Models []struct {
+			Name    string `json:"name"`
+			Size    int64  `json:"size"`
+			Details struct {
+				ParameterSize     string `json:"parameter_size"`
+				QuantizationLevel string `json:"quantization_level"`
+			} `json:"details"`
+		} `json:"models"`
+	}
+	root := strings.TrimSuffix(strings.TrimRight(p.baseURL, "/"), "/v1") + "/"
+	if err := p.client.Get(ctx, "api/tags", nil, &out, option.WithBaseURL(root)); err != nil {
+		return nil, fmt.Errorf("读取 Ollama 模型列表失败: %w", err)
+	}
+	models := make([]RemoteModel, 0, len(out.Models))
+	for _, m := range out.Models {
+		var details []string
+		for _, d := range []string{m.Details.ParameterSize, m.Details.QuantizationLevel} {
+			if d != "" {
+				details = append(details, d)
+			}
+		}
+		models = append(models, RemoteModel{ID: m.Name, Size: m.Size, Details: strings.Join(details, " ")})
+	}
+	sortModels(models)
+	return models, nil
+}
+
+// isOllama 根据端口判断地址是否指向 Ollama
+func isOllama(baseURL string) bool {
+	u, err := url.Parse(baseURL)
+	return err == nil && u.Port() == ollamaPort
+}
+
+func sortModels(models []RemoteModel) {
+	sort.Slice(models, func(i, j int) bool { return models[i].ID < models[j].ID })
+}
+
+// HasModel 判断模型 ID 是否在列表中，忽略大小写；Ollama 模型省略 :latest 标签时同样匹配
+func HasModel(models []RemoteModel, model string) bool {
+	for _, m := range models {
+		if strings.EqualFold(m.ID, model) || strings.EqualFold(m.ID, model+":latest") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,193 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"ai_code_reviewer/internal/config"
+	"ai_code_reviewer/internal/usage"
+
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+	"github.com/openai/openai-go/shared"
+)
+
+// OpenAIName OpenAI 及兼容接口的服务提供方名称
+const OpenAIName = "openai"
+
+// OpenAI 基于 OpenAI Chat Completions 接口的服务提供方，也适用于兼容该接口的网关
+type OpenAI struct {
+	client  openai.Client
+	baseURL string // 配置的服务地址，为空表示官方地址
+	params  config.ModelParams
+}
+
+// NewOpenAI 创建 OpenAI 服务提供方，baseURL 为空时使用官方地址，client 为空时使用默认的 HTTP 客户端
+func NewOpenAI(token, baseURL string, client *http.Client) *OpenAI {
+	opts := []option.RequestOption{option.WithAPIKey(token)}
+	if baseURL != "" {
+		opts = append(opts, option.WithBaseURL(baseURL))
+	}
+	if client != nil {
+		opts = append(opts, option.WithHTTPClient(client))
+	}
+	return &OpenAI{client: openai.NewClient(opts...), baseURL: baseURL}
+}
+
+// SetParams 设置每个请求附带的生成参数
+func (p *OpenAI) SetParams(params config.ModelParams) {
+	p.params = params
+}
+
+func (p *OpenAI) Name() string {
+	return OpenAIName
+}
+
+func (p *OpenAI) Complete(ctx context.Context, req Request) (*Response, error) {
+	completion, err := p.client.Chat.Completions.New(ctx, p.request(req))
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &Response{Usage: convertUsage(completion.Usage)}
+	if len(completion.Choices) == 0 {
+		return resp, fmt.Errorf("模型未返回任何结果")
+	}
+	msg := completion.Choices[0].Message
+	resp.Content = msg.Content
+	for _, tc := range msg.ToolCalls {
+		resp.ToolCalls = append(resp.ToolCalls, ToolCall{
+			ID:        tc.ID,
+			Name:      tc.Function.Name,
+			Arguments: tc.Function.Arguments,
+		})
+	}
+	return resp, nil
+}
+
+func (p *OpenAI) Stream(ctx context.Context, req Request, onDelta func(string)) (*Response, error) {
+	params := p.request(req)
+	params.StreamOptions = openai.ChatCompletionStreamOptionsParam{
+		IncludeUsage: openai.Bool(true),
+	}
+
+	stream := p.client.Chat.Completions.NewStreaming(ctx, params)
+	defer stream.Close()
+
+	acc := openai.ChatCompletionAccumulator{}
+	var content strings.Builder
+	resp := &Response{}
+	for stream.Next() {
+		chunk := stream.Current()
+		acc.AddChunk(chunk)
+
+		if len(chunk.Choices) > 0 && chunk.Choices[0].Delta.Content != "" {
+			delta := chunk.Choices[0].Delta.Content
+			content.WriteString(delta)
+			if onDelta != nil {
+				onDelta(delta)
+			}
+		}
+		// 最后一个分片携带整个请求的用量
+		if chunk.Usage.TotalTokens > 0 {
+			resp.Usage = convertUsage(chunk.Usage)
+		}
+	}
+	resp.Content = content.String()
+	if err := stream.Err(); err != nil {
+		return resp, err
+	}
+
+	if len(acc.Choices) > 0 {
+		for _, tc := range acc.Choices[0].Message.ToolCalls {
+			resp.ToolCalls = append(resp.ToolCalls, ToolCall{
+				ID:        tc.ID,
+				Name:      tc.Function.Name,
+				Arguments: tc.Function.Arguments,
+			})
+		}
+	}
+	return resp, nil
+}
+
+// request 将通用请求转换为 OpenAI 请求参数
+func (p *OpenAI) request(req Request) openai.ChatCompletionNewParams {
+	messages := make([]openai.ChatCompletionMessageParamUnion, 0, len(req.Messages))
+	for _, m := range req.Messages {
+		switch m.Role {
+		case RoleSystem:
+			messages = append(messages, openai.SystemMessage(m.Content))
+		case RoleAssistant:
+			asst := openai.ChatCompletionAssistantMessageParam{}
+			if m.Content != "" {
+				asst.Content.OfString = openai.String(m.Content)
+			}
+			for _, tc := range m.ToolCalls {
+				asst.ToolCalls = append(asst.ToolCalls, openai.ChatCompletionMessageToolCallParam{
+					ID: tc.ID,
+					Function: openai.ChatCompletionMessageToolCallFunctionParam{
+						Name:      tc.Name,
+						Arguments: tc.Arguments,
+					},
+				})
+			}
+			messages = append(messages, openai.ChatCompletionMessageParamUnion{OfAssistant: &asst})
+		case RoleTool:
+			messages = append(messages, openai.ToolMessage(m.Content, m.ToolCallID))
+		default:
+			messages = append(messages, openai.UserMessage(m.Content))
+		}
+	}
+
+	params := openai.ChatCompletionNewParams{
+		Messages: messages,
+		Model:    req.Model,
+	}
+	p.applyParams(&params, req.Model)
+	for _, t := range req.Tools {
+		params.Tools = append(params.Tools, openai.ChatCompletionToolParam{
+			Function: shared.FunctionDefinitionParam{
+				Name:        t.Name,
+				Description: openai.String(t.Description),
+				Parameters:  shared.FunctionParameters(t.Parameters),
+			},
+		})
+	}
+	return params
+}
+
+// applyParams 设置生成参数，推理模型使用 max_completion_tokens 限制输出长度
+func (p *OpenAI) applyParams(params *openai.ChatCompletionNewParams, model string) {
+	if t := p.params.Temperature; t != nil {
+		params.Temperature = openai.Float(*t)
+	}
+	if t := p.params.TopP; t != nil {
+		params.TopP = openai.Float(*t)
+	}
+	if n := p.params.MaxOutputTokens; n > 0 {
+		if IsReasoningModel(model) || p.params.ReasoningEffort != "" {
+			params.MaxCompletionTokens = openai.Int(int64(n))
+		} else {
+			params.MaxTokens = openai.Int(int64(n))
+		}
+	}
+	if s := p.params.Seed; s != nil {
+		params.Seed = openai.Int(*s)
+	}
+	if len(p.params.Stop) > 0 {
+		params.Stop = openai.ChatCompletionNewParamsStopUnion{OfStringArray: p.params.Stop}
+	}
+	if e := p.params.ReasoningEffort; e != "" {
+		params.ReasoningEffort = shared.ReasoningEffort(e)
+	}
+}
+
+func convertUsage(u openai.CompletionUsage) usage.Usage {
+	return usage.Usage{
+		PromptTokens:     u.PromptTokens,
+		CompletionTokens: u.CompletionTokens,
+		CachedTokens:     u.PromptTokensDetails.CachedTokens,
+	}
+}
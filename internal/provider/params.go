@@ -0,0 +1,52 @@
+package provider
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"ai_code_reviewer/internal/config"
+)
+
+// reasoningPrefixes OpenAI 推理模型的名称前缀，这些模型不支持 temperature 和 top_p
+var reasoningPrefixes = []string{"o1", "o3", "o4", "gpt-5"}
+
+// IsReasoningModel 判断是否为 OpenAI 推理模型
+func IsReasoningModel(model string) bool {
+	model = strings.ToLower(model)
+	for _, p := range reasoningPrefixes {
+		if model == p || strings.HasPrefix(model, p+"-") {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateParams 检查模型参数的取值是否被服务提供方和模型支持
+func ValidateParams(providerName, model string, p config.ModelParams) error {
+	switch providerName {
+	case FakeName:
+		return nil
+	case OpenAIName, AzureName:
+		if p.Temperature != nil && (*p.Temperature < 0 || *p.Temperature > 2) {
+			return fmt.Errorf("temperature 应在 0 到 2 之间")
+		}
+		if p.TopP != nil && (*p.TopP < 0 || *p.TopP > 1) {
+			return fmt.Errorf("top_p 应在 0 到 1 之间")
+		}
+		if p.MaxOutputTokens < 0 {
+			return fmt.Errorf("max_output_tokens 应为正整数")
+		}
+		if len(p.Stop) > 4 {
+			return fmt.Errorf("%s 最多支持 4 个 stop 序列", providerName)
+		}
+		if p.ReasoningEffort != "" && !slices.Contains(config.ReasoningEfforts, p.ReasoningEffort) {
+			return fmt.Errorf("无效的 reasoning_effort: %s，可选: %s", p.ReasoningEffort, strings.Join(config.ReasoningEfforts, "，"))
+		}
+		if IsReasoningModel(model) && (p.Temperature != nil || p.TopP != nil) {
+			return fmt.Errorf("推理模型 %s 不支持 temperature 和 top_p", model)
+		}
+		return nil
+	}
+	return fmt.Errorf("不支持的 provider: %s", providerName)
+}
@@ -0,0 +1,151 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"ai_code_reviewer/internal/cassette"
+	"ai_code_reviewer/internal/config"
+	"ai_code_reviewer/internal/usage"
+)
+
+// 消息角色
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+	RoleTool      = "tool"
+)
+
+// Message 对话中的一条消息
+type Message struct {
+	Role       string     `json:"role"`
+	Content    string     `json:"content"`
+	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`   // assistant 消息发起的工具调用
+	ToolCallID string     `json:"tool_call_id,omitempty"` // tool 消息对应的调用 ID
+}
+
+// ToolCall 模型发起的一次工具调用
+type ToolCall struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"` // JSON 格式的参数
+}
+
+// Tool 提供给模型的工具定义
+type Tool struct {
+	Name        string
+	Description string
+	Parameters  map[string]any // JSON Schema
+}
+
+// Request 一次对话请求
+type Request struct {
+	Model    string
+	Messages []Message
+	Tools    []Tool
+}
+
+// Response 模型的一次回复
+type Response struct {
+	Content   string
+	ToolCalls []ToolCall
+	Usage     usage.Usage
+	Model     string // 实际给出回复的模型，为空表示请求中的模型
+}
+
+// Provider 模型服务提供方
+type Provider interface {
+	// Name 服务提供方名称，参与缓存键计算和用量统计
+	Name() string
+	// Complete 发送请求并等待完整回复
+	Complete(ctx context.Context, req Request) (*Response, error)
+	// Stream 以流式方式发送请求，每收到一段文本调用一次 onDelta
+	Stream(ctx context.Context, req Request, onDelta func(string)) (*Response, error)
+}
+
+// New 根据配置创建服务提供方，配置了 fallback 时返回按顺序回退的 Fallback
+func New(cfg *config.Config) (Provider, error) {
+	primary, err := newSingle(cfg)
+	if err != nil || cfg.Fallback == "" {
+		return primary, err
+	}
+
+	var on []string
+	for _, reason := range strings.Split(cfg.FallbackOn, ",") {
+		reason = strings.TrimSpace(reason)
+		if reason == "" {
+			continue
+		}
+		if !slices.Contains(FailoverReasons, reason) {
+			return nil, fmt.Errorf("无效的 fallback_on: %s，可选: %s", reason, strings.Join(FailoverReasons, "，"))
+		}
+		on = append(on, reason)
+	}
+	var timeout time.Duration
+	if cfg.FallbackTimeout != "" {
+		if timeout, err = time.ParseDuration(cfg.FallbackTimeout); err != nil {
+			return nil, fmt.Errorf("无效的 fallback_timeout: %s", cfg.FallbackTimeout)
+		}
+	}
+
+	chain := []Candidate{{Model: cfg.Model, Provider: primary}}
+	for _, name := range strings.Split(cfg.Fallback, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" || name == cfg.Model {
+			continue
+		}
+		fc := cfg.ForModel(name)
+		p, err := newSingle(fc)
+		if err != nil {
+			return nil, fmt.Errorf("初始化回退模型 %s 失败: %w", name, err)
+		}
+		chain = append(chain, Candidate{Model: fc.Model, Provider: p})
+	}
+	return NewFallback(chain, on, timeout), nil
+}
+
+// newSingle 创建单个模型的服务提供方
+func newSingle(cfg *config.Config) (Provider, error) {
+	switch cfg.Provider {
+	case "", OpenAIName:
+		if err := ValidateParams(OpenAIName, cfg.Model, cfg.Params); err != nil {
+			return nil, err
+		}
+		client, err := cassette.Client(cfg.Token)
+		if err != nil {
+			return nil, err
+		}
+		p := NewOpenAI(cfg.Token, cfg.Url, client)
+		p.SetParams(cfg.Params)
+		return p, nil
+	case AzureName:
+		if err := ValidateParams(AzureName, cfg.Model, cfg.Params); err != nil {
+			return nil, err
+		}
+		// aad 认证时获取 token 的请求体中带有客户端密钥
+		client, err := cassette.Client(cfg.Token, os.Getenv(azureSecretEnv))
+		if err != nil {
+			return nil, err
+		}
+		p, err := NewAzure(cfg, client)
+		if err != nil {
+			return nil, err
+		}
+		p.SetParams(cfg.Params)
+		return p, nil
+	case FakeName:
+		return newFakeFromEnv()
+	default:
+		return nil, fmt.Errorf("不支持的 provider: %s，可选: %s", cfg.Provider, strings.Join(Names(), "，"))
+	}
+}
+
+// Names 返回支持的服务提供方名称
+func Names() []string {
+	return []string{OpenAIName, AzureName, FakeName}
+}
@@ -0,0 +1,185 @@
+package redact
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"ai_code_reviewer/internal/gitutil"
+)
+
+// Redaction 一处被替换的敏感信息
+type Redaction struct {
+	File string
+	Line int // 新增/上下文行为新文件行号，删除行为旧文件行号
+	Rule string
+}
+
+func (r Redaction) String() string {
+	return fmt.Sprintf("%s:%d %s", r.File, r.Line, r.Rule)
+}
+
+var (
+	hunkHeader = regexp.MustCompile(`^@@ -(\d+)(?:,\d+)? \+(\d+)(?:,\d+)? @@`)
+	pemInline  = regexp.MustCompile(`(-----BEGIN [A-Z ]*PRIVATE KEY-----).*?(-----END [A-Z ]*PRIVATE KEY-----)`)
+)
+
+// Placeholder 返回替换敏感信息使用的占位符
+func Placeholder(rule string) string {
+	return "[REDACTED:" + rule + "]"
+}
+
+// Apply 按 redact_mode 处理发送给模型前的 diff：off 原样返回；redact 替换敏感信息并返回替换记录；
+// refuse 检测到敏感信息时返回错误，返回的 diff 同样已脱敏
+func Apply(mode string, files []gitutil.FileDiff) ([]gitutil.FileDiff, []Redaction, error) {
+	if mode == "off" {
+		return files, nil, nil
+	}
+	files, found := Files(files)
+	return files, found, refused(mode, found)
+}
+
+// ApplyText 与 Apply 相同，处理普通文本
+func ApplyText(mode, name, content string) (string, []Redaction, error) {
+	if mode == "off" {
+		return content, nil, nil
+	}
+	content, found := Text(name, content)
+	return content, found, refused(mode, found)
+}
+
+// refused redact_mode 为 refuse 且检测到敏感信息时返回错误
+func refused(mode string, found []Redaction) error {
+	if mode == "refuse" && len(found) > 0 {
+		return fmt.Errorf("检测到 %d 处敏感信息，已拒绝发送（redact_mode=refuse）", len(found))
+	}
+	return nil
+}
+
+// Files 对所有文件的 diff 做脱敏，返回脱敏后的 diff 和替换记录
+func Files(files []gitutil.FileDiff) ([]gitutil.FileDiff, []Redaction) {
+	out := make([]gitutil.FileDiff, len(files))
+	var all []Redaction
+	for i, f := range files {
+		var found []Redaction
+		out[i], found = File(f)
+		all = append(all, found...)
+	}
+	return out, all
+}
+
+// File 对单个文件的 diff 做脱敏，只处理 hunk 中的内容行，保持 diff 行数不变
+func File(f gitutil.FileDiff) (gitutil.FileDiff, []Redaction) {
+	var found []Redaction
+	sc := &lineScanner{skipEntropy: lockFiles[path.Base(f.Path)]}
+
+	lines := strings.Split(f.Content, "\n")
+	inHunk := false
+	oldLine, newLine := 0, 0
+
+	for i, line := range lines {
+		if m := hunkHeader.FindStringSubmatch(line); m != nil {
+			oldLine, _ = strconv.Atoi(m[1])
+			newLine, _ = strconv.Atoi(m[2])
+			inHunk = true
+			continue
+		}
+		if strings.HasPrefix(line, "diff --git ") {
+			inHunk, sc.inPEM = false, false
+			continue
+		}
+		if !inHunk || line == "" || line[0] == '\\' {
+			continue
+		}
+
+		prefix, body := line[:1], line[1:]
+		lineNo := newLine
+		switch prefix {
+		case "+":
+			newLine++
+		case "-":
+			lineNo = oldLine
+			oldLine++
+		default:
+			oldLine++
+			newLine++
+		}
+
+		redacted, hits := sc.scan(body)
+		for _, rule := range hits {
+			found = append(found, Redaction{File: f.Path, Line: lineNo, Rule: rule})
+		}
+		lines[i] = prefix + redacted
+	}
+
+	return gitutil.FileDiff{Path: f.Path, Content: strings.Join(lines, "\n")}, found
+}
+
+// Text 对普通文本（如完整的文件内容）做脱敏，name 用于记录位置
+func Text(name, content string) (string, []Redaction) {
+	var found []Redaction
+	sc := &lineScanner{skipEntropy: lockFiles[path.Base(name)]}
+
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		redacted, hits := sc.scan(line)
+		for _, rule := range hits {
+			found = append(found, Redaction{File: name, Line: i + 1, Rule: rule})
+		}
+		lines[i] = redacted
+	}
+	return strings.Join(lines, "\n"), found
+}
+
+// lineScanner 逐行检测敏感信息，记录跨行的私钥块状态
+type lineScanner struct {
+	skipEntropy bool
+	inPEM       bool
+}
+
+// scan 处理一行内容，返回替换后的内容和命中的规则名
+func (sc *lineScanner) scan(line string) (string, []string) {
+	// 私钥块：保留首尾标记行，中间内容逐行替换
+	if sc.inPEM {
+		if strings.Contains(line, "-----END ") && strings.Contains(line, "PRIVATE KEY-----") {
+			sc.inPEM = false
+			return line, nil
+		}
+		return Placeholder("private_key"), nil
+	}
+	if strings.Contains(line, "-----BEGIN ") && strings.Contains(line, "PRIVATE KEY-----") {
+		if pemInline.MatchString(line) {
+			return pemInline.ReplaceAllString(line, "$1"+Placeholder("private_key")+"$2"), []string{"private_key"}
+		}
+		sc.inPEM = true
+		return line, []string{"private_key"}
+	}
+	return redactLine(line, sc.skipEntropy)
+}
+
+// redactLine 依次应用检测规则，返回替换后的内容和命中的规则名
+func redactLine(line string, skipEntropy bool) (string, []string) {
+	var hits []string
+	for _, rule := range rules {
+		if skipEntropy && rule.Name == "high_entropy_string" {
+			continue
+		}
+		matches := rule.Pattern.FindAllStringSubmatchIndex(line, -1)
+		// 从后往前替换，避免前面的替换影响后面匹配的下标
+		for j := len(matches) - 1; j >= 0; j-- {
+			start, end := matches[j][2*rule.Group], matches[j][2*rule.Group+1]
+			if start < 0 {
+				continue
+			}
+			value := line[start:end]
+			if rule.Check != nil && !rule.Check(value) {
+				continue
+			}
+			line = line[:start] + Placeholder(rule.Name) + line[end:]
+			hits = append(hits, rule.Name)
+		}
+	}
+	return line, hits
+}
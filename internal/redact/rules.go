@@ -0,0 +1,77 @@
+package redact
+
+import (
+	"math"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Rule 基于正则的敏感信息检测规则
+type Rule struct {
+	Name    string
+	Pattern *regexp.Regexp
+	Group   int                     // 需要替换的捕获组，0 表示整个匹配
+	Check   func(value string) bool // 可选的二次校验，用于降低误报
+}
+
+// 敏感信息检测规则，按顺序匹配，先匹配到的规则优先
+var rules = []Rule{
+	{Name: "aws_access_key_id", Pattern: regexp.MustCompile(`\b(?:AKIA|ASIA|AGPA|AIDA|AROA)[0-9A-Z]{16}\b`)},
+	{Name: "aws_secret_access_key", Pattern: regexp.MustCompile(`(?i)aws.{0,20}?(?:secret|key).{0,20}?[:=]\s*["']?([A-Za-z0-9/+=]{40})\b`), Group: 1},
+	{Name: "github_token", Pattern: regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`)},
+	{Name: "slack_token", Pattern: regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}\b`)},
+	{Name: "openai_api_key", Pattern: regexp.MustCompile(`\bsk-(?:proj-|ant-)?[A-Za-z0-9_-]{20,}\b`)},
+	{Name: "google_api_key", Pattern: regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`)},
+	{Name: "jwt", Pattern: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`)},
+	{Name: "url_credentials", Pattern: regexp.MustCompile(`[a-zA-Z][a-zA-Z0-9+.-]*://[^\s:/@]+:([^\s:/@]{3,})@`), Group: 1},
+	{
+		Name:    "generic_secret",
+		Pattern: regexp.MustCompile(`(?i)(?:password|passwd|pwd|secret|token|api[_-]?key|access[_-]?key|private[_-]?key|client[_-]?secret)["']?\s*[:=]\s*["']?([^\s"',;]{8,})`),
+		Group:   1,
+		Check:   func(v string) bool { return hasDigit(v) && entropy(v) >= 3.0 },
+	},
+	{
+		Name:    "high_entropy_string",
+		Pattern: regexp.MustCompile(`[A-Za-z0-9+/_=-]{32,}`),
+		Check:   func(v string) bool { return hasDigit(v) && hasLetter(v) && entropy(v) >= 4.0 },
+	},
+}
+
+// 锁文件中大量哈希值属于正常内容，不做高熵检测
+var lockFiles = map[string]bool{
+	"go.sum":            true,
+	"package-lock.json": true,
+	"yarn.lock":         true,
+	"pnpm-lock.yaml":    true,
+	"Cargo.lock":        true,
+	"poetry.lock":       true,
+	"composer.lock":     true,
+	"Gemfile.lock":      true,
+}
+
+// entropy 计算字符串的香农熵（bits/字符）
+func entropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+	counts := map[rune]int{}
+	for _, r := range s {
+		counts[r]++
+	}
+	n := float64(len([]rune(s)))
+	var h float64
+	for _, c := range counts {
+		p := float64(c) / n
+		h -= p * math.Log2(p)
+	}
+	return h
+}
+
+func hasDigit(s string) bool {
+	return strings.IndexFunc(s, unicode.IsDigit) >= 0
+}
+
+func hasLetter(s string) bool {
+	return strings.IndexFunc(s, unicode.IsLetter) >= 0
+}
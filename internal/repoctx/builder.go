@@ -0,0 +1,224 @@
+package repoctx
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"ai_code_reviewer/internal/gitutil"
+	"ai_code_reviewer/internal/tokens"
+)
+
+// 上下文模式
+const (
+	ModeOff   = "off"   // 只发送 diff
+	ModeLines = "lines" // 附加变更附近的若干行
+	ModeSmart = "smart" // 附加所在函数、引用的定义和相关测试
+)
+
+// 单个文件读取上限，避免把生成文件或数据文件整体读入
+const maxFileSize = 256 * 1024
+
+// Builder 为变更文件收集仓库中的相关代码
+type Builder struct {
+	mode      string
+	lines     int
+	maxTokens int
+	model     string
+	ref       string // 读取文件的引用，为空时读取工作区
+}
+
+// section 一段上下文代码
+type section struct {
+	title string
+	path  string
+	start int // 起始行号，从 1 开始
+	code  string
+}
+
+// NewBuilder 创建上下文构建器，ref 为空时从工作区读取文件
+func NewBuilder(mode string, lines, maxTokens int, model, ref string) *Builder {
+	return &Builder{
+		mode:      mode,
+		lines:     lines,
+		maxTokens: maxTokens,
+		model:     model,
+		ref:       ref,
+	}
+}
+
+// ValidMode 判断上下文模式是否合法
+func ValidMode(mode string) bool {
+	return mode == ModeOff || mode == ModeLines || mode == ModeSmart
+}
+
+// Build 返回文件 f 的上下文文本，没有可用上下文时返回空字符串
+func (b *Builder) Build(f gitutil.FileDiff) (string, error) {
+	if b.mode == ModeOff || b.mode == "" {
+		return "", nil
+	}
+
+	src, err := b.readFile(f.Path)
+	if err != nil || src == nil {
+		// 删除的文件或二进制文件没有可用的上下文
+		return "", nil
+	}
+	ranges := f.ChangedRanges()
+	if len(ranges) == 0 {
+		return "", nil
+	}
+
+	var sections []section
+	switch b.mode {
+	case ModeLines:
+		sections = b.windowSections(f.Path, src, ranges)
+	case ModeSmart:
+		if strings.HasSuffix(f.Path, ".go") {
+			sections = b.goSections(f.Path, src, ranges)
+		} else {
+			sections = b.heuristicSections(f.Path, src, ranges)
+		}
+		sections = append(sections, b.testSections(f.Path)...)
+	default:
+		return "", fmt.Errorf("不支持的上下文模式: %s", b.mode)
+	}
+
+	return b.assemble(sections)
+}
+
+// windowSections 变更区间上下各扩展 lines 行
+func (b *Builder) windowSections(path string, src []byte, ranges []gitutil.LineRange) []section {
+	lines := splitLines(src)
+	var sections []section
+	lastEnd := 0
+	for _, r := range ranges {
+		start := max(r.Start-b.lines, lastEnd+1, 1)
+		end := min(r.End+b.lines, len(lines))
+		if start > end {
+			continue
+		}
+		sections = append(sections, section{
+			title: "变更附近的代码",
+			path:  path,
+			start: start,
+			code:  strings.Join(lines[start-1:end], "\n"),
+		})
+		lastEnd = end
+	}
+	return sections
+}
+
+// assemble 按优先级拼接上下文，超出 token 预算的部分截断或丢弃
+func (b *Builder) assemble(sections []section) (string, error) {
+	if len(sections) == 0 {
+		return "", nil
+	}
+
+	header := "以下是与本文件变更相关的仓库代码，仅用于理解上下文，不需要审查：\n"
+	used, err := tokens.Count(b.model, header)
+	if err != nil {
+		return "", err
+	}
+
+	var out strings.Builder
+	out.WriteString(header)
+	added := 0
+	for _, s := range sections {
+		text := s.render()
+		n, err := tokens.Count(b.model, text)
+		if err != nil {
+			return "", err
+		}
+		if b.maxTokens > 0 && used+n > b.maxTokens {
+			// 剩余预算较多时截断后加入，否则丢弃
+			remaining := b.maxTokens - used
+			if remaining < 200 {
+				continue
+			}
+			text = s.truncate(float64(remaining) / float64(n)).render()
+			n, _ = tokens.Count(b.model, text)
+			if used+n > b.maxTokens {
+				continue
+			}
+		}
+		out.WriteString("\n")
+		out.WriteString(text)
+		used += n
+		added++
+	}
+
+	if added == 0 {
+		return "", nil
+	}
+	return out.String(), nil
+}
+
+func (b *Builder) readFile(path string) ([]byte, error) {
+	data, err := gitutil.ShowFile(b.ref, path)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxFileSize || isBinary(data) {
+		return nil, nil
+	}
+	return data, nil
+}
+
+func (s section) render() string {
+	var loc string
+	if s.start > 0 {
+		end := s.start + strings.Count(s.code, "\n")
+		loc = fmt.Sprintf("%s:%d-%d", s.path, s.start, end)
+	} else {
+		loc = s.path
+	}
+	lang := strings.TrimPrefix(filepath.Ext(s.path), ".")
+	return fmt.Sprintf("### %s（%s）\n```%s\n%s\n```\n", s.title, loc, lang, s.code)
+}
+
+// truncate 按比例保留开头部分
+func (s section) truncate(ratio float64) section {
+	lines := strings.Split(s.code, "\n")
+	keep := int(float64(len(lines)) * ratio * 0.9)
+	if keep < 1 {
+		keep = 1
+	}
+	if keep < len(lines) {
+		s.code = strings.Join(lines[:keep], "\n") + "\n// ...（已截断）"
+	}
+	return s
+}
+
+func splitLines(src []byte) []string {
+	return strings.Split(strings.TrimRight(string(src), "\n"), "\n")
+}
+
+func isBinary(data []byte) bool {
+	n := min(len(data), 8000)
+	return strings.IndexByte(string(data[:n]), 0) >= 0
+}
+
+// overlaps 判断 [start, end] 是否与任一变更区间相交
+func overlaps(start, end int, ranges []gitutil.LineRange) bool {
+	for _, r := range ranges {
+		if start <= r.End && r.Start <= end {
+			return true
+		}
+	}
+	return false
+}
+
+// covered 判断 [start, end] 是否完全处于某个变更区间内（即整段都已出现在 diff 中）
+func covered(start, end int, ranges []gitutil.LineRange) bool {
+	for _, r := range ranges {
+		if r.Start <= start && end <= r.End {
+			return true
+		}
+	}
+	return false
+}
+
+// inRanges 判断行号是否处于变更区间内
+func inRanges(line int, ranges []gitutil.LineRange) bool {
+	return overlaps(line, line, ranges)
+}
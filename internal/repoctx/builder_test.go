@@ -0,0 +1,205 @@
+package repoctx
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ai_code_reviewer/internal/gitutil"
+)
+
+// useRepo 将 testdata 下的示例仓库复制到临时目录并初始化为 git 仓库，测试期间以其为工作目录
+func useRepo(t *testing.T, name string) {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.CopyFS(dir, os.DirFS(filepath.Join("testdata", name))); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := exec.Command("git", "-C", dir, "init", "-q").CombinedOutput(); err != nil {
+		t.Skipf("无法初始化 git 仓库: %v %s", err, out)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}
+
+// readSource 读取示例仓库中的文件
+func readSource(t *testing.T, path string) []byte {
+	t.Helper()
+	src, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return src
+}
+
+// lineOf 返回包含 text 的第一行的行号
+func lineOf(t *testing.T, src []byte, text string) int {
+	t.Helper()
+	for i, line := range splitLines(src) {
+		if strings.Contains(line, text) {
+			return i + 1
+		}
+	}
+	t.Fatalf("源码中没有 %q", text)
+	return 0
+}
+
+// titles 返回各段上下文的标题
+func titles(sections []section) []string {
+	var out []string
+	for _, s := range sections {
+		out = append(out, s.title)
+	}
+	return out
+}
+
+// numberedLines 生成 n 行形如 "line 1" 的文本
+func numberedLines(n int) string {
+	lines := make([]string, n)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("line %d", i+1)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestWindowSections(t *testing.T) {
+	src := []byte(numberedLines(10) + "\n")
+	tests := []struct {
+		name   string
+		lines  int
+		ranges []gitutil.LineRange
+		want   []string // 各段的 起始行-结束行
+	}{
+		{name: "上下各扩展两行", lines: 2, ranges: []gitutil.LineRange{{Start: 5, End: 5}}, want: []string{"3-7"}},
+		{name: "不超出文件首尾", lines: 3, ranges: []gitutil.LineRange{{Start: 1, End: 2}, {Start: 10, End: 10}}, want: []string{"1-5", "7-10"}},
+		{name: "相邻区间不重复输出", lines: 2, ranges: []gitutil.LineRange{{Start: 4, End: 4}, {Start: 6, End: 6}}, want: []string{"2-6", "7-8"}},
+		{name: "后一区间已被完全包含", lines: 3, ranges: []gitutil.LineRange{{Start: 4, End: 4}, {Start: 5, End: 5}}, want: []string{"1-7", "8-8"}},
+		{name: "不扩展", lines: 0, ranges: []gitutil.LineRange{{Start: 3, End: 4}}, want: []string{"3-4"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewBuilder(ModeLines, tt.lines, 0, "gpt-4o", "")
+			var got []string
+			for _, s := range b.windowSections("a.txt", src, tt.ranges) {
+				end := s.start + strings.Count(s.code, "\n")
+				got = append(got, fmt.Sprintf("%d-%d", s.start, end))
+				if first := fmt.Sprintf("line %d", s.start); !strings.HasPrefix(s.code, first) {
+					t.Errorf("代码应从第 %d 行开始: %q", s.start, s.code)
+				}
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	s := section{title: "t", path: "a.py", start: 1, code: numberedLines(10)}
+	tests := []struct {
+		ratio float64
+		keep  int
+	}{
+		{ratio: 0.5, keep: 4},
+		{ratio: 0.01, keep: 1}, // 至少保留一行
+	}
+	for _, tt := range tests {
+		got := s.truncate(tt.ratio).code
+		want := numberedLines(tt.keep) + "\n// ...（已截断）"
+		if got != want {
+			t.Errorf("truncate(%v) = %q, want %q", tt.ratio, got, want)
+		}
+	}
+}
+
+func TestAssemble(t *testing.T) {
+	small := section{title: "小段", path: "a.go", start: 1, code: "func a() {}"}
+	large := section{title: "大段", path: "b.go", start: 1, code: numberedLines(400)}
+
+	tests := []struct {
+		name      string
+		maxTokens int
+		sections  []section
+		want      []string // 输出中应出现的内容
+		wantNot   []string // 输出中不应出现的内容
+		wantEmpty bool
+	}{
+		{name: "没有上下文", sections: nil, wantEmpty: true},
+		{name: "不限预算时全部加入", sections: []section{small, large}, want: []string{"### 小段（a.go:1-1）\n```go\nfunc a() {}\n```", "line 400"}, wantNot: []string{"已截断"}},
+		{name: "剩余预算充足时截断加入", maxTokens: 1000, sections: []section{small, large}, want: []string{"小段", "大段", "line 1\n", "// ...（已截断）"}, wantNot: []string{"line 400"}},
+		{name: "剩余预算不足 200 时丢弃", maxTokens: 150, sections: []section{small, large}, want: []string{"小段"}, wantNot: []string{"大段"}},
+		{name: "全部丢弃时返回空", maxTokens: 100, sections: []section{large}, wantEmpty: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewBuilder(ModeSmart, 0, tt.maxTokens, "gpt-4o", "")
+			got, err := b.assemble(tt.sections)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantEmpty {
+				if got != "" {
+					t.Errorf("应返回空字符串，got %q", got)
+				}
+				return
+			}
+			if !strings.HasPrefix(got, "以下是与本文件变更相关的仓库代码") {
+				t.Errorf("缺少说明: %q", got)
+			}
+			for _, w := range tt.want {
+				if !strings.Contains(got, w) {
+					t.Errorf("输出中缺少 %q", w)
+				}
+			}
+			for _, w := range tt.wantNot {
+				if strings.Contains(got, w) {
+					t.Errorf("输出中不应包含 %q", w)
+				}
+			}
+		})
+	}
+}
+
+func TestBuild(t *testing.T) {
+	useRepo(t, "go")
+	change := gitutil.FileDiff{Path: "price/price.go", Content: "@@ -5 +5 @@\n-\treturn 0\n+\treturn len(name)\n"}
+
+	tests := []struct {
+		name string
+		mode string
+		file gitutil.FileDiff
+		want string
+	}{
+		{name: "关闭上下文", mode: ModeOff, file: change},
+		{name: "附近行", mode: ModeLines, file: change, want: "### 变更附近的代码（price/price.go:4-6）"},
+		{name: "所在声明", mode: ModeSmart, file: change, want: "### 变更所在的声明 Of（price/price.go:3-6）\n```go\n// Of 返回商品单价"},
+		{name: "已删除的文件", mode: ModeSmart, file: gitutil.FileDiff{Path: "gone.go", Content: "@@ -1 +0,0 @@\n-package gone\n"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewBuilder(tt.mode, 1, 0, "gpt-4o", "").Build(tt.file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.want == "" && got != "" {
+				t.Errorf("应没有上下文，got %q", got)
+			}
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("输出中缺少 %q:\n%s", tt.want, got)
+			}
+		})
+	}
+
+	if _, err := NewBuilder("full", 1, 0, "gpt-4o", "").Build(change); err == nil {
+		t.Error("不支持的模式应返回错误")
+	}
+}
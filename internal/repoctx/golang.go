@@ -0,0 +1,229 @@
+package repoctx
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"ai_code_reviewer/internal/gitutil"
+)
+
+// goDecl 包级声明在源码中的位置
+type goDecl struct {
+	name  string // 函数名、类型名或变量名；方法为 Recv.Name
+	key   string // 用于按标识符查找的名称，方法为方法名
+	path  string
+	start int
+	end   int
+	code  string
+}
+
+// goSections 使用 go/parser 找出变更所在的声明以及变更行引用的包内、模块内定义
+func (b *Builder) goSections(filePath string, src []byte, ranges []gitutil.LineRange) []section {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filePath, src, parser.ParseComments)
+	if err != nil {
+		// 语法错误时退回通用规则
+		return b.heuristicSections(filePath, src, ranges)
+	}
+
+	var sections []section
+	included := map[string]bool{}
+
+	// 1. 变更所在的声明（整段都是新增的声明已在 diff 中，不再重复）
+	for _, d := range collectDecls(fset, file, filePath, src) {
+		if overlaps(d.start, d.end, ranges) && !covered(d.start, d.end, ranges) {
+			sections = append(sections, section{title: "变更所在的声明 " + d.name, path: d.path, start: d.start, code: d.code})
+			included[d.path+":"+d.name] = true
+		}
+	}
+
+	// 2. 收集变更行中引用的标识符，区分包内引用和 pkg.Name 形式的跨包引用
+	imports := moduleImports(file)
+	local := map[string]bool{}
+	remote := map[string]map[string]bool{} // 包目录 -> 标识符
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch x := n.(type) {
+		case *ast.SelectorExpr:
+			if pkg, ok := x.X.(*ast.Ident); ok {
+				if dir, ok := imports[pkg.Name]; ok && inRanges(fset.Position(x.Pos()).Line, ranges) {
+					if remote[dir] == nil {
+						remote[dir] = map[string]bool{}
+					}
+					remote[dir][x.Sel.Name] = true
+					return false
+				}
+			}
+		case *ast.Ident:
+			if inRanges(fset.Position(x.Pos()).Line, ranges) {
+				local[x.Name] = true
+			}
+		}
+		return true
+	})
+
+	// 3. 包内定义：同目录下的非测试文件
+	dir := path.Dir(filePath)
+	for _, d := range b.packageDecls(dir, filePath, fset, file, src) {
+		if !local[d.key] || included[d.path+":"+d.name] {
+			continue
+		}
+		// 本文件中与变更重叠的声明已经包含在 diff 或上一步中
+		if d.path == filePath && overlaps(d.start, d.end, ranges) {
+			continue
+		}
+		sections = append(sections, section{title: "引用的定义 " + d.name, path: d.path, start: d.start, code: d.code})
+		included[d.path+":"+d.name] = true
+	}
+
+	// 4. 模块内其他包的定义
+	dirs := make([]string, 0, len(remote))
+	for d := range remote {
+		dirs = append(dirs, d)
+	}
+	sort.Strings(dirs)
+	for _, pkgDir := range dirs {
+		for _, d := range b.packageDecls(pkgDir, "", nil, nil, nil) {
+			if !remote[pkgDir][d.key] || strings.Contains(d.name, ".") || included[d.path+":"+d.name] {
+				continue
+			}
+			sections = append(sections, section{title: "引用的定义 " + path.Base(pkgDir) + "." + d.name, path: d.path, start: d.start, code: d.code})
+			included[d.path+":"+d.name] = true
+		}
+	}
+
+	return sections
+}
+
+// packageDecls 收集目录下所有非测试 Go 文件的包级声明，已解析的文件可直接传入复用
+func (b *Builder) packageDecls(dir, parsedPath string, parsedFset *token.FileSet, parsed *ast.File, parsedSrc []byte) []goDecl {
+	files, err := gitutil.ListFiles(b.ref, dir)
+	if err != nil {
+		return nil
+	}
+
+	var decls []goDecl
+	for _, f := range files {
+		if path.Dir(f) != path.Clean(dir) || !strings.HasSuffix(f, ".go") || strings.HasSuffix(f, "_test.go") {
+			continue
+		}
+		if f == parsedPath && parsed != nil {
+			decls = append(decls, collectDecls(parsedFset, parsed, f, parsedSrc)...)
+			continue
+		}
+		src, err := b.readFile(f)
+		if err != nil || src == nil {
+			continue
+		}
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, f, src, parser.ParseComments)
+		if err != nil {
+			continue
+		}
+		decls = append(decls, collectDecls(fset, file, f, src)...)
+	}
+	return decls
+}
+
+// collectDecls 提取文件中的函数、方法、类型、变量和常量声明（含文档注释）
+func collectDecls(fset *token.FileSet, file *ast.File, filePath string, src []byte) []goDecl {
+	var decls []goDecl
+	add := func(name, key string, startPos, endPos token.Pos) {
+		start, end := fset.Position(startPos), fset.Position(endPos)
+		decls = append(decls, goDecl{
+			name:  name,
+			key:   key,
+			path:  filePath,
+			start: start.Line,
+			end:   end.Line,
+			code:  string(src[start.Offset:end.Offset]),
+		})
+	}
+
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			start := d.Pos()
+			if d.Doc != nil {
+				start = d.Doc.Pos()
+			}
+			name := d.Name.Name
+			if d.Recv != nil && len(d.Recv.List) > 0 {
+				name = receiverName(d.Recv.List[0].Type) + "." + name
+			}
+			add(name, d.Name.Name, start, d.End())
+		case *ast.GenDecl:
+			if d.Tok == token.IMPORT {
+				continue
+			}
+			start := d.Pos()
+			if d.Doc != nil {
+				start = d.Doc.Pos()
+			}
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					add(s.Name.Name, s.Name.Name, start, d.End())
+				case *ast.ValueSpec:
+					for _, n := range s.Names {
+						add(n.Name, n.Name, start, d.End())
+					}
+				}
+			}
+		}
+	}
+	return decls
+}
+
+// moduleImports 返回导入的本模块包：包名 -> 相对仓库根目录的路径
+func moduleImports(file *ast.File) map[string]string {
+	module := modulePath()
+	imports := map[string]string{}
+	if module == "" {
+		return imports
+	}
+	for _, imp := range file.Imports {
+		p, err := strconv.Unquote(imp.Path.Value)
+		if err != nil || !strings.HasPrefix(p, module+"/") {
+			continue
+		}
+		name := path.Base(p)
+		if imp.Name != nil {
+			name = imp.Name.Name
+		}
+		imports[name] = strings.TrimPrefix(p, module+"/")
+	}
+	return imports
+}
+
+// modulePath 读取仓库根目录 go.mod 中的模块路径
+func modulePath() string {
+	data, err := gitutil.ShowFile("", "go.mod")
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(line, "module ") {
+			return strings.TrimSpace(strings.TrimPrefix(line, "module "))
+		}
+	}
+	return ""
+}
+
+func receiverName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return receiverName(t.X)
+	case *ast.IndexExpr:
+		return receiverName(t.X)
+	case *ast.IndexListExpr:
+		return receiverName(t.X)
+	case *ast.Ident:
+		return t.Name
+	}
+	return ""
+}
@@ -0,0 +1,127 @@
+package repoctx
+
+import (
+	"go/parser"
+	"go/token"
+	"reflect"
+	"strings"
+	"testing"
+
+	"ai_code_reviewer/internal/gitutil"
+)
+
+func TestCollectDecls(t *testing.T) {
+	src := []byte(`package a
+
+import "fmt"
+
+// Server 服务
+type Server struct{}
+
+// Start 启动服务
+func (s *Server) Start() { fmt.Println("start") }
+
+func (p *Pool[T]) Get() T { var v T; return v }
+
+var (
+	x, y = 1, 2
+)
+
+const limit = 3
+`)
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "a.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, d := range collectDecls(fset, file, "a.go", src) {
+		got = append(got, d.name+"/"+d.key)
+	}
+	want := []string{"Server/Server", "Server.Start/Start", "Pool.Get/Get", "x/x", "y/y", "limit/limit"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// 声明的起始行包含文档注释
+	d := collectDecls(fset, file, "a.go", src)[1]
+	if d.start != 8 || d.end != 9 || !strings.HasPrefix(d.code, "// Start 启动服务\nfunc (s *Server) Start()") {
+		t.Errorf("Server.Start = %d-%d %q", d.start, d.end, d.code)
+	}
+}
+
+func TestGoSections(t *testing.T) {
+	useRepo(t, "go")
+	src := readSource(t, "cart/cart.go")
+
+	tests := []struct {
+		name   string
+		change string // 变更行中的文本
+		want   []string
+		first  string // 第一段代码的开头
+	}{
+		{
+			name:   "方法中的变更附带跨包引用的定义",
+			change: "sum += price.Of",
+			want:   []string{"变更所在的声明 Cart.Total", "引用的定义 price.Of"},
+			first:  "// Total 计算总价\nfunc (c *Cart) Total() int {",
+		},
+		{
+			name:   "类型中的变更附带包内引用的类型",
+			change: "Items []Item",
+			want:   []string{"变更所在的声明 Cart", "引用的定义 Item"},
+			first:  "// Cart 购物车\ntype Cart struct {",
+		},
+		{
+			name:   "整段都是新增的声明不再重复",
+			change: "func Empty() bool",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			line := lineOf(t, src, tt.change)
+			b := NewBuilder(ModeSmart, 3, 0, "gpt-4o", "")
+			sections := b.goSections("cart/cart.go", src, []gitutil.LineRange{{Start: line, End: line}})
+			if got := titles(sections); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("titles = %q, want %q", got, tt.want)
+			}
+			if tt.first != "" && !strings.HasPrefix(sections[0].code, tt.first) {
+				t.Errorf("所在声明应包含文档注释: %q", sections[0].code)
+			}
+		})
+	}
+}
+
+func TestGoSectionsSyntaxError(t *testing.T) {
+	useRepo(t, "go")
+	// 无法解析时退回通用规则
+	src := []byte("package cart\n\nfunc Broken() {\n\treturn (\n}\n")
+	b := NewBuilder(ModeSmart, 3, 0, "gpt-4o", "")
+	sections := b.goSections("cart/broken.go", src, []gitutil.LineRange{{Start: 4, End: 4}})
+	if got := titles(sections); !reflect.DeepEqual(got, []string{"变更所在的代码块"}) {
+		t.Errorf("titles = %q", got)
+	}
+}
+
+func TestModuleImports(t *testing.T) {
+	useRepo(t, "go")
+	src := `package a
+
+import (
+	"fmt"
+
+	"example.com/shop/price"
+	c "example.com/shop/cart"
+	"example.com/shopping/other"
+)
+`
+	file, err := parser.ParseFile(token.NewFileSet(), "a.go", src, parser.ImportsOnly)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"price": "price", "c": "cart"}
+	if got := moduleImports(file); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
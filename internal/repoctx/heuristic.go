@@ -0,0 +1,196 @@
+package repoctx
+
+import (
+	"path"
+	"regexp"
+	"strings"
+
+	"ai_code_reviewer/internal/gitutil"
+)
+
+// 非 Go 语言使用 ctags 风格的正则规则识别定义
+var (
+	defLine = regexp.MustCompile(`^\s*(?:export\s+)?(?:default\s+)?(?:(?:public|private|protected|internal|static|abstract|final|async|override|pub(?:\([a-z]+\))?)\s+)*` +
+		`(?:def|function|class|interface|struct|enum|trait|impl|fn|func|type|module|object)\b\s*\*?\s*([A-Za-z_][A-Za-z0-9_]*)`)
+	arrowDef   = regexp.MustCompile(`^\s*(?:export\s+)?(?:const|let|var)\s+([A-Za-z_][A-Za-z0-9_]*)\s*=\s*(?:async\s+)?(?:\([^)]*\)|\w+)\s*=>`)
+	identifier = regexp.MustCompile(`\b[A-Za-z_][A-Za-z0-9_]{2,}\b`)
+)
+
+// 查找定义时忽略的常见关键字和内置名称
+var keywords = map[string]bool{
+	"def": true, "function": true, "class": true, "return": true, "const": true, "let": true, "var": true,
+	"if": true, "else": true, "for": true, "while": true, "import": true, "from": true, "export": true,
+	"default": true, "new": true, "this": true, "self": true, "true": true, "false": true, "null": true,
+	"None": true, "True": true, "False": true, "undefined": true, "async": true, "await": true, "public": true,
+	"private": true, "protected": true, "static": true, "void": true, "int": true, "string": true, "bool": true,
+	"boolean": true, "float": true, "double": true, "long": true, "char": true, "try": true, "catch": true,
+	"except": true, "finally": true, "raise": true, "throw": true, "throws": true, "pass": true, "with": true,
+	"and": true, "not": true, "elif": true, "lambda": true, "yield": true, "struct": true, "enum": true,
+	"interface": true, "impl": true, "pub": true, "mut": true, "use": true, "mod": true, "match": true,
+	"case": true, "switch": true, "break": true, "continue": true, "extends": true, "implements": true,
+	"package": true, "typeof": true, "instanceof": true, "super": true, "print": true, "len": true,
+}
+
+// 查找定义时扫描的文件数上限
+const maxDefinitionFiles = 300
+
+// heuristicSections 通过缩进和定义行规则识别变更所在的代码块，并查找变更行引用的定义
+func (b *Builder) heuristicSections(filePath string, src []byte, ranges []gitutil.LineRange) []section {
+	lines := splitLines(src)
+	var sections []section
+
+	// 1. 变更所在的代码块，识别不到时退回变更附近的若干行
+	lastEnd := 0
+	for _, r := range ranges {
+		if r.Start <= lastEnd {
+			continue
+		}
+		start, end, ok := enclosingBlock(lines, r)
+		if !ok {
+			start, end = max(r.Start-b.lines, 1), min(r.End+b.lines, len(lines))
+		}
+		if start <= lastEnd {
+			start = lastEnd + 1
+		}
+		if start > end || covered(start, end, ranges) {
+			continue
+		}
+		sections = append(sections, section{
+			title: "变更所在的代码块",
+			path:  filePath,
+			start: start,
+			code:  strings.Join(lines[start-1:end], "\n"),
+		})
+		lastEnd = end
+	}
+
+	// 2. 变更行引用的标识符，排除变更中自身定义的名称
+	names := map[string]bool{}
+	for _, r := range ranges {
+		for i := r.Start; i <= r.End && i <= len(lines); i++ {
+			for _, id := range identifier.FindAllString(lines[i-1], -1) {
+				if !keywords[id] {
+					names[id] = true
+				}
+			}
+			if m := definedName(lines[i-1]); m != "" {
+				delete(names, m)
+			}
+		}
+	}
+	if len(names) == 0 {
+		return sections
+	}
+
+	return append(sections, b.findDefinitions(filePath, names, ranges)...)
+}
+
+// findDefinitions 在同类型文件中查找名称的定义，每个名称只取第一处
+func (b *Builder) findDefinitions(filePath string, names map[string]bool, ranges []gitutil.LineRange) []section {
+	ext := path.Ext(filePath)
+	if ext == "" {
+		return nil
+	}
+	files, err := gitutil.ListFiles(b.ref, "*"+ext)
+	if err != nil {
+		return nil
+	}
+	if len(files) > maxDefinitionFiles {
+		files = files[:maxDefinitionFiles]
+	}
+
+	var sections []section
+	found := map[string]bool{}
+	for _, f := range files {
+		src, err := b.readFile(f)
+		if err != nil || src == nil {
+			continue
+		}
+		lines := splitLines(src)
+		for i, line := range lines {
+			name := definedName(line)
+			if name == "" || !names[name] || found[name] {
+				continue
+			}
+			start := i + 1
+			end := blockEnd(lines, start, start)
+			if f == filePath && overlaps(start, end, ranges) {
+				continue
+			}
+			found[name] = true
+			sections = append(sections, section{
+				title: "引用的定义 " + name,
+				path:  f,
+				start: start,
+				code:  strings.Join(lines[start-1:end], "\n"),
+			})
+		}
+		if len(found) == len(names) {
+			break
+		}
+	}
+	return sections
+}
+
+// definedName 返回定义行中定义的名称，不是定义行时返回空字符串
+func definedName(line string) string {
+	if m := defLine.FindStringSubmatch(line); m != nil {
+		return m[1]
+	}
+	if m := arrowDef.FindStringSubmatch(line); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// enclosingBlock 从变更起始行向上查找缩进不大于变更行的定义行，并确定代码块结束位置
+func enclosingBlock(lines []string, r gitutil.LineRange) (int, int, bool) {
+	if r.Start > len(lines) {
+		return 0, 0, false
+	}
+	changedIndent := indentOf(lines[r.Start-1])
+	for i := r.Start; i >= 1 && i >= r.Start-200; i-- {
+		line := lines[i-1]
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if definedName(line) != "" && (i == r.Start || indentOf(line) < changedIndent || indentOf(line) == 0) {
+			return i, blockEnd(lines, i, r.End), true
+		}
+	}
+	return 0, 0, false
+}
+
+// blockEnd 从定义行开始，找到第一个缩进不大于定义行的非空行作为代码块结束
+func blockEnd(lines []string, def, from int) int {
+	indent := indentOf(lines[def-1])
+	limit := min(len(lines), def+300)
+	for i := max(from, def) + 1; i <= limit; i++ {
+		line := lines[i-1]
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || indentOf(line) > indent {
+			continue
+		}
+		// 花括号语言的右括号、Ruby/Lua 的 end 属于当前代码块
+		if strings.HasPrefix(trimmed, "}") || strings.HasPrefix(trimmed, ")") || trimmed == "end" {
+			return i
+		}
+		return i - 1
+	}
+	return limit
+}
+
+func indentOf(line string) int {
+	n := 0
+	for _, c := range line {
+		switch c {
+		case ' ':
+			n++
+		case '\t':
+			n += 4
+		default:
+			return n
+		}
+	}
+	return n
+}
@@ -0,0 +1,113 @@
+package repoctx
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"ai_code_reviewer/internal/gitutil"
+)
+
+func TestDefinedName(t *testing.T) {
+	tests := []struct {
+		line string
+		want string
+	}{
+		{line: "def discount(amount):", want: "discount"},
+		{line: "    async def fetch(self):", want: "fetch"},
+		{line: "class Order:", want: "Order"},
+		{line: "function render(cart) {", want: "render"},
+		{line: "export default async function* items() {", want: "items"},
+		{line: "export const formatPrice = (value) => {", want: "formatPrice"},
+		{line: "const add = async x => x + 1;", want: "add"},
+		{line: "pub(crate) fn parse(input: &str) {", want: "parse"},
+		{line: "public abstract class Repository {", want: "Repository"},
+		{line: "const total = cart.total();", want: ""},
+		{line: "return discount(amount)", want: ""},
+		{line: "# def commented_out():", want: ""},
+	}
+	for _, tt := range tests {
+		if got := definedName(tt.line); got != tt.want {
+			t.Errorf("definedName(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestEnclosingBlock(t *testing.T) {
+	py := splitLines([]byte("class Order:\n    def total(self):\n        amount = 1\n        return amount\n\n\ndef other():\n    pass\n"))
+	js := splitLines([]byte("function render(cart) {\n  if (cart) {\n    return 1;\n  }\n}\n\nrender();\n"))
+	tests := []struct {
+		name       string
+		lines      []string
+		r          gitutil.LineRange
+		start, end int
+		ok         bool
+	}{
+		{name: "Python 方法", lines: py, r: gitutil.LineRange{Start: 4, End: 4}, start: 2, end: 6, ok: true},
+		{name: "变更行本身是定义行", lines: py, r: gitutil.LineRange{Start: 7, End: 7}, start: 7, end: 8, ok: true},
+		{name: "花括号语言包含右括号", lines: js, r: gitutil.LineRange{Start: 3, End: 3}, start: 1, end: 5, ok: true},
+		{name: "上方没有定义行", lines: splitLines([]byte("import x from 'y';\n\nx();\n")), r: gitutil.LineRange{Start: 3, End: 3}},
+		{name: "超出文件末尾", lines: js, r: gitutil.LineRange{Start: 20, End: 20}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, ok := enclosingBlock(tt.lines, tt.r)
+			if ok != tt.ok || (ok && (start != tt.start || end != tt.end)) {
+				t.Errorf("got %d-%d %v, want %d-%d %v", start, end, ok, tt.start, tt.end, tt.ok)
+			}
+		})
+	}
+}
+
+func TestHeuristicSections(t *testing.T) {
+	tests := []struct {
+		name   string
+		repo   string
+		file   string
+		change string
+		want   []string
+		codes  []string // 各段代码的开头
+	}{
+		{
+			name:   "Python 方法调用其他模块的函数",
+			repo:   "py",
+			file:   "app/orders.py",
+			change: "return discount(amount)",
+			want:   []string{"变更所在的代码块", "引用的定义 discount"},
+			codes:  []string{"    def total(self):", "def discount(amount):\n    if amount > 100:"},
+		},
+		{
+			name:   "JavaScript 箭头函数定义",
+			repo:   "js",
+			file:   "cart.js",
+			change: "return formatPrice(total)",
+			want:   []string{"变更所在的代码块", "引用的定义 formatPrice"},
+			codes:  []string{"function render(cart) {", "export const formatPrice = (value) => {\n  return value.toFixed(2);\n};"},
+		},
+		{
+			name:   "变更中定义的名称不作为引用查找",
+			repo:   "py",
+			file:   "app/pricing.py",
+			change: "def discount(amount):",
+			want:   []string{"变更所在的代码块"},
+			codes:  []string{"def discount(amount):"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useRepo(t, tt.repo)
+			src := readSource(t, tt.file)
+			line := lineOf(t, src, tt.change)
+			b := NewBuilder(ModeSmart, 3, 0, "gpt-4o", "")
+			sections := b.heuristicSections(tt.file, src, []gitutil.LineRange{{Start: line, End: line}})
+			if got := titles(sections); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("titles = %q, want %q", got, tt.want)
+			}
+			for i, code := range tt.codes {
+				if !strings.HasPrefix(sections[i].code, code) {
+					t.Errorf("第 %d 段代码 = %q, want 前缀 %q", i+1, sections[i].code, code)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package cart
+
+import "example.com/shop/price"
+
+// Cart 购物车
+type Cart struct {
+	Items []Item
+}
+
+// Item 商品
+type Item struct {
+	Name  string
+	Count int
+}
+
+// Total 计算总价
+func (c *Cart) Total() int {
+	sum := 0
+	for _, it := range c.Items {
+		sum += price.Of(it.Name) * it.Count
+	}
+	return sum
+}
+
+func Empty() bool { return false }
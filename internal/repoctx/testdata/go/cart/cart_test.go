@@ -0,0 +1,9 @@
+package cart
+
+import "testing"
+
+func TestTotal(t *testing.T) {
+	if (&Cart{}).Total() != 0 {
+		t.Fail()
+	}
+}
@@ -0,0 +1,6 @@
+package price
+
+// Of 返回商品单价
+func Of(name string) int {
+	return len(name)
+}
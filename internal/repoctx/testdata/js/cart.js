@@ -0,0 +1,6 @@
+import { formatPrice } from './utils';
+
+function render(cart) {
+  const total = cart.total();
+  return formatPrice(total);
+}
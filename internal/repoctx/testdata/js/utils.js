@@ -0,0 +1,3 @@
+export const formatPrice = (value) => {
+  return value.toFixed(2);
+};
@@ -0,0 +1,14 @@
+from app.pricing import discount
+
+
+class Order:
+    def __init__(self, items):
+        self.items = items
+
+    def total(self):
+        amount = sum(i.price for i in self.items)
+        return discount(amount)
+
+
+def unrelated():
+    return 1
@@ -0,0 +1,4 @@
+def discount(amount):
+    if amount > 100:
+        return amount * 0.9
+    return amount
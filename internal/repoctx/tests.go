@@ -0,0 +1,78 @@
+package repoctx
+
+import (
+	"path"
+	"strings"
+)
+
+// testSections 按常见命名约定查找与变更文件对应的测试文件
+func (b *Builder) testSections(filePath string) []section {
+	var sections []section
+	for _, candidate := range testCandidates(filePath) {
+		if candidate == filePath {
+			continue
+		}
+		src, err := b.readFile(candidate)
+		if err != nil || src == nil {
+			continue
+		}
+		sections = append(sections, section{
+			title: "相关测试",
+			path:  candidate,
+			start: 1,
+			code:  strings.TrimRight(string(src), "\n"),
+		})
+	}
+	return sections
+}
+
+// testCandidates 返回可能的测试文件路径，测试文件本身不再查找
+func testCandidates(filePath string) []string {
+	dir, file := path.Split(filePath)
+	ext := path.Ext(file)
+	base := strings.TrimSuffix(file, ext)
+
+	switch ext {
+	case ".go":
+		if strings.HasSuffix(base, "_test") {
+			return nil
+		}
+		return []string{dir + base + "_test.go"}
+	case ".py":
+		if strings.HasPrefix(base, "test_") || strings.HasSuffix(base, "_test") {
+			return nil
+		}
+		return []string{
+			dir + "test_" + base + ".py",
+			dir + base + "_test.py",
+			dir + "tests/test_" + base + ".py",
+			"tests/test_" + base + ".py",
+		}
+	case ".js", ".jsx", ".ts", ".tsx", ".mjs", ".cjs":
+		if strings.HasSuffix(base, ".test") || strings.HasSuffix(base, ".spec") {
+			return nil
+		}
+		return []string{
+			dir + base + ".test" + ext,
+			dir + base + ".spec" + ext,
+			dir + "__tests__/" + base + ".test" + ext,
+		}
+	case ".java", ".kt":
+		if strings.HasSuffix(base, "Test") {
+			return nil
+		}
+		testDir := strings.Replace(dir, "src/main/", "src/test/", 1)
+		return []string{testDir + base + "Test" + ext}
+	case ".rb":
+		if strings.HasSuffix(base, "_spec") {
+			return nil
+		}
+		specDir := strings.Replace(dir, "lib/", "spec/", 1)
+		return []string{specDir + base + "_spec.rb", dir + base + "_spec.rb"}
+	default:
+		if ext == "" || strings.HasSuffix(base, "_test") {
+			return nil
+		}
+		return []string{dir + base + "_test" + ext}
+	}
+}
@@ -0,0 +1,47 @@
+package repoctx
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTestCandidates(t *testing.T) {
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{path: "internal/cart/cart.go", want: []string{"internal/cart/cart_test.go"}},
+		{path: "internal/cart/cart_test.go"},
+		{path: "app/orders.py", want: []string{"app/test_orders.py", "app/orders_test.py", "app/tests/test_orders.py", "tests/test_orders.py"}},
+		{path: "app/test_orders.py"},
+		{path: "src/cart.tsx", want: []string{"src/cart.test.tsx", "src/cart.spec.tsx", "src/__tests__/cart.test.tsx"}},
+		{path: "src/cart.spec.js"},
+		{path: "src/main/java/shop/Cart.java", want: []string{"src/test/java/shop/CartTest.java"}},
+		{path: "lib/shop/cart.rb", want: []string{"spec/shop/cart_spec.rb", "lib/shop/cart_spec.rb"}},
+		{path: "src/cart.rs", want: []string{"src/cart_test.rs"}},
+		{path: "Makefile"},
+	}
+	for _, tt := range tests {
+		if got := testCandidates(tt.path); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("testCandidates(%s) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestTestSections(t *testing.T) {
+	useRepo(t, "go")
+	b := NewBuilder(ModeSmart, 3, 0, "gpt-4o", "")
+
+	sections := b.testSections("cart/cart.go")
+	if len(sections) != 1 || sections[0].title != "相关测试" || sections[0].path != "cart/cart_test.go" || sections[0].start != 1 {
+		t.Fatalf("sections = %+v", sections)
+	}
+	if !strings.HasPrefix(sections[0].code, "package cart") || strings.HasSuffix(sections[0].code, "\n") {
+		t.Errorf("code = %q", sections[0].code)
+	}
+
+	if got := b.testSections("price/price.go"); len(got) != 0 {
+		t.Errorf("没有测试文件时应为空: %+v", got)
+	}
+}
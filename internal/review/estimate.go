@@ -0,0 +1,77 @@
+package review
+
+import (
+	"fmt"
+	"strings"
+
+	"ai_code_reviewer/internal/config"
+	"ai_code_reviewer/internal/tokens"
+	"ai_code_reviewer/internal/usage"
+)
+
+// Estimate 审查计划的预估输入用量
+type Estimate struct {
+	Requests    int
+	InputTokens int
+	InputCost   float64 // 仅包含输入部分的费用，输出长度无法预知
+	Priced      bool
+}
+
+// EstimatePlan 使用本地分词器估算计划中所有待发送请求的输入 token 数和费用
+func EstimatePlan(plan *Plan, cfg *config.Config) (Estimate, error) {
+	est := Estimate{Requests: len(plan.Pending)}
+	for _, req := range plan.Pending {
+		var messages []tokens.Message
+		for _, m := range req.Messages() {
+			messages = append(messages, tokens.Message{Role: m.Role, Content: m.Content})
+		}
+		n, err := tokens.CountMessages(cfg.Model, messages)
+		if err != nil {
+			return est, err
+		}
+		est.InputTokens += n
+	}
+
+	price, ok := usage.LookupPrice(cfg.Model, cfg.Prices)
+	est.Priced = ok
+	est.InputCost = usage.Cost(usage.Usage{PromptTokens: int64(est.InputTokens)}, price)
+	return est, nil
+}
+
+// String 输出预估摘要
+func (e Estimate) String() string {
+	s := fmt.Sprintf("预计发送 %d 个请求，输入约 %d tokens", e.Requests, e.InputTokens)
+	if e.Priced {
+		s += fmt.Sprintf("，输入费用约 $%.4f", e.InputCost)
+	}
+	return s
+}
+
+// CheckBudget 检查预估是否超出 max_input_tokens / max_cost 限制，返回超出项说明
+func CheckBudget(est Estimate, cfg *config.Config) []string {
+	var violations []string
+	if cfg.MaxInputTokens > 0 && est.InputTokens > cfg.MaxInputTokens {
+		violations = append(violations, fmt.Sprintf("预估输入 %d tokens 超过 max_input_tokens=%d", est.InputTokens, cfg.MaxInputTokens))
+	}
+	if cfg.MaxCost > 0 && est.Priced && est.InputCost > cfg.MaxCost {
+		violations = append(violations, fmt.Sprintf("预估输入费用 $%.4f 超过 max_cost=%g", est.InputCost, cfg.MaxCost))
+	}
+	return violations
+}
+
+// FormatPayload 按发送顺序输出每个请求的完整消息内容，用于 --dry-run
+func FormatPayload(plan *Plan) string {
+	var b strings.Builder
+	for n, req := range plan.Pending {
+		name := req.Path
+		if req.Pass != "" {
+			name += " [" + req.Pass + "]"
+		}
+		fmt.Fprintf(&b, "===== 请求 %d/%d: %s =====\n", n+1, len(plan.Pending), name)
+		for _, m := range req.Messages() {
+			fmt.Fprintf(&b, "[%s]\n%s\n", m.Role, strings.TrimRight(m.Content, "\n"))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,163 @@
+package review
+
+import (
+	"encoding/json"
+
+	"ai_code_reviewer/internal/config"
+	"ai_code_reviewer/internal/consensus"
+	"ai_code_reviewer/internal/finding"
+	"ai_code_reviewer/internal/lint"
+)
+
+// Report JSON 格式的审查结果
+type Report struct {
+	Findings []finding.Finding `json:"findings"`
+	Reviews  []FileReview      `json:"reviews,omitempty"` // 非结构化的审查结论
+	Dropped  []finding.Finding `json:"dropped,omitempty"` // 核实后因置信度过低被移除的发现
+	Models   []ModelInfo       `json:"models,omitempty"`  // 参与审查的模型及实际使用的生成参数
+}
+
+// ModelInfo 参与审查的模型
+type ModelInfo struct {
+	Provider string              `json:"provider"`
+	Model    string              `json:"model"`
+	Params   *config.ModelParams `json:"params,omitempty"`   // 未设置任何参数时省略
+	Fallback bool                `json:"fallback,omitempty"` // 主模型失败后改用的回退模型
+}
+
+// NewModelInfo 记录配置中的模型及生成参数
+func NewModelInfo(providerName string, cfg *config.Config) ModelInfo {
+	info := ModelInfo{Provider: providerName, Model: cfg.Model}
+	if !cfg.Params.IsZero() {
+		params := cfg.Params
+		info.Params = &params
+	}
+	return info
+}
+
+// FileReview 单次审查或无法解析为结构化结果时的原始结论
+type FileReview struct {
+	Path    string `json:"path"`
+	Pass    string `json:"pass,omitempty"`
+	Content string `json:"content"`
+	Model   string `json:"model,omitempty"` // 由回退模型给出结论时为该模型
+}
+
+// NewReport 汇总审查结果
+func NewReport(results []FileResult) *Report {
+	report := &Report{Findings: AllFindings(results)}
+	if report.Findings == nil {
+		report.Findings = []finding.Finding{}
+	}
+	for _, res := range results {
+		if !res.Structured {
+			report.Reviews = append(report.Reviews, FileReview{Path: res.Path, Pass: res.Pass, Content: res.Content, Model: res.Model})
+		}
+	}
+	return report
+}
+
+// JSON 输出缩进格式的 JSON
+func (r *Report) JSON() (string, error) {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// AttachDiagnostics 将静态分析诊断与模型的结构化发现去重后并入审查结果：
+// 与模型发现位置重合的诊断记录在该发现的 Related 中，其余诊断作为单独的发现补充
+func AttachDiagnostics(results []FileResult, diags map[string][]lint.Diagnostic) []FileResult {
+	for _, path := range paths(results) {
+		var extra []finding.Finding
+		for _, d := range diags[path] {
+			if !relate(results, d) {
+				extra = append(extra, d.Finding())
+			}
+		}
+		if len(extra) > 0 {
+			results = append(results, FileResult{Path: path, Pass: "lint", Findings: extra, Structured: true})
+		}
+	}
+	return results
+}
+
+// relate 查找与诊断位置重合的模型发现并记录该诊断，找到时返回 true
+func relate(results []FileResult, d lint.Diagnostic) bool {
+	related := false
+	for i := range results {
+		for j := range results[i].Findings {
+			f := &results[i].Findings[j]
+			if f.Source == "" && f.Covers(d.Path, d.Line) {
+				f.Related = append(f.Related, d.Label())
+				related = true
+			}
+		}
+	}
+	return related
+}
+
+// ModelFindings 返回模型给出的结构化发现的指针，静态分析工具的诊断不包含在内
+func ModelFindings(results []FileResult) []*finding.Finding {
+	var list []*finding.Finding
+	for i := range results {
+		for j := range results[i].Findings {
+			if results[i].Findings[j].Source == "" {
+				list = append(list, &results[i].Findings[j])
+			}
+		}
+	}
+	return list
+}
+
+// FilterFindings 对所有结构化发现调用 keep（可修改发现），移除返回 false 的发现
+func FilterFindings(results []FileResult, keep func(f *finding.Finding) bool) {
+	for i := range results {
+		kept := results[i].Findings[:0]
+		for _, f := range results[i].Findings {
+			if keep(&f) {
+				kept = append(kept, f)
+			}
+		}
+		results[i].Findings = kept
+	}
+}
+
+// Consensus 合并多个模型对同一 diff 的审查结果：每个文件的结构化发现按位置和相似度聚类，
+// 只保留至少 minAgreement 个模型给出的发现。无法解析为结构化结果的结论原样保留，
+// 审查维度标注为 "模型/维度"
+func Consensus(models []string, results [][]FileResult, minAgreement int) []FileResult {
+	var all []FileResult
+	for _, rs := range results {
+		all = append(all, rs...)
+	}
+
+	var merged []FileResult
+	for _, path := range paths(all) {
+		runs := make([]consensus.Run, len(models))
+		var raw []FileResult
+		structured := false
+		for i, rs := range results {
+			runs[i].Model = models[i]
+			for _, res := range rs {
+				if res.Path != path {
+					continue
+				}
+				if !res.Structured {
+					res.Pass = models[i] + "/" + res.Pass
+					raw = append(raw, res)
+					continue
+				}
+				structured = true
+				runs[i].Findings = append(runs[i].Findings, res.Findings...)
+			}
+		}
+		if structured {
+			findings := consensus.Filter(consensus.Cluster(runs), minAgreement)
+			merged = append(merged, FileResult{Path: path, Pass: "consensus", Findings: findings, Structured: true})
+		}
+		merged = append(merged, raw...)
+	}
+	return merged
+}
@@ -0,0 +1,375 @@
+package review
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"ai_code_reviewer/internal/agent"
+	"ai_code_reviewer/internal/cache"
+	"ai_code_reviewer/internal/config"
+	"ai_code_reviewer/internal/finding"
+	"ai_code_reviewer/internal/focus"
+	"ai_code_reviewer/internal/gitutil"
+	"ai_code_reviewer/internal/provider"
+	"ai_code_reviewer/internal/usage"
+)
+
+// Reviewer 按文件审查 diff，命中缓存的文件直接复用上次的审查结果
+type Reviewer struct {
+	cfg      *config.Config
+	provider provider.Provider
+	cache    *cache.Cache      // 为 nil 时不使用缓存
+	toolbox  *agent.Toolbox    // 为 nil 时不向模型提供工具
+	maxTools int               // 每个文件最多的工具调用轮数
+	passes   []focus.Pass      // 专项审查维度，为空时使用配置中的提示词进行单次审查
+	previous map[string]string // 上次审查的各文件结论，增量审查时作为上下文
+	contexts map[string]string // 各文件附加的仓库上下文代码
+	used     usage.Usage       // 本次审查累计的 token 用量
+	requests int               // 本次审查实际发出的请求数
+	calls    int               // 本次审查执行的工具调用次数
+}
+
+// FileResult 单个文件（在某个审查维度下）的审查结果
+type FileResult struct {
+	Path       string
+	Pass       string // 审查维度，为空表示单次审查
+	Content    string
+	Cached     bool
+	Findings   []finding.Finding
+	Structured bool   // Content 已成功解析为 Findings
+	Model      string // 主模型失败后由回退模型给出结果时为该模型
+}
+
+// NewReviewer 创建审查器，c 为 nil 表示禁用缓存
+func NewReviewer(cfg *config.Config, p provider.Provider, c *cache.Cache) *Reviewer {
+	return &Reviewer{
+		cfg:      cfg,
+		provider: p,
+		cache:    c,
+	}
+}
+
+// SetToolbox 允许模型在审查时调用工具读取仓库内容，maxIterations 为每个文件的工具调用轮数上限
+func (r *Reviewer) SetToolbox(tb *agent.Toolbox, maxIterations int) {
+	r.toolbox = tb
+	r.maxTools = maxIterations
+}
+
+// SetPasses 设置专项审查维度，每个文件在每个维度下各审查一次，结果为结构化的发现
+func (r *Reviewer) SetPasses(passes []focus.Pass) {
+	r.passes = passes
+}
+
+// SetPrevious 设置上次审查的各文件结论，模型会据此说明哪些问题已解决
+func (r *Reviewer) SetPrevious(findings map[string]string) {
+	r.previous = findings
+}
+
+// SetContexts 设置各文件附加的仓库上下文，随 diff 一起发送
+func (r *Reviewer) SetContexts(contexts map[string]string) {
+	r.contexts = contexts
+}
+
+// OpenCache 根据配置打开审查结果缓存
+func OpenCache(cfg *config.Config) (*cache.Cache, error) {
+	var ttl time.Duration
+	if cfg.CacheTTL != "" {
+		d, err := time.ParseDuration(cfg.CacheTTL)
+		if err != nil {
+			return nil, fmt.Errorf("无效的 cache_ttl: %s", cfg.CacheTTL)
+		}
+		ttl = d
+	}
+	maxSize := int64(cfg.CacheMaxSize) * 1024 * 1024
+	return cache.New(config.HomePath(config.DefaultCacheDir), ttl, maxSize), nil
+}
+
+// Request 一次待发送给模型的审查请求
+type Request struct {
+	Index  int // 对应文件在审查结果中的下标
+	Path   string
+	Pass   string
+	System string
+	User   string
+	key    string
+}
+
+// Plan 审查计划，包含命中缓存的结果和需要发送给模型的请求
+type Plan struct {
+	Results []FileResult
+	Pending []Request
+}
+
+// Prepare 查询缓存并生成审查计划，不会调用模型
+func (r *Reviewer) Prepare(files []gitutil.FileDiff) *Plan {
+	plan := &Plan{}
+
+	for _, f := range files {
+		if len(r.passes) == 0 {
+			user := f.Content
+			if ctx := r.contexts[f.Path]; ctx != "" {
+				user += "\n\n" + ctx
+			}
+			r.plan(plan, f, "", r.promptFor(f.Path), user)
+			continue
+		}
+
+		// 结构化输出需要行号，发送带行号标注的 diff
+		user := f.Numbered()
+		if ctx := r.contexts[f.Path]; ctx != "" {
+			user += "\n\n" + ctx
+		}
+		for _, p := range r.passes {
+			r.plan(plan, f, p.Name, r.passPrompt(p, f.Path), user)
+		}
+	}
+	return plan
+}
+
+// plan 为单个文件在单个维度下的审查查询缓存，未命中时加入待发送请求
+func (r *Reviewer) plan(plan *Plan, f gitutil.FileDiff, pass, prompt, user string) {
+	index := len(plan.Results)
+	plan.Results = append(plan.Results, FileResult{Path: f.Path, Pass: pass})
+
+	key := r.cacheKey(f, r.contexts[f.Path], prompt)
+	if r.cache != nil {
+		if content, ok := r.cache.Get(key); ok {
+			plan.Results[index].Content = content
+			plan.Results[index].Cached = true
+			plan.Results[index].parse()
+			return
+		}
+	}
+	plan.Pending = append(plan.Pending, Request{
+		Index:  index,
+		Path:   f.Path,
+		Pass:   pass,
+		System: prompt,
+		User:   user,
+		key:    key,
+	})
+}
+
+// parse 将专项维度的输出解析为结构化发现
+func (res *FileResult) parse() {
+	if res.Pass == "" {
+		return
+	}
+	findings, err := finding.Parse(res.Content, res.Path, res.Pass)
+	res.Findings = findings
+	res.Structured = err == nil
+}
+
+// Messages 返回请求对应的对话消息
+func (req Request) Messages() []provider.Message {
+	return []provider.Message{
+		{Role: provider.RoleSystem, Content: req.System},
+		{Role: provider.RoleUser, Content: req.User},
+	}
+}
+
+// Execute 逐个发送计划中的请求，progress 在开始调用模型前及每完成一个文件后回调
+func (r *Reviewer) Execute(ctx context.Context, plan *Plan, progress func(done, total int)) ([]FileResult, error) {
+	results := plan.Results
+	if len(plan.Pending) == 0 {
+		return results, nil
+	}
+
+	if progress != nil {
+		progress(0, len(plan.Pending))
+	}
+	for n, req := range plan.Pending {
+		content, model, err := r.complete(ctx, req)
+		if err != nil {
+			return nil, fmt.Errorf("审查 %s 失败: %w", req.Path, err)
+		}
+		results[req.Index].Content = content
+		results[req.Index].Model = model
+		results[req.Index].parse()
+
+		// 无法解析的结构化输出和回退模型给出的结果不写入缓存，下次重新请求；缓存写入失败不影响本次审查
+		if r.cache != nil && model == "" && (req.Pass == "" || results[req.Index].Structured) {
+			_ = r.cache.Put(req.key, content)
+		}
+		if progress != nil {
+			progress(n+1, len(plan.Pending))
+		}
+	}
+
+	return results, nil
+}
+
+// complete 发送单个文件的审查请求，启用工具时进入工具调用循环。
+// 返回回复内容及回退模型的名称，由主模型给出回复时模型名称为空
+func (r *Reviewer) complete(ctx context.Context, req Request) (string, string, error) {
+	call := provider.Request{Model: r.cfg.Model, Messages: req.Messages()}
+
+	if r.toolbox != nil {
+		res, err := agent.Run(ctx, r.provider, call, r.toolbox, r.maxTools)
+		r.used.Add(res.Usage)
+		r.requests += res.Requests
+		r.calls += res.ToolCalls
+		if err != nil {
+			return "", "", err
+		}
+		return res.Content, res.Model, nil
+	}
+
+	resp, err := r.provider.Complete(ctx, call)
+	r.requests++
+	if resp != nil {
+		r.used.Add(resp.Usage)
+	}
+	if err != nil {
+		return "", "", err
+	}
+	return resp.Content, resp.Model, nil
+}
+
+// ToolCalls 返回本次审查中模型调用工具的次数
+func (r *Reviewer) ToolCalls() int {
+	return r.calls
+}
+
+// Usage 返回本次审查累计的 token 用量和请求数，命中缓存的文件不计入
+func (r *Reviewer) Usage() (usage.Usage, int) {
+	return r.used, r.requests
+}
+
+// promptFor 生成文件的系统提示词，存在上次审查结论时附加在提示词之后
+func (r *Reviewer) promptFor(path string) string {
+	prev, ok := r.previous[path]
+	if !ok || strings.TrimSpace(prev) == "" {
+		return r.cfg.Prompt
+	}
+	return r.cfg.Prompt + "\n\n以下是该文件上次审查的结论。本次 diff 只包含此后的新提交，" +
+		"请先逐条说明这些问题哪些已解决、哪些仍然存在，再审查新的变更：\n\n" + prev
+}
+
+// passPrompt 生成专项维度的系统提示词，存在上次审查结论时附加在提示词之后
+func (r *Reviewer) passPrompt(p focus.Pass, path string) string {
+	prompt := p.SystemPrompt()
+	prev, ok := r.previous[path]
+	if !ok || strings.TrimSpace(prev) == "" {
+		return prompt
+	}
+	return prompt + "\n\n以下是该文件上次审查的结论，本次 diff 只包含此后的新提交。" +
+		"仍然存在的问题请再次输出，已解决的问题不要输出：\n\n" + prev
+}
+
+// cacheKey 由归一化的文件 diff、附加的上下文、模型、提示词、服务提供方（含地址和部署）、是否启用工具
+// 以及生成参数共同决定。未设置生成参数时不参与计算，已有的缓存仍然有效
+func (r *Reviewer) cacheKey(f gitutil.FileDiff, repoContext, prompt string) string {
+	tools := "tools=off"
+	if r.toolbox != nil {
+		tools = "tools=on"
+	}
+	parts := []string{f.Normalized(), repoContext, r.cfg.Model, prompt, r.provider.Name(), r.cfg.Url, r.cfg.Deployment, tools}
+	if !r.cfg.Params.IsZero() {
+		parts = append(parts, r.cfg.Params.String())
+	}
+	return cache.Key(parts...)
+}
+
+// Render 将各文件审查结果合并为一份 Markdown，同一文件多个维度的发现合并后按行号排列
+func Render(results []FileResult) string {
+	var b strings.Builder
+	for _, path := range paths(results) {
+		fmt.Fprintf(&b, "## `%s`", path)
+		if models := fallbackModels(results, path); len(models) > 0 {
+			fmt.Fprintf(&b, "（由 %s 审查）", strings.Join(models, "、"))
+		}
+		b.WriteString("\n\n")
+		b.WriteString(renderFile(results, path))
+		b.WriteString("\n\n")
+	}
+	return b.String()
+}
+
+// Findings 将审查结果转换为 文件路径 -> 结论 的映射，用于记录审查状态
+func Findings(results []FileResult) map[string]string {
+	findings := make(map[string]string)
+	for _, path := range paths(results) {
+		findings[path] = renderFile(results, path)
+	}
+	return findings
+}
+
+// AllFindings 汇总所有结构化发现，按文件和行号排序
+func AllFindings(results []FileResult) []finding.Finding {
+	var all []finding.Finding
+	for _, res := range results {
+		all = append(all, res.Findings...)
+	}
+	finding.Sort(all)
+	return all
+}
+
+// fallbackModels 返回给出该文件结果的回退模型
+func fallbackModels(results []FileResult, path string) []string {
+	var models []string
+	for _, res := range results {
+		if res.Path == path && res.Model != "" && !slices.Contains(models, res.Model) {
+			models = append(models, res.Model)
+		}
+	}
+	return models
+}
+
+// paths 按首次出现的顺序返回结果涉及的文件
+func paths(results []FileResult) []string {
+	var list []string
+	seen := make(map[string]bool)
+	for _, res := range results {
+		if !seen[res.Path] {
+			seen[res.Path] = true
+			list = append(list, res.Path)
+		}
+	}
+	return list
+}
+
+// renderFile 渲染单个文件的审查结论
+func renderFile(results []FileResult, path string) string {
+	var b strings.Builder
+	var findings []finding.Finding
+	structured := false
+	for _, res := range results {
+		if res.Path != path {
+			continue
+		}
+		switch {
+		case res.Pass == "":
+			b.WriteString(strings.TrimSpace(res.Content))
+		case res.Structured:
+			structured = true
+			findings = append(findings, res.Findings...)
+		default:
+			// 模型未按要求输出 JSON，保留原始输出
+			fmt.Fprintf(&b, "### %s（未能解析为结构化结果）\n\n%s\n\n", res.Pass, strings.TrimSpace(res.Content))
+		}
+	}
+	if structured {
+		if len(findings) == 0 {
+			b.WriteString("未发现问题。")
+		} else {
+			finding.Sort(findings)
+			b.WriteString(finding.Markdown(findings))
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// CountCached 统计命中缓存的文件数
+func CountCached(results []FileResult) int {
+	n := 0
+	for _, res := range results {
+		if res.Cached {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,124 @@
+package review
+
+import (
+	"context"
+	"flag"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ai_code_reviewer/internal/cassette"
+	"ai_code_reviewer/internal/config"
+	"ai_code_reviewer/internal/focus"
+	"ai_code_reviewer/internal/gitutil"
+	"ai_code_reviewer/internal/provider"
+)
+
+var update = flag.Bool("update", false, "用当前输出更新 testdata 中的期望结果")
+
+// loadDiff 读取 testdata 中的 diff 并按文件拆分
+func loadDiff(t *testing.T, name string) []gitutil.FileDiff {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return gitutil.SplitDiff(string(data))
+}
+
+// golden 比较输出与 testdata 中的期望结果，指定 -update 时改为写入
+func golden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != string(want) {
+		t.Errorf("%s 与期望不符，确认无误后使用 go test -update 更新\ngot:\n%s\nwant:\n%s", name, got, want)
+	}
+}
+
+func TestReviewWithFake(t *testing.T) {
+	files := loadDiff(t, "change.diff")
+	if len(files) != 2 || files[0].Path != "store/store.go" || files[1].Path != "docs/old.md" {
+		t.Fatalf("SplitDiff 拆分结果 = %+v", files)
+	}
+	if !strings.HasPrefix(files[1].Content, "diff --git a/docs/old.md") || strings.Contains(files[0].Content, "old.md") {
+		t.Errorf("拆分后的 diff 应各自只包含一个文件")
+	}
+
+	passes, err := focus.Resolve([]string{focus.General, "security"}, nil, "请审查以下代码变更")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 按 文件 × 维度 的顺序回复
+	fake := provider.NewFake(
+		`{"findings": [{"line": 14, "severity": "low", "category": "error-handling", "title": "ErrNotFound 未携带 id"}]}`,
+		"```json\n"+`{"findings": [{"line": 37, "severity": "CRITICAL", "category": "sql-injection", "title": "拼接 SQL 语句", "detail": "name 来自调用方", "suggestion": "使用占位符参数"}]}`+"\n```",
+		`{"findings": []}`,
+		"文件已删除，无需审查",
+	)
+	r := NewReviewer(&config.Config{Model: "fake-model"}, fake, nil)
+	r.SetPasses(passes)
+
+	plan := r.Prepare(files)
+	if len(plan.Pending) != 4 || len(plan.Results) != 4 {
+		t.Fatalf("pending = %d, results = %d，应各为 4", len(plan.Pending), len(plan.Results))
+	}
+	if user := plan.Pending[1].User; !strings.Contains(user, "   37 +\treturn s.db.Query(") {
+		t.Errorf("结构化审查应发送带行号的 diff:\n%s", user)
+	}
+
+	results, err := r.Execute(context.Background(), plan, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	requests := fake.Requests()
+	if len(requests) != 4 {
+		t.Fatalf("发出 %d 个请求，应为 4", len(requests))
+	}
+	if requests[1].Messages[0].Content != passes[1].SystemPrompt() {
+		t.Error("第二个请求应使用 security 维度的提示词")
+	}
+	if _, n := r.Usage(); n != 4 {
+		t.Errorf("requests = %d, want 4", n)
+	}
+
+	golden(t, "review.golden.md", Render(results))
+	report, err := NewReport(results).JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden(t, "report.golden.json", report+"\n")
+}
+
+func TestReviewReplayCassette(t *testing.T) {
+	// 回放不发出网络请求，服务地址与录制时不同也能按路径匹配
+	replayer, err := cassette.NewReplayer(filepath.Join("testdata", "review.cassette.json"), "sk-test-0123456789")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := provider.NewOpenAI("sk-test-0123456789", "https://llm.example.com/v1", &http.Client{Transport: replayer})
+
+	files := loadDiff(t, "change.diff")[:1]
+	r := NewReviewer(&config.Config{Model: "gpt-4o-mini", Prompt: "请审查以下代码变更"}, p, nil)
+	results, err := r.Execute(context.Background(), r.Prepare(files), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	golden(t, "replay.golden.md", Render(results))
+	used, n := r.Usage()
+	if n != 1 || used.PromptTokens != 412 || used.CompletionTokens != 58 {
+		t.Errorf("usage = %+v, requests = %d", used, n)
+	}
+}
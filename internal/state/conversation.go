@@ -0,0 +1,82 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"ai_code_reviewer/internal/finding"
+	"ai_code_reviewer/internal/gitutil"
+	"ai_code_reviewer/internal/provider"
+)
+
+// 最近一次审查对话相对 .git 目录的路径
+const conversationFile = "acr/last_review.json"
+
+// ErrNoConversation 尚未进行过审查
+var ErrNoConversation = errors.New("未找到上次审查记录，请先运行 acr review")
+
+// Conversation 最近一次审查的对话记录，供 acr chat 继续追问
+type Conversation struct {
+	Model     string             `json:"model"`
+	CreatedAt time.Time          `json:"created_at"`
+	Messages  []provider.Message `json:"messages"`
+	Findings  []finding.Finding  `json:"findings,omitempty"` // 结构化发现（含基线中的发现），供 acr baseline create 使用
+
+	// 被审查的代码，acr fix 据此确认当前工作区与审查时一致
+	Ref         string `json:"ref,omitempty"`          // 被审查的引用，为空表示当前 HEAD
+	Commit      string `json:"commit,omitempty"`       // 审查时被审查引用对应的提交
+	WorkingTree bool   `json:"working_tree,omitempty"` // 审查的是工作区的未提交变更
+	DiffDigest  string `json:"diff_digest,omitempty"`  // 审查工作区时 diff 的摘要
+}
+
+// SaveConversation 保存最近一次审查的对话
+func SaveConversation(c *Conversation) error {
+	path, err := conversationPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("创建状态目录失败: %w", err)
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("写入审查对话失败: %w", err)
+	}
+	return nil
+}
+
+// LoadConversation 读取最近一次审查的对话，不存在时返回 ErrNoConversation
+func LoadConversation() (*Conversation, error) {
+	path, err := conversationPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNoConversation
+		}
+		return nil, fmt.Errorf("读取审查对话失败: %w", err)
+	}
+
+	var c Conversation
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("解析审查对话失败: %w", err)
+	}
+	return &c, nil
+}
+
+func conversationPath() (string, error) {
+	gitDir, err := gitutil.GitDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(gitDir, conversationFile), nil
+}
@@ -0,0 +1,81 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"ai_code_reviewer/internal/gitutil"
+)
+
+// 状态文件相对 .git 目录的路径
+const stateFile = "acr/state.json"
+
+// BranchState 某个分支最近一次审查的记录
+type BranchState struct {
+	Commit     string            `json:"commit"`
+	ReviewedAt time.Time         `json:"reviewed_at"`
+	Findings   map[string]string `json:"findings"` // 文件路径 -> 审查结论
+}
+
+// Store 保存在 .git/acr/state.json 中的审查状态
+type Store struct {
+	path     string
+	Branches map[string]BranchState `json:"branches"`
+}
+
+// Load 读取当前仓库的审查状态，文件不存在时返回空状态
+func Load() (*Store, error) {
+	gitDir, err := gitutil.GitDir()
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Store{
+		path:     filepath.Join(gitDir, stateFile),
+		Branches: map[string]BranchState{},
+	}
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return nil, fmt.Errorf("读取审查状态失败: %w", err)
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("解析审查状态失败: %w", err)
+	}
+	if s.Branches == nil {
+		s.Branches = map[string]BranchState{}
+	}
+	return s, nil
+}
+
+// Get 获取分支最近一次审查记录
+func (s *Store) Get(branch string) (BranchState, bool) {
+	st, ok := s.Branches[branch]
+	return st, ok
+}
+
+// Set 更新分支审查记录
+func (s *Store) Set(branch string, st BranchState) {
+	s.Branches[branch] = st
+}
+
+// Save 写回状态文件
+func (s *Store) Save() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("创建状态目录失败: %w", err)
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(s.path, data, 0644); err != nil {
+		return fmt.Errorf("写入审查状态失败: %w", err)
+	}
+	return nil
+}
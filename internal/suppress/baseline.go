@@ -0,0 +1,123 @@
+package suppress
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"ai_code_reviewer/internal/finding"
+	"ai_code_reviewer/internal/gitutil"
+)
+
+// 基线文件名，位于仓库根目录，随代码一起提交
+const BaselineFile = ".acr-baseline.json"
+
+// Baseline 已知发现的基线，基线中的发现不再出现在审查结果中
+type Baseline struct {
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	Findings  []Entry   `json:"findings"`
+}
+
+// Entry 基线中的一条发现，除指纹外的字段便于人工查看
+type Entry struct {
+	Fingerprint string `json:"fingerprint"`
+	Path        string `json:"path"`
+	Category    string `json:"category"`
+	Title       string `json:"title"`
+}
+
+// NewBaseline 由发现生成基线，发现必须已计算指纹
+func NewBaseline(findings []finding.Finding) *Baseline {
+	b := &Baseline{Version: 1, CreatedAt: time.Now()}
+	seen := make(map[string]bool)
+	for _, f := range findings {
+		if f.Fingerprint == "" || seen[f.Fingerprint] {
+			continue
+		}
+		seen[f.Fingerprint] = true
+		b.Findings = append(b.Findings, Entry{
+			Fingerprint: f.Fingerprint,
+			Path:        f.Path,
+			Category:    f.Category,
+			Title:       f.Title,
+		})
+	}
+	sortEntries(b.Findings)
+	return b
+}
+
+// sortEntries 按路径和指纹排序，使基线文件的差异便于审阅
+func sortEntries(entries []Entry) {
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Path != entries[j].Path {
+			return entries[i].Path < entries[j].Path
+		}
+		return entries[i].Fingerprint < entries[j].Fingerprint
+	})
+}
+
+// Merge 将 other 中不在基线里的发现加入基线，返回新增的条数
+func (b *Baseline) Merge(other *Baseline) int {
+	seen := make(map[string]bool, len(b.Findings))
+	for _, e := range b.Findings {
+		seen[e.Fingerprint] = true
+	}
+	added := 0
+	for _, e := range other.Findings {
+		if !seen[e.Fingerprint] {
+			seen[e.Fingerprint] = true
+			b.Findings = append(b.Findings, e)
+			added++
+		}
+	}
+	sortEntries(b.Findings)
+	return added
+}
+
+// LoadBaseline 读取仓库根目录下的基线文件，不存在时返回 nil
+func LoadBaseline() (*Baseline, error) {
+	path, err := baselinePath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("读取 %s 失败: %w", BaselineFile, err)
+	}
+	var b Baseline
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("解析 %s 失败: %w", BaselineFile, err)
+	}
+	return &b, nil
+}
+
+// Save 写入仓库根目录下的基线文件，返回文件路径
+func (b *Baseline) Save() (string, error) {
+	path, err := baselinePath()
+	if err != nil {
+		return "", err
+	}
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return "", fmt.Errorf("写入 %s 失败: %w", BaselineFile, err)
+	}
+	return path, nil
+}
+
+func baselinePath() (string, error) {
+	top, err := gitutil.TopLevel()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(top, BaselineFile), nil
+}
@@ -0,0 +1,132 @@
+package suppress
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strings"
+
+	"ai_code_reviewer/internal/finding"
+	"ai_code_reviewer/internal/gitutil"
+)
+
+// directiveRe 匹配 "acr:ignore <category> reason"，category 为 * 或 all 时忽略所有分类
+var directiveRe = regexp.MustCompile(`acr:ignore\s+(\S+)`)
+
+// Suppressor 根据源码中的 acr:ignore 标注和基线文件过滤发现
+type Suppressor struct {
+	ref      string              // 读取源码的引用，为空时读取工作区
+	baseline map[string]bool     // 基线中的指纹
+	files    map[string][]string // 已读取的文件内容，按行拆分
+}
+
+// New 创建过滤器，baseline 为 nil 时只处理行内标注
+func New(ref string, baseline *Baseline) *Suppressor {
+	s := &Suppressor{
+		ref:      ref,
+		baseline: make(map[string]bool),
+		files:    make(map[string][]string),
+	}
+	if baseline != nil {
+		for _, e := range baseline.Findings {
+			s.baseline[e.Fingerprint] = true
+		}
+	}
+	return s
+}
+
+// Fingerprint 计算发现的指纹：由文件路径、分类和归一化后的代码片段决定，
+// 代码整体上下移动时指纹保持不变。取不到代码片段（文件已删除、行号超出文件）时改用归一化后的标题，
+// 避免一条基线屏蔽该文件中同一分类的所有发现
+func (s *Suppressor) Fingerprint(f finding.Finding) string {
+	anchor := s.snippet(f)
+	if anchor == "" {
+		anchor = "title:" + strings.ToLower(strings.Join(strings.Fields(f.Title), " "))
+	}
+	h := sha256.New()
+	h.Write([]byte(f.Path))
+	h.Write([]byte{0})
+	h.Write([]byte(strings.ToLower(f.Category)))
+	h.Write([]byte{0})
+	h.Write([]byte(anchor))
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// Baselined 判断指纹是否在基线中
+func (s *Suppressor) Baselined(fingerprint string) bool {
+	return s.baseline[fingerprint]
+}
+
+// Ignored 判断发现是否被 acr:ignore 标注忽略。标注可以写在问题所在行的行尾，
+// 也可以单独写在问题所在行的上一行
+func (s *Suppressor) Ignored(f finding.Finding) bool {
+	lines := s.lines(f.Path)
+	start, end := f.Line-1, f.EndLine
+	if end < f.Line {
+		end = f.Line
+	}
+	for n := start; n <= end; n++ {
+		if n < 1 || n > len(lines) {
+			continue
+		}
+		// 上一行的标注必须独占一行，行尾标注只作用于所在行
+		if n == f.Line-1 && !isComment(lines[n-1]) {
+			continue
+		}
+		m := directiveRe.FindStringSubmatch(lines[n-1])
+		if m == nil {
+			continue
+		}
+		category := m[1]
+		if category == "*" || strings.EqualFold(category, "all") || strings.EqualFold(category, f.Category) {
+			return true
+		}
+	}
+	return false
+}
+
+// commentPrefixes 常见语言的注释起始符
+var commentPrefixes = []string{"//", "#", "--", "/*", "*", "<!--", ";"}
+
+// isComment 判断该行是否只包含注释
+func isComment(line string) bool {
+	line = strings.TrimSpace(line)
+	for _, p := range commentPrefixes {
+		if strings.HasPrefix(line, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// snippet 返回发现所在行归一化后的代码：去掉每行的空白并拼接
+func (s *Suppressor) snippet(f finding.Finding) string {
+	lines := s.lines(f.Path)
+	end := f.EndLine
+	if end < f.Line {
+		end = f.Line
+	}
+	var parts []string
+	for n := f.Line; n <= end && n <= len(lines); n++ {
+		if n < 1 {
+			continue
+		}
+		if line := strings.Join(strings.Fields(lines[n-1]), " "); line != "" {
+			parts = append(parts, line)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// lines 读取并缓存文件内容，文件不存在（如已删除）时返回空
+func (s *Suppressor) lines(path string) []string {
+	if lines, ok := s.files[path]; ok {
+		return lines
+	}
+	var lines []string
+	if data, err := gitutil.ShowFile(s.ref, path); err == nil {
+		lines = strings.Split(string(data), "\n")
+	}
+	s.files[path] = lines
+	return lines
+}
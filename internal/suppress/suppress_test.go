@@ -0,0 +1,145 @@
+package suppress
+
+import (
+	"testing"
+
+	"ai_code_reviewer/internal/finding"
+)
+
+// newTestSuppressor 使用给定的文件内容，不读取仓库
+func newTestSuppressor(files map[string][]string) *Suppressor {
+	s := New("", nil)
+	for path, lines := range files {
+		s.files[path] = lines
+	}
+	return s
+}
+
+func TestFingerprint(t *testing.T) {
+	s := newTestSuppressor(map[string][]string{
+		"a.go": {"package a", "", "func f() {", "\treturn  nil", "}"},
+	})
+	base := finding.Finding{Path: "a.go", Line: 4, Category: "Bug", Title: "返回值未检查"}
+
+	tests := []struct {
+		name string
+		f    finding.Finding
+		same bool
+	}{
+		{name: "标题不同但代码相同", f: finding.Finding{Path: "a.go", Line: 4, Category: "bug", Title: "另一种说法"}, same: true},
+		{name: "分类不同", f: finding.Finding{Path: "a.go", Line: 4, Category: "style", Title: base.Title}, same: false},
+		{name: "代码行不同", f: finding.Finding{Path: "a.go", Line: 3, Category: "bug", Title: base.Title}, same: false},
+	}
+	want := s.Fingerprint(base)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.Fingerprint(tt.f); (got == want) != tt.same {
+				t.Errorf("Fingerprint 相同 = %v，应为 %v", got == want, tt.same)
+			}
+		})
+	}
+
+	// 代码整体下移、空白不同，指纹不变
+	moved := newTestSuppressor(map[string][]string{"a.go": {"// 新增注释", "package a", "", "func f() {", "return nil", "}"}})
+	if got := moved.Fingerprint(finding.Finding{Path: "a.go", Line: 5, Category: "bug"}); got != want {
+		t.Errorf("代码移动后指纹变化: %s != %s", got, want)
+	}
+}
+
+func TestFingerprintWithoutSnippet(t *testing.T) {
+	// 文件已删除或行号超出文件时取不到代码片段
+	s := newTestSuppressor(map[string][]string{"gone.go": nil, "a.go": {"package a"}})
+	tests := []struct {
+		name   string
+		a, b   finding.Finding
+		wantEq bool
+	}{
+		{
+			name:   "已删除文件中不同标题的发现",
+			a:      finding.Finding{Path: "gone.go", Line: 3, Category: "bug", Title: "空指针"},
+			b:      finding.Finding{Path: "gone.go", Line: 9, Category: "bug", Title: "资源泄漏"},
+			wantEq: false,
+		},
+		{
+			name:   "行号超出文件",
+			a:      finding.Finding{Path: "a.go", Line: 40, Category: "bug", Title: "空指针"},
+			b:      finding.Finding{Path: "a.go", Line: 50, Category: "bug", Title: "资源泄漏"},
+			wantEq: false,
+		},
+		{
+			name:   "标题只有大小写和空白不同",
+			a:      finding.Finding{Path: "gone.go", Line: 3, Category: "bug", Title: "Nil  pointer"},
+			b:      finding.Finding{Path: "gone.go", Line: 7, Category: "bug", Title: "nil pointer"},
+			wantEq: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if eq := s.Fingerprint(tt.a) == s.Fingerprint(tt.b); eq != tt.wantEq {
+				t.Errorf("指纹相同 = %v，应为 %v", eq, tt.wantEq)
+			}
+		})
+	}
+}
+
+func TestIgnored(t *testing.T) {
+	s := newTestSuppressor(map[string][]string{
+		"a.go": {
+			"// acr:ignore sql 内部常量",
+			"rows := db.Query(q)",
+			"x := 1 // acr:ignore * 已知",
+			"y := 2",
+			"z := 3 // acr:ignore style",
+			"w := 4",
+		},
+	})
+	tests := []struct {
+		name string
+		f    finding.Finding
+		want bool
+	}{
+		{name: "上一行的标注", f: finding.Finding{Path: "a.go", Line: 2, Category: "SQL"}, want: true},
+		{name: "分类不匹配", f: finding.Finding{Path: "a.go", Line: 2, Category: "bug"}},
+		{name: "行尾通配标注", f: finding.Finding{Path: "a.go", Line: 3, Category: "bug"}, want: true},
+		{name: "上一行的行尾标注不作用于下一行", f: finding.Finding{Path: "a.go", Line: 4, Category: "bug"}},
+		{name: "范围内的标注", f: finding.Finding{Path: "a.go", Line: 4, EndLine: 5, Category: "style"}, want: true},
+		{name: "没有标注", f: finding.Finding{Path: "a.go", Line: 6, Category: "style"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.Ignored(tt.f); got != tt.want {
+				t.Errorf("Ignored = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBaselineMerge(t *testing.T) {
+	existing := NewBaseline([]finding.Finding{
+		{Path: "b.go", Category: "bug", Title: "旧发现", Fingerprint: "2"},
+		{Path: "a.go", Category: "bug", Title: "旧发现", Fingerprint: "1"},
+	})
+	latest := NewBaseline([]finding.Finding{
+		{Path: "a.go", Category: "bug", Title: "重复", Fingerprint: "1"},
+		{Path: "c.go", Category: "bug", Title: "新发现", Fingerprint: "3"},
+		{Path: "c.go", Category: "bug", Title: "没有指纹"},
+	})
+
+	if added := existing.Merge(latest); added != 1 {
+		t.Errorf("added = %d, want 1", added)
+	}
+	var got []string
+	for _, e := range existing.Findings {
+		got = append(got, e.Path+"#"+e.Fingerprint)
+	}
+	want := []string{"a.go#1", "b.go#2", "c.go#3"}
+	if len(got) != len(want) {
+		t.Fatalf("findings = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("findings = %v, want %v", got, want)
+			break
+		}
+	}
+}
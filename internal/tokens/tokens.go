@@ -0,0 +1,83 @@
+package tokens
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/pkoukk/tiktoken-go"
+	tiktoken_loader "github.com/pkoukk/tiktoken-go-loader"
+)
+
+// 聊天接口每条消息的固定开销，以及回复前的引导 token 数（参照 OpenAI 官方计算方式）
+const (
+	tokensPerMessage = 3
+	tokensPerReply   = 3
+)
+
+// o200kPrefixes 使用 o200k_base 编码的模型系列，其余模型按 cl100k_base 近似估算
+var o200kPrefixes = []string{"gpt-4o", "gpt-4.1", "gpt-4.5", "gpt-5", "chatgpt-", "o1", "o3", "o4"}
+
+var (
+	loaderOnce sync.Once
+	encoders   sync.Map // 编码名 -> *tiktoken.Tiktoken
+)
+
+// Message 参与估算的一条聊天消息
+type Message struct {
+	Role    string
+	Content string
+}
+
+// EncodingFor 返回模型系列对应的 BPE 编码名
+func EncodingFor(model string) string {
+	model = strings.ToLower(model)
+	for _, prefix := range o200kPrefixes {
+		if strings.HasPrefix(model, prefix) {
+			return tiktoken.MODEL_O200K_BASE
+		}
+	}
+	return tiktoken.MODEL_CL100K_BASE
+}
+
+// Count 使用本地 BPE 词表计算文本的 token 数
+func Count(model, text string) (int, error) {
+	enc, err := encoder(EncodingFor(model))
+	if err != nil {
+		return 0, err
+	}
+	return len(enc.EncodeOrdinary(text)), nil
+}
+
+// CountMessages 估算一组聊天消息作为输入时的 token 数
+func CountMessages(model string, messages []Message) (int, error) {
+	enc, err := encoder(EncodingFor(model))
+	if err != nil {
+		return 0, err
+	}
+
+	total := tokensPerReply
+	for _, m := range messages {
+		total += tokensPerMessage
+		total += len(enc.EncodeOrdinary(m.Role))
+		total += len(enc.EncodeOrdinary(m.Content))
+	}
+	return total, nil
+}
+
+func encoder(name string) (*tiktoken.Tiktoken, error) {
+	// 使用内嵌词表，避免运行时从网络下载
+	loaderOnce.Do(func() {
+		tiktoken.SetBpeLoader(tiktoken_loader.NewOfflineLoader())
+	})
+
+	if enc, ok := encoders.Load(name); ok {
+		return enc.(*tiktoken.Tiktoken), nil
+	}
+	enc, err := tiktoken.GetEncoding(name)
+	if err != nil {
+		return nil, fmt.Errorf("加载 %s 编码失败: %w", name, err)
+	}
+	encoders.Store(name, enc)
+	return enc, nil
+}
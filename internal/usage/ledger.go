@@ -0,0 +1,152 @@
+package usage
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Record 用量账本中的一条记录，对应一次命令执行中对某个模型的调用汇总
+type Record struct {
+	Time     time.Time `json:"time"`
+	Command  string    `json:"command"`
+	Provider string    `json:"provider"`
+	Model    string    `json:"model"`
+	Requests int       `json:"requests"`
+	Usage
+	Cost float64 `json:"cost"`
+}
+
+// Summary 按维度聚合后的用量
+type Summary struct {
+	Key      string
+	Requests int
+	Usage
+	Cost float64
+}
+
+// 支持的聚合维度
+var groupKeys = map[string]func(Record) string{
+	"model":    func(r Record) string { return r.Model },
+	"provider": func(r Record) string { return r.Provider },
+	"command":  func(r Record) string { return r.Command },
+	"day":      func(r Record) string { return r.Time.Local().Format("2006-01-02") },
+}
+
+// Append 追加一条记录到账本文件（JSON Lines 格式）
+func Append(path string, rec Record) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("创建账本目录失败: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("打开用量账本失败: %w", err)
+	}
+	defer f.Close()
+
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("写入用量账本失败: %w", err)
+	}
+	return nil
+}
+
+// ReadSince 读取账本中 since 之后的记录，账本不存在时返回空
+func ReadSince(path string, since time.Time) ([]Record, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("打开用量账本失败: %w", err)
+	}
+	defer f.Close()
+
+	var records []Record
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var rec Record
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			// 跳过损坏的行，避免一条坏记录导致整个账本不可用
+			continue
+		}
+		if rec.Time.Before(since) {
+			continue
+		}
+		records = append(records, rec)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取用量账本失败: %w", err)
+	}
+	return records, nil
+}
+
+// Summarize 按指定维度聚合记录，结果按费用从高到低排序
+func Summarize(records []Record, by string) ([]Summary, error) {
+	keyOf, ok := groupKeys[by]
+	if !ok {
+		return nil, fmt.Errorf("不支持的聚合维度: %s，可选: model，provider，command，day", by)
+	}
+
+	index := map[string]*Summary{}
+	var order []string
+	for _, rec := range records {
+		key := keyOf(rec)
+		s, ok := index[key]
+		if !ok {
+			s = &Summary{Key: key}
+			index[key] = s
+			order = append(order, key)
+		}
+		s.Requests += rec.Requests
+		s.Usage.Add(rec.Usage)
+		s.Cost += rec.Cost
+	}
+
+	summaries := make([]Summary, 0, len(order))
+	for _, key := range order {
+		summaries = append(summaries, *index[key])
+	}
+	sort.SliceStable(summaries, func(i, j int) bool {
+		if by == "day" {
+			return summaries[i].Key < summaries[j].Key
+		}
+		return summaries[i].Cost > summaries[j].Cost
+	})
+	return summaries, nil
+}
+
+// ParseSince 解析时间范围，支持 30d、2w、12h 等相对时长以及 2006-01-02 格式的日期
+func ParseSince(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+
+	units := map[byte]time.Duration{'d': 24 * time.Hour, 'w': 7 * 24 * time.Hour}
+	if unit, ok := units[value[len(value)-1]]; ok {
+		n, err := strconv.Atoi(value[:len(value)-1])
+		if err == nil && n >= 0 {
+			return now.Add(-time.Duration(n) * unit), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("无效的时间范围: %s，示例: 30d、2w、12h、2024-01-01", value)
+}
@@ -0,0 +1,87 @@
+package usage
+
+import (
+	"sort"
+	"strings"
+)
+
+// Usage 单次或累计的 token 用量
+type Usage struct {
+	PromptTokens     int64 `json:"prompt_tokens"`
+	CompletionTokens int64 `json:"completion_tokens"`
+	CachedTokens     int64 `json:"cached_tokens"` // 命中服务端提示词缓存的输入 token，包含在 PromptTokens 中
+}
+
+// Add 累加用量
+func (u *Usage) Add(other Usage) {
+	u.PromptTokens += other.PromptTokens
+	u.CompletionTokens += other.CompletionTokens
+	u.CachedTokens += other.CachedTokens
+}
+
+// Total 输入与输出 token 总数
+func (u Usage) Total() int64 {
+	return u.PromptTokens + u.CompletionTokens
+}
+
+// Price 模型单价，单位为美元 / 百万 token
+type Price struct {
+	Input       float64 `mapstructure:"input" json:"input"`
+	CachedInput float64 `mapstructure:"cached_input" json:"cached_input"`
+	Output      float64 `mapstructure:"output" json:"output"`
+}
+
+// DefaultPrices 内置的常用模型价格，可通过配置文件中的 prices 覆盖或补充
+var DefaultPrices = map[string]Price{
+	"gpt-3.5-turbo": {Input: 0.5, Output: 1.5},
+	"gpt-4o":        {Input: 2.5, CachedInput: 1.25, Output: 10},
+	"gpt-4o-mini":   {Input: 0.15, CachedInput: 0.075, Output: 0.6},
+	"gpt-4.1":       {Input: 2, CachedInput: 0.5, Output: 8},
+	"gpt-4.1-mini":  {Input: 0.4, CachedInput: 0.1, Output: 1.6},
+	"gpt-4.1-nano":  {Input: 0.1, CachedInput: 0.025, Output: 0.4},
+	"gpt-5":         {Input: 1.25, CachedInput: 0.125, Output: 10},
+	"gpt-5-mini":    {Input: 0.25, CachedInput: 0.025, Output: 2},
+	"gpt-5-nano":    {Input: 0.05, CachedInput: 0.005, Output: 0.4},
+	"o1":            {Input: 15, CachedInput: 7.5, Output: 60},
+	"o3":            {Input: 2, CachedInput: 0.5, Output: 8},
+	"o3-mini":       {Input: 1.1, CachedInput: 0.55, Output: 4.4},
+	"o4-mini":       {Input: 1.1, CachedInput: 0.275, Output: 4.4},
+}
+
+// LookupPrice 查找模型价格，先精确匹配，再按最长前缀匹配（如 gpt-4o-2024-08-06 匹配 gpt-4o）
+func LookupPrice(model string, overrides map[string]Price) (Price, bool) {
+	prices := make(map[string]Price, len(DefaultPrices)+len(overrides))
+	for k, v := range DefaultPrices {
+		prices[k] = v
+	}
+	for k, v := range overrides {
+		prices[strings.ToLower(k)] = v
+	}
+
+	model = strings.ToLower(model)
+	if p, ok := prices[model]; ok {
+		return p, true
+	}
+
+	names := make([]string, 0, len(prices))
+	for name := range prices {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return len(names[i]) > len(names[j]) })
+	for _, name := range names {
+		if strings.HasPrefix(model, name+"-") {
+			return prices[name], true
+		}
+	}
+	return Price{}, false
+}
+
+// Cost 按价格计算费用（美元），未配置缓存单价时缓存 token 按普通输入计费
+func Cost(u Usage, p Price) float64 {
+	cachedPrice := p.CachedInput
+	if cachedPrice == 0 {
+		cachedPrice = p.Input
+	}
+	uncached := u.PromptTokens - u.CachedTokens
+	return (float64(uncached)*p.Input + float64(u.CachedTokens)*cachedPrice + float64(u.CompletionTokens)*p.Output) / 1e6
+}
@@ -0,0 +1,120 @@
+package usage
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestLookupPrice(t *testing.T) {
+	overrides := map[string]Price{
+		"GPT-4o":        {Input: 3, Output: 12},
+		"my-model":      {Input: 1, Output: 2},
+		"gpt-4o-mini-x": {Input: 9, Output: 9},
+	}
+	tests := []struct {
+		name   string
+		model  string
+		want   Price
+		wantOK bool
+	}{
+		{name: "精确匹配", model: "gpt-4.1", want: DefaultPrices["gpt-4.1"], wantOK: true},
+		{name: "不区分大小写", model: "GPT-4.1-Mini", want: DefaultPrices["gpt-4.1-mini"], wantOK: true},
+		{name: "带日期的版本按前缀匹配", model: "gpt-4.1-2025-04-14", want: DefaultPrices["gpt-4.1"], wantOK: true},
+		{name: "最长前缀优先", model: "gpt-4o-mini-2024-07-18", want: DefaultPrices["gpt-4o-mini"], wantOK: true},
+		{name: "配置覆盖内置价格", model: "gpt-4o-2024-08-06", want: Price{Input: 3, Output: 12}, wantOK: true},
+		{name: "配置补充的模型", model: "my-model", want: Price{Input: 1, Output: 2}, wantOK: true},
+		{name: "前缀须以 - 分隔", model: "o30", wantOK: false},
+		{name: "未知模型", model: "llama-3", wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := LookupPrice(tt.model, overrides)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("LookupPrice(%s) = %+v, %v; want %+v, %v", tt.model, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestCost(t *testing.T) {
+	u := Usage{PromptTokens: 1_000_000, CachedTokens: 400_000, CompletionTokens: 500_000}
+	tests := []struct {
+		name  string
+		price Price
+		want  float64
+	}{
+		{name: "缓存单价", price: Price{Input: 2, CachedInput: 0.5, Output: 8}, want: 0.6*2 + 0.4*0.5 + 0.5*8},
+		{name: "未配置缓存单价时按普通输入计费", price: Price{Input: 2, Output: 8}, want: 2 + 0.5*8},
+		{name: "免费模型", price: Price{}, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Cost(u, tt.price); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Cost = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2026, 3, 15, 10, 0, 0, 0, time.Local)
+	tests := []struct {
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{value: "", want: time.Time{}},
+		{value: "30d", want: now.AddDate(0, 0, -30)},
+		{value: "0d", want: now},
+		{value: "2w", want: now.AddDate(0, 0, -14)},
+		{value: "12h", want: now.Add(-12 * time.Hour)},
+		{value: "90m", want: now.Add(-90 * time.Minute)},
+		{value: "2026-01-02", want: time.Date(2026, 1, 2, 0, 0, 0, 0, time.Local)},
+		{value: "d", wantErr: true},
+		{value: "-3d", wantErr: true},
+		{value: "1.5w", wantErr: true},
+		{value: "2026-13-01", wantErr: true},
+		{value: "yesterday", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseSince(tt.value, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !got.Equal(tt.want) {
+				t.Errorf("ParseSince(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	day := time.Date(2026, 3, 15, 12, 0, 0, 0, time.Local)
+	records := []Record{
+		{Time: day, Command: "review", Model: "gpt-4o", Requests: 2, Usage: Usage{PromptTokens: 100}, Cost: 0.5},
+		{Time: day.AddDate(0, 0, -1), Command: "chat", Model: "gpt-4o-mini", Requests: 1, Usage: Usage{PromptTokens: 10}, Cost: 0.1},
+		{Time: day, Command: "review", Model: "gpt-4o-mini", Requests: 3, Usage: Usage{PromptTokens: 30}, Cost: 0.2},
+	}
+
+	byModel, err := Summarize(records, "model")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(byModel) != 2 || byModel[0].Key != "gpt-4o" || byModel[1].Requests != 4 || byModel[1].PromptTokens != 40 {
+		t.Errorf("按模型汇总 = %+v", byModel)
+	}
+
+	byDay, err := Summarize(records, "day")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(byDay) != 2 || byDay[0].Key != "2026-03-14" || byDay[1].Key != "2026-03-15" {
+		t.Errorf("按日汇总应按日期排列: %+v", byDay)
+	}
+
+	if _, err := Summarize(records, "user"); err == nil {
+		t.Error("不支持的维度应返回错误")
+	}
+}
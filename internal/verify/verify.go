@@ -0,0 +1,255 @@
+package verify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"ai_code_reviewer/internal/cache"
+	"ai_code_reviewer/internal/config"
+	"ai_code_reviewer/internal/finding"
+	"ai_code_reviewer/internal/gitutil"
+	"ai_code_reviewer/internal/provider"
+	"ai_code_reviewer/internal/redact"
+	"ai_code_reviewer/internal/usage"
+)
+
+// 核实结论
+const (
+	Confirmed = "confirmed"
+	Refuted   = "refuted"
+)
+
+// contextLines 发现所在行前后附加的代码行数
+const contextLines = 20
+
+// systemPrompt 核实者的系统提示词
+const systemPrompt = `你是代码审查的核实者。另一位审查者针对下面的代码变更给出了若干发现，其中可能包含误报，
+例如臆测的空指针、已被调用方处理的错误、与代码不符的描述。请对照 diff 和代码逐条判断问题是否真实存在，
+不要提出新的问题。
+
+只输出一个 JSON 对象，不要使用代码块，不要添加其他文字，格式如下：
+{"verdicts": [{"id": 1, "verdict": "confirmed", "confidence": 0.9, "reason": "判断依据"}]}
+- id 为发现的编号；
+- verdict 为 confirmed（问题真实存在）或 refuted（误报）；
+- confidence 为问题真实存在的把握，0 到 1 之间，refuted 的发现应给出较低的值。`
+
+// Verifier 让模型逐条核实结构化发现，并为每条发现附加核实结论
+type Verifier struct {
+	cfg      *config.Config
+	provider provider.Provider
+	cache    *cache.Cache // 为 nil 时不使用缓存
+	ref      string       // 读取源码的引用，为空时读取工作区
+	used     usage.Usage
+	requests int
+}
+
+// New 创建核实器，c 为 nil 表示禁用缓存
+func New(cfg *config.Config, p provider.Provider, c *cache.Cache, ref string) *Verifier {
+	return &Verifier{cfg: cfg, provider: p, cache: c, ref: ref}
+}
+
+// Failure 核实失败的文件，其中的发现保持未核实状态
+type Failure struct {
+	Path     string
+	Findings int // 未能核实的发现数
+	Err      error
+}
+
+// Verify 按文件分组核实发现，结论写入各发现的 Verification。
+// 模型没有给出结论或核实请求失败的发现保持未核实状态，单个文件失败不影响其他文件，
+// 失败的文件在返回值中列出；progress 在每处理完一个文件后回调
+func (v *Verifier) Verify(ctx context.Context, findings []*finding.Finding, files []gitutil.FileDiff, progress func(done, total int)) []Failure {
+	byPath := make(map[string][]*finding.Finding)
+	var paths []string
+	for _, f := range findings {
+		if _, ok := byPath[f.Path]; !ok {
+			paths = append(paths, f.Path)
+		}
+		byPath[f.Path] = append(byPath[f.Path], f)
+	}
+	diffs := make(map[string]gitutil.FileDiff, len(files))
+	for _, f := range files {
+		diffs[f.Path] = f
+	}
+
+	var failures []Failure
+	for n, path := range paths {
+		list := byPath[path]
+		if err := v.verifyFile(ctx, path, diffs[path], list); err != nil {
+			failures = append(failures, Failure{Path: path, Findings: len(list), Err: err})
+		}
+		if progress != nil {
+			progress(n+1, len(paths))
+		}
+	}
+	return failures
+}
+
+// verifyFile 核实一个文件中的发现
+func (v *Verifier) verifyFile(ctx context.Context, path string, diff gitutil.FileDiff, list []*finding.Finding) error {
+	content, err := v.complete(ctx, v.userMessage(path, diff, list))
+	if err != nil {
+		return fmt.Errorf("请求失败: %w", err)
+	}
+	verdicts, err := parse(content)
+	if err != nil {
+		return fmt.Errorf("解析核实结果失败: %w", err)
+	}
+	for i, f := range list {
+		if verdict, ok := verdicts[i+1]; ok {
+			f.Verification = &verdict
+		}
+	}
+	return nil
+}
+
+// Usage 返回核实阶段累计的 token 用量和请求数，命中缓存的文件不计入
+func (v *Verifier) Usage() (usage.Usage, int) {
+	return v.used, v.requests
+}
+
+// complete 发送核实请求，结果按请求内容缓存
+func (v *Verifier) complete(ctx context.Context, user string) (string, error) {
+	parts := []string{"verify", user, v.cfg.Model, systemPrompt, v.provider.Name(), v.cfg.Url, v.cfg.Deployment}
+	if !v.cfg.Params.IsZero() {
+		parts = append(parts, v.cfg.Params.String())
+	}
+	key := cache.Key(parts...)
+	if v.cache != nil {
+		if content, ok := v.cache.Get(key); ok {
+			return content, nil
+		}
+	}
+
+	resp, err := v.provider.Complete(ctx, provider.Request{
+		Model: v.cfg.Model,
+		Messages: []provider.Message{
+			{Role: provider.RoleSystem, Content: systemPrompt},
+			{Role: provider.RoleUser, Content: user},
+		},
+	})
+	v.requests++
+	if resp != nil {
+		v.used.Add(resp.Usage)
+	}
+	if err != nil {
+		return "", err
+	}
+	if v.cache != nil {
+		if _, err := parse(resp.Content); err == nil {
+			_ = v.cache.Put(key, resp.Content)
+		}
+	}
+	return resp.Content, nil
+}
+
+// userMessage 包含文件的 diff、发现所在位置附近的代码以及待核实的发现列表
+func (v *Verifier) userMessage(path string, diff gitutil.FileDiff, list []*finding.Finding) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "文件 `%s`\n\n", path)
+	if diff.Content != "" {
+		fmt.Fprintf(&b, "diff（每行开头的数字为新文件中的行号）：\n\n```diff\n%s\n```\n\n", strings.TrimRight(diff.Numbered(), "\n"))
+	}
+	if excerpt := v.excerpt(path, list); excerpt != "" {
+		fmt.Fprintf(&b, "发现所在位置附近的代码：\n\n```\n%s```\n\n", excerpt)
+	}
+	b.WriteString("待核实的发现：\n\n")
+	for i, f := range list {
+		fmt.Fprintf(&b, "%d. %s [%s] `%s` %s\n", i+1, f.Location(), f.Severity, f.Category, f.Title)
+		if f.Detail != "" {
+			fmt.Fprintf(&b, "   %s\n", strings.ReplaceAll(strings.TrimSpace(f.Detail), "\n", "\n   "))
+		}
+	}
+	return b.String()
+}
+
+// excerpt 截取各发现前后 contextLines 行的代码并标注行号，重叠的区间会合并。
+// 代码中检测到敏感信息且 redact_mode 为 refuse 时不附加代码，只依据 diff 核实
+func (v *Verifier) excerpt(path string, list []*finding.Finding) string {
+	data, err := gitutil.ShowFile(v.ref, path)
+	if err != nil {
+		// 文件已被删除，只能依据 diff 核实
+		return ""
+	}
+	lines := strings.Split(string(data), "\n")
+
+	type span struct{ start, end int }
+	var spans []span
+	for _, f := range list {
+		end := f.EndLine
+		if end < f.Line {
+			end = f.Line
+		}
+		spans = append(spans, span{max(1, f.Line-contextLines), min(len(lines), end+contextLines)})
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+
+	var b strings.Builder
+	last := 0
+	for _, s := range spans {
+		if s.end <= last {
+			continue
+		}
+		if s.start <= last {
+			s.start = last + 1
+		} else if last > 0 {
+			b.WriteString("  ...\n")
+		}
+		for n := s.start; n <= s.end; n++ {
+			fmt.Fprintf(&b, "%5d %s\n", n, lines[n-1])
+		}
+		last = s.end
+	}
+
+	text, _, err := redact.ApplyText(v.cfg.RedactMode, path, b.String())
+	if err != nil {
+		return ""
+	}
+	return text
+}
+
+// Keep 判断核实后是否保留发现：判定为误报的发现不论置信度都移除，其余按置信度阈值过滤，未核实的发现保留
+func Keep(f finding.Finding, minConfidence float64) bool {
+	if f.Verification == nil {
+		return true
+	}
+	return f.Verification.Verdict != Refuted && f.Verification.Confidence >= minConfidence
+}
+
+// parse 解析模型输出的核实结论，返回 编号 -> 结论
+func parse(text string) (map[int]finding.Verification, error) {
+	start := strings.Index(text, "{")
+	end := strings.LastIndex(text, "}")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("输出中没有 JSON 对象")
+	}
+
+	var out struct {
+		Verdicts []struct {
+			ID         int     `json:"id"`
+			Verdict    string  `json:"verdict"`
+			Confidence float64 `json:"confidence"`
+			Reason     string  `json:"reason"`
+		} `json:"verdicts"`
+	}
+	if err := json.Unmarshal([]byte(text[start:end+1]), &out); err != nil {
+		return nil, fmt.Errorf("解析 JSON 失败: %w", err)
+	}
+
+	verdicts := make(map[int]finding.Verification, len(out.Verdicts))
+	for _, v := range out.Verdicts {
+		verdict := strings.ToLower(strings.TrimSpace(v.Verdict))
+		if verdict != Refuted {
+			verdict = Confirmed
+		}
+		verdicts[v.ID] = finding.Verification{
+			Verdict:    verdict,
+			Confidence: min(max(v.Confidence, 0), 1),
+			Reason:     strings.TrimSpace(v.Reason),
+		}
+	}
+	return verdicts, nil
+}
@@ -0,0 +1,100 @@
+package verify
+
+import (
+	"context"
+	"testing"
+
+	"ai_code_reviewer/internal/config"
+	"ai_code_reviewer/internal/finding"
+	"ai_code_reviewer/internal/provider"
+)
+
+func TestVerifyContinuesAfterFailure(t *testing.T) {
+	// 第一个文件的回复无法解析，第二个文件正常
+	fake := provider.NewFake(
+		"模型没有按格式输出",
+		`{"verdicts": [{"id": 1, "verdict": "refuted", "confidence": 0.2, "reason": "已在调用方处理"}]}`,
+	)
+	cfg := &config.Config{Model: "fake-model", RedactMode: "off"}
+	findings := []*finding.Finding{
+		{Path: "testdata/missing-a.go", Line: 1, Title: "a"},
+		{Path: "testdata/missing-b.go", Line: 2, Title: "b"},
+	}
+
+	var calls int
+	failures := New(cfg, fake, nil, "").Verify(context.Background(), findings, nil, func(done, total int) {
+		calls++
+	})
+
+	if len(failures) != 1 || failures[0].Path != "testdata/missing-a.go" || failures[0].Findings != 1 {
+		t.Fatalf("failures = %+v，应只有 missing-a.go 失败", failures)
+	}
+	if findings[0].Verification != nil {
+		t.Error("核实失败的发现应保持未核实")
+	}
+	if v := findings[1].Verification; v == nil || v.Verdict != Refuted || v.Confidence != 0.2 {
+		t.Errorf("第二个文件的核实结论 = %+v", v)
+	}
+	if calls != 2 {
+		t.Errorf("progress 回调 %d 次，应为 2", calls)
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		want    map[int]finding.Verification
+		wantErr bool
+	}{
+		{
+			name: "代码块和多余文字",
+			text: "结论如下：\n```json\n{\"verdicts\": [{\"id\": 2, \"verdict\": \"CONFIRMED\", \"confidence\": 1.5}]}\n```",
+			want: map[int]finding.Verification{2: {Verdict: Confirmed, Confidence: 1}},
+		},
+		{
+			name: "未知结论视为确认，置信度截断到 0",
+			text: `{"verdicts": [{"id": 1, "verdict": "maybe", "confidence": -1, "reason": " r "}]}`,
+			want: map[int]finding.Verification{1: {Verdict: Confirmed, Confidence: 0, Reason: "r"}},
+		},
+		{name: "没有 JSON", text: "无法判断", wantErr: true},
+		{name: "JSON 格式错误", text: `{"verdicts": [}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parse(tt.text)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+			for id, want := range tt.want {
+				if got[id] != want {
+					t.Errorf("verdict %d = %+v, want %+v", id, got[id], want)
+				}
+			}
+		})
+	}
+}
+
+func TestKeep(t *testing.T) {
+	tests := []struct {
+		name string
+		v    *finding.Verification
+		want bool
+	}{
+		{name: "未核实", v: nil, want: true},
+		{name: "确认且达到阈值", v: &finding.Verification{Verdict: Confirmed, Confidence: 0.7}, want: true},
+		{name: "确认但低于阈值", v: &finding.Verification{Verdict: Confirmed, Confidence: 0.6}, want: false},
+		{name: "误报但置信度很高", v: &finding.Verification{Verdict: Refuted, Confidence: 0.95}, want: false},
+		{name: "误报且置信度低", v: &finding.Verification{Verdict: Refuted, Confidence: 0.1}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Keep(finding.Finding{Title: "t", Verification: tt.v}, 0.7); got != tt.want {
+				t.Errorf("Keep = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}